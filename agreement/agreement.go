@@ -0,0 +1,136 @@
+package agreement
+
+import (
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+)
+
+func runStep2(currentPeriod *PeriodState, lastPeriod *PeriodState, requiredVotes int64) string {
+	var voteValue string
+
+	// a value and _|_ can both have 2t+1 next-votes, the value wins as it does
+	// for the starting value
+	if currentPeriod.period > 1 {
+		voteValue = checkPeriodCompleted(lastPeriod, requiredVotes)
+	}
+
+	logging.Agreement.Debugf("step 2 voteValue: %v", voteValue)
+
+	if currentPeriod.period == 1 || voteValue == "_|_" {
+		logging.Agreement.Debugf("Period is 1 or vote value is _|_, ProposedValues: %#v", currentPeriod.proposedValues)
+		leadersValue := selectLeader(currentPeriod.proposedValues)
+		logging.Agreement.Debugf("leadersValue: %v", leadersValue)
+		if leadersValue == "" {
+			// nobody we heard of proposed, settle for the empty block
+			return Empty
+		}
+		return leadersValue
+	} else if voteValue != "" {
+		return voteValue
+	}
+	return ""
+}
+
+func runStep3(currentPeriod *PeriodState, requiredVotes int64) string {
+	var voteValue string
+	votes := int64(0)
+
+	for value, numVotes := range currentPeriod.softVotes {
+		// find max value
+		if numVotes > votes {
+			voteValue = value
+			votes = numVotes
+		}
+	}
+
+	logging.Agreement.Debugf("step 3 voteValue: %v, votes: %v", voteValue, votes)
+
+	if voteValue != "_|_" && votes >= requiredVotes {
+		return voteValue
+	}
+	return ""
+}
+
+func runStep4(currentPeriod *PeriodState, lastPeriod *PeriodState, requiredVotes int64) string {
+	var voteValue string
+
+	if currentPeriod.period > 1 {
+		voteValue = checkPeriodCompleted(lastPeriod, requiredVotes)
+	}
+
+	if currentPeriod.myCertVote != "" {
+		voteValue = currentPeriod.myCertVote
+	} else if currentPeriod.period >= 2 && voteValue == "_|_" {
+		voteValue = "_|_"
+	} else {
+		// next vote starting value stpi??
+		voteValue = currentPeriod.startingValue
+	}
+	return voteValue
+}
+
+func runStep5(currentPeriod *PeriodState, lastPeriod *PeriodState, requiredVotes int64) string {
+	// if i sees 2t + 1 soft-votes for some value v != ⊥ for period p, then i next-votes v.
+	var voteValue string
+	votes := int64(0)
+
+	for value, numVotes := range currentPeriod.softVotes {
+		// find max value
+		if numVotes > votes {
+			voteValue = value
+			votes = numVotes
+		}
+	}
+
+	if voteValue != "_|_" && votes >= requiredVotes {
+		return voteValue
+	}
+
+	// If p ≥ 2 AND i sees 2t+ 1 next-votes for ⊥ for period p−1 AND i has not certified in period p , then i next-votes _|_
+	if currentPeriod.period > 1 {
+		voteValue = checkPeriodCompleted(lastPeriod, requiredVotes)
+
+		if voteValue == "_|_" && currentPeriod.myCertVote == "" {
+			return "_|_"
+		}
+	}
+
+	return ""
+}
+
+// Returns value if consensus has chosen a block, otherwise empty string
+func checkHaltingCondition(currentPeriod *PeriodState, requiredVotes int64) string {
+	var voteValue string
+	votes := int64(0)
+
+	for value, numVotes := range currentPeriod.certVotes {
+		// find max value
+		if numVotes > votes {
+			voteValue = value
+			votes = numVotes
+		}
+	}
+
+	if votes >= requiredVotes {
+		return voteValue
+	}
+	return ""
+}
+
+// Returns the value 2t+1 users next-voted in the period, otherwise empty string.
+// Step 5 can next-vote both a value and _|_, the value wins if both got there.
+// Should two values get there, every node picks the smallest.
+func checkPeriodCompleted(currentPeriod *PeriodState, requiredVotes int64) string {
+	completed := ""
+	for value, numVotes := range currentPeriod.nextVotes {
+		if value != "_|_" && numVotes >= requiredVotes && (completed == "" || value < completed) {
+			completed = value
+		}
+	}
+	if completed != "" {
+		return completed
+	}
+	if currentPeriod.nextVotes["_|_"] >= requiredVotes {
+		return "_|_"
+	}
+	return ""
+}
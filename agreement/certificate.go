@@ -0,0 +1,36 @@
+package agreement
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+// VerifyCertificate checks that c holds cert votes for its value, round and
+// period from at least requiredVotes different users.
+func VerifyCertificate(c *pb.Certificate, users []string, requiredVotes int64) error {
+	known := make(map[string]bool, len(users))
+	for _, u := range users {
+		known[u] = true
+	}
+
+	voted := make(map[string]bool)
+	for _, sig := range c.GetVotes() {
+		v, err := parseVote(sig)
+		if err != nil {
+			return err
+		}
+		if v.voteType != "cert" || v.value != c.Value || v.round != c.Round || v.period != c.Period {
+			return errors.New("certificate holds a vote for something else")
+		}
+		if !known[sig.UserId] {
+			return fmt.Errorf("certificate holds a vote by unknown user %v", sig.UserId)
+		}
+		voted[sig.UserId] = true
+	}
+	if int64(len(voted)) < requiredVotes {
+		return fmt.Errorf("certificate holds %v votes, %v required", len(voted), requiredVotes)
+	}
+	return nil
+}
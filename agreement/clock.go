@@ -0,0 +1,30 @@
+package agreement
+
+import (
+	"time"
+)
+
+// Clock is the source of time for a Service.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f once d has elapsed, unless the returned Timer is stopped first.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+type Timer interface {
+	// Stop prevents the timer from firing, returning false if it already fired.
+	Stop() bool
+}
+
+// SystemClock is the Clock backed by the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
@@ -0,0 +1,179 @@
+package agreement
+
+import (
+	"time"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+// An Event is an input to the state machine.
+type Event interface {
+	isEvent()
+}
+
+// An Action is an output of the state machine that the caller has to carry out.
+type Action interface {
+	isAction()
+}
+
+type TimerKind int
+
+const (
+	// Fires periodically, starting a new round or period when the last one is done.
+	RoundTimer TimerKind = iota
+	// Fires when the current step is over.
+	StepTimer
+)
+
+func (t TimerKind) String() string {
+	switch t {
+	case RoundTimer:
+		return "round"
+	case StepTimer:
+		return "step"
+	}
+	return "unknown"
+}
+
+// Events
+
+// Timeout is delivered when a timer set through SetTimer fires.
+type Timeout struct {
+	Timer TimerKind
+	// identifies which SetTimer this is for, so a Service can drop stale timeouts
+	seq uint64
+}
+
+// ProposalReceived is delivered for a ProposeBlock from a peer.
+type ProposalReceived struct {
+	Proposal *pb.ProposeBlockArgs
+}
+
+// PriorityReceived is delivered for a ProposePriority from a peer.
+type PriorityReceived struct {
+	Priority *pb.ProposePriorityArgs
+}
+
+// VoteReceived is delivered for a Vote from a peer.
+type VoteReceived struct {
+	Vote *pb.VoteArgs
+}
+
+// BlockAssembled answers an AssembleBlock action.
+type BlockAssembled struct {
+	Round  int64
+	Period int64
+	Block  *pb.Block
+	// hash of Block, the value voted on
+	Value string
+	// our VRF proof over the Seed of the AssembleBlock
+	Proof []byte
+}
+
+// Synced is delivered after the caller replaced its chain, Round is the next
+// round to agree on and Seed the seed of the chain's last block.
+type Synced struct {
+	Round int64
+	Seed  string
+}
+
+// BlockFetched answers a FetchBlock action. The caller checked that Block
+// hashes to Value.
+type BlockFetched struct {
+	Round int64
+	Value string
+	Block *pb.Block
+}
+
+func (Timeout) isEvent()          {}
+func (ProposalReceived) isEvent() {}
+func (PriorityReceived) isEvent() {}
+func (VoteReceived) isEvent()     {}
+func (BlockAssembled) isEvent()   {}
+func (Synced) isEvent()           {}
+func (BlockFetched) isEvent()     {}
+
+// Actions
+
+// SetTimer (re)arms a timer, replacing any earlier setting of the same timer.
+type SetTimer struct {
+	Timer    TimerKind
+	Duration time.Duration
+}
+
+// BroadcastProposal sends our proposal to every peer.
+type BroadcastProposal struct {
+	Proposal *pb.ProposeBlockArgs
+}
+
+// BroadcastPriority sends our claim to lead the period to every peer, ahead
+// of the proposal itself.
+type BroadcastPriority struct {
+	Priority *pb.ProposePriorityArgs
+}
+
+// BroadcastVote sends our vote to every peer.
+type BroadcastVote struct {
+	Vote *pb.VoteArgs
+}
+
+// AssembleBlock asks the caller for the block to propose in Round and Period,
+// and for its VRF proof over Seed, which decides whether we may propose it.
+// The block's own seed follows from PrevSeed, see BlockSeed. The caller
+// answers with a BlockAssembled event.
+type AssembleBlock struct {
+	Round    int64
+	Period   int64
+	PrevSeed string
+	Seed     []byte
+}
+
+// Commit reports that agreement was reached on Value for Round. It waits for
+// Block, see FetchBlock. For Empty Block is nil, the caller commits the
+// round's empty block. Certificate holds the cert votes that decided it.
+type Commit struct {
+	Round       int64
+	Period      int64
+	Value       string
+	Block       *pb.Block
+	Certificate *pb.Certificate
+}
+
+// FetchBlock asks the caller for the block of Value, agreed on in Round and
+// Period without us ever seeing its proposal. Voters certified it, so they
+// have it. The caller answers with BlockFetched, the commit waits until then
+// and the action is repeated every round interval.
+type FetchBlock struct {
+	Round  int64
+	Period int64
+	Value  string
+	Voters []string
+}
+
+// CheckProposal hands back a proposal kept from before we got to its round.
+// Whether its block follows the previous one could only be checked once that
+// block was committed, so the caller does so now and delivers it again as
+// ProposalReceived if it holds up.
+type CheckProposal struct {
+	Proposal *pb.ProposeBlockArgs
+}
+
+// RequestSync reports that peers are ahead of us and we should fetch their chains.
+type RequestSync struct{}
+
+// Equivocation reports a voter that signed two different values for the same
+// step. The caller keeps the evidence and tells the operator.
+type Equivocation struct {
+	Evidence *pb.Evidence
+}
+
+func (SetTimer) isAction()          {}
+func (BroadcastProposal) isAction() {}
+func (BroadcastPriority) isAction() {}
+func (BroadcastVote) isAction()     {}
+func (AssembleBlock) isAction()     {}
+func (Commit) isAction()            {}
+func (FetchBlock) isAction()        {}
+func (CheckProposal) isAction()     {}
+func (RequestSync) isAction()       {}
+func (Equivocation) isAction()      {}
@@ -0,0 +1,92 @@
+package agreement
+
+import (
+	"errors"
+	"strconv"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+var ErrEquivocation = errors.New("voter signed a different value for the same step")
+
+// A vote is signed over [value, type, period, step, round]. The step tells the
+// two next-votes of a period apart, the round ties the vote to its round so a
+// pair of votes is evidence on its own.
+type voteMessage struct {
+	value    string
+	voteType string
+	period   int64
+	step     int64
+	round    int64
+}
+
+func (v voteMessage) strings() []string {
+	return []string{v.value, v.voteType, strconv.FormatInt(v.period, 10), strconv.FormatInt(v.step, 10), strconv.FormatInt(v.round, 10)}
+}
+
+func parseVote(sig *pb.SIGRet) (voteMessage, error) {
+	if !verifySIG(sig) || len(sig.Message) != 5 {
+		return voteMessage{}, ErrBadSignature
+	}
+	v := voteMessage{value: sig.Message[0], voteType: sig.Message[1]}
+
+	var err error
+	if v.period, err = strconv.ParseInt(sig.Message[2], 10, 64); err != nil {
+		return voteMessage{}, ErrBadSignature
+	}
+	if v.step, err = strconv.ParseInt(sig.Message[3], 10, 64); err != nil {
+		return voteMessage{}, ErrBadSignature
+	}
+	if v.round, err = strconv.ParseInt(sig.Message[4], 10, 64); err != nil {
+		return voteMessage{}, ErrBadSignature
+	}
+	return v, nil
+}
+
+// Votes are unique per voter, round, period and step, except that a next-vote
+// for ⊥ and one for a value can share a step: step 5 may cast both.
+type voteKey struct {
+	voter  string
+	period int64
+	step   int64
+	bottom bool
+}
+
+func (v voteMessage) key(voter string) voteKey {
+	return voteKey{voter: voter, period: v.period, step: v.step, bottom: v.voteType == "next" && v.value == Bottom}
+}
+
+// VerifyEvidence checks that e really shows its user signing two different
+// values for the same round, period and step. key is the user's
+// participation key for e's round, both votes have to be signed with it.
+func VerifyEvidence(e *pb.Evidence, key ed25519.PublicKey) error {
+	first, err := parseVote(e.GetFirst())
+	if err != nil {
+		return err
+	}
+	second, err := parseVote(e.GetSecond())
+	if err != nil {
+		return err
+	}
+
+	if e.First.UserId != e.UserId || e.Second.UserId != e.UserId {
+		return errors.New("evidence votes are not signed by the accused user")
+	}
+	if first.round != e.Round || second.round != e.Round ||
+		first.period != e.Period || second.period != e.Period ||
+		first.step != e.Step || second.step != e.Step {
+		return errors.New("evidence votes are not for the same round, period and step")
+	}
+	if first.value == second.value {
+		return errors.New("evidence votes are for the same value")
+	}
+	if first.key(e.UserId) != second.key(e.UserId) {
+		return errors.New("evidence votes are a next-vote for a value and one for bottom, which is allowed")
+	}
+	if VerifyVoteSignature(e.First, e.Round, key) != nil || VerifyVoteSignature(e.Second, e.Round, key) != nil {
+		return ErrUnsignedVote
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package agreement
+
+// A message that arrived before we got to its round or period.
+type futureMessage struct {
+	round  int64
+	period int64
+	sender string
+	event  Event
+	// a proposal for a later round, the caller hasn't checked its block
+	unchecked bool
+}
+
+// buffer keeps msg until we get to its round and period. Peers too far ahead,
+// or so many messages that we are clearly behind, make us sync instead.
+func (m *Machine) buffer(msg futureMessage, peer string) ([]Action, error) {
+	log := m.log().WithField("peer", peer)
+	if msg.round-m.round > m.cfg.FutureRounds || len(m.future) >= m.cfg.FutureMessages {
+		log.Infof("Round is behind peers, request blockchains")
+		return m.requestSync(), ErrFutureRound
+	}
+	log.Debugf("Keeping message for round %v period %v until we get there", msg.round, msg.period)
+	m.future = append(m.future, msg)
+	return nil, nil
+}
+
+func (m *Machine) requestSync() []Action {
+	if m.syncRequested {
+		return nil
+	}
+	m.syncRequested = true
+	return []Action{RequestSync{}}
+}
+
+// replayFuture handles the buffered messages that are due now and drops the
+// ones for rounds that are over. Handling them can move us on again, so it
+// goes until nothing more is due.
+func (m *Machine) replayFuture() []Action {
+	var actions []Action
+	for len(m.future) > 0 {
+		var due, kept []futureMessage
+		for _, msg := range m.future {
+			switch {
+			case msg.round < m.round:
+			case msg.round == m.round && msg.period <= m.period:
+				due = append(due, msg)
+			default:
+				kept = append(kept, msg)
+			}
+		}
+		m.future = kept
+		if len(due) == 0 {
+			break
+		}
+		for _, msg := range due {
+			if msg.unchecked {
+				actions = append(actions, CheckProposal{Proposal: msg.event.(ProposalReceived).Proposal})
+				continue
+			}
+			a, _ := m.handle(msg.event)
+			actions = append(actions, a...)
+		}
+	}
+	return actions
+}
+
+// votersAhead counts the voters we have messages from for a later round.
+func (m *Machine) votersAhead() int64 {
+	senders := make(map[string]bool)
+	for _, msg := range m.future {
+		if msg.round > m.round {
+			senders[msg.sender] = true
+		}
+	}
+	return int64(len(senders))
+}
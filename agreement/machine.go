@@ -0,0 +1,632 @@
+package agreement
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+	"github.com/nyu-distributed-systems-fa18/algorand/vrf"
+)
+
+var (
+	ErrFutureRound    = errors.New("message is for a future round")
+	ErrPastRound      = errors.New("message is for a round that is already over")
+	ErrPastPeriod     = errors.New("message is for a period that is already over")
+	ErrNotOnCommittee = errors.New("proposer is not on the committee")
+	ErrBadSignature   = errors.New("signature does not match the message")
+	ErrAlreadyVoted   = errors.New("already voted in this step")
+	ErrUnknownVote    = errors.New("unknown vote type")
+	ErrBadSeed        = errors.New("block seed isn't its proposer's VRF output over the previous seed")
+)
+
+type Config struct {
+	UserId string
+	// signs our votes, nil leaves them unsigned
+	Signer Signer
+
+	// stake weighted list of userIds that sortition picks the committee from
+	Candidates []string
+	// the key every user proves its sortition with, see sortitionSeed
+	VRFKeys map[string]ed25519.PublicKey
+	// seed of the genesis block, later rounds take theirs from the chain
+	Seed string
+	// 2t+1 required votes for Byzantine fault tolerance
+	RequiredVotes int64
+
+	Params Params
+
+	// Messages for up to FutureRounds rounds ahead, and proposals for later
+	// periods of this round, are kept and handled once we get there, at most
+	// FutureMessages of them. Peers further ahead make us sync. Votes for
+	// later periods count right away, up to FutureMessages of them too.
+	FutureRounds   int64
+	FutureMessages int
+}
+
+// Buffering limits suited to a small network, see Config.
+const (
+	DefaultFutureRounds   = 2
+	DefaultFutureMessages = 1024
+)
+
+// Machine runs BA* for a single node. It does no I/O of its own: it consumes
+// Events and returns the Actions the caller has to carry out, so the same
+// sequence of events always produces the same decisions. See Service for
+// running a Machine against a clock and a network.
+type Machine struct {
+	cfg Config
+
+	round             int64
+	period            int64
+	step              int64
+	readyForNextRound bool
+	// seed of the block before this round, sortition proves over it
+	seed string
+
+	// every period of this round we have seen votes for, and the current and previous one
+	periods         map[int64]*PeriodState
+	periodState     *PeriodState
+	lastPeriodState *PeriodState
+
+	// the value agreed on this round while we wait for its block, and its period
+	decided       string
+	decidedPeriod int64
+
+	// the value we assembled for the current period, our starting value if it doesn't settle
+	myValue string
+	// what we proposed in the current period, nil if sortition didn't select us
+	// or a better priority was known before we could send it
+	myProposal *pb.ProposeBlockArgs
+
+	// the first vote of every voter and step this round, and the keys we already reported
+	votes   map[voteKey]*pb.SIGRet
+	accused map[voteKey]bool
+
+	// messages for later rounds and proposals for later periods, in the order they arrived
+	future []futureMessage
+	// votes counted for periods we haven't got to yet this round
+	laterVotes int
+	// whether most voters were already ahead of us at the last round timeout
+	behind bool
+	// we ask to sync at most once per round interval
+	syncRequested bool
+}
+
+func NewMachine(cfg Config) *Machine {
+	m := &Machine{cfg: cfg, seed: cfg.Seed}
+	m.startRound(1)
+	return m
+}
+
+func (m *Machine) Config() Config {
+	return m.cfg
+}
+
+func (m *Machine) Round() int64 {
+	return m.round
+}
+
+func (m *Machine) Period() int64 {
+	return m.period
+}
+
+func (m *Machine) Step() int64 {
+	return m.step
+}
+
+// Snapshot copies the machine's position and the tallies of this and the last period.
+func (m *Machine) Snapshot() Snapshot {
+	return Snapshot{
+		Round:   m.round,
+		Period:  m.period,
+		Step:    m.step,
+		Current: m.periodState.snapshot(),
+		Last:    m.lastPeriodState.snapshot(),
+	}
+}
+
+// log returns the agreement logger with our position in the protocol attached.
+func (m *Machine) log() *logging.Entry {
+	return logging.Agreement.WithFields(logging.Fields{"node": m.cfg.UserId, "round": m.round, "period": m.period, "step": m.step})
+}
+
+// CurrentProposal returns what we proposed in the current round and period,
+// nil once a proposer with a better priority is known: ours can't lead then,
+// so there is no point in sending it again.
+func (m *Machine) CurrentProposal() *pb.ProposeBlockArgs {
+	if m.myProposal == nil || m.periodState.bestPriority < m.myProposal.Priority.Priority {
+		return nil
+	}
+	return m.myProposal
+}
+
+// Block returns the block of value if it was proposed this round, in any period.
+func (m *Machine) Block(value string) *pb.Block {
+	for _, ps := range m.periods {
+		if b := ps.valueToBlock[value]; b != nil {
+			return b
+		}
+	}
+	return nil
+}
+
+// Start returns the actions that get the first round going.
+func (m *Machine) Start() []Action {
+	return []Action{
+		SetTimer{Timer: RoundTimer, Duration: m.cfg.Params.RoundInterval},
+		SetTimer{Timer: StepTimer, Duration: m.cfg.Params.StepTimeout()},
+	}
+}
+
+// Handle applies an event. A non nil error means the message in the event was
+// rejected, the returned actions still have to be carried out.
+func (m *Machine) Handle(ev Event) ([]Action, error) {
+	actions, err := m.handle(ev)
+	// moving on may have made buffered messages current
+	return append(actions, m.replayFuture()...), err
+}
+
+func (m *Machine) handle(ev Event) ([]Action, error) {
+	switch e := ev.(type) {
+	case Timeout:
+		if e.Timer == RoundTimer {
+			return m.roundTimeout(), nil
+		}
+		return m.stepTimeout(), nil
+	case BlockAssembled:
+		return m.blockAssembled(e), nil
+	case ProposalReceived:
+		return m.proposalReceived(e.Proposal)
+	case PriorityReceived:
+		return m.priorityReceived(e.Priority)
+	case VoteReceived:
+		return m.voteReceived(e.Vote)
+	case Synced:
+		m.seed = e.Seed
+		m.startRound(e.Round)
+		return nil, nil
+	case BlockFetched:
+		return m.blockFetched(e), nil
+	}
+	return nil, fmt.Errorf("unknown event %T", ev)
+}
+
+func (m *Machine) startRound(round int64) {
+	m.round = round
+	m.periods = make(map[int64]*PeriodState)
+	m.enterPeriod(1, Bottom)
+	m.myValue = ""
+	m.decided = ""
+
+	m.votes = make(map[voteKey]*pb.SIGRet)
+	m.laterVotes = 0
+	m.accused = make(map[voteKey]bool)
+	m.behind = false
+	m.syncRequested = false
+}
+
+// enterPeriod moves to step 1 of period, proposing again at the next round timer.
+func (m *Machine) enterPeriod(period int64, startingValue string) {
+	m.period = period
+	m.step = 1
+	m.periodState = m.stateOf(period)
+	m.periodState.startingValue = startingValue
+	m.lastPeriodState = m.stateOf(period - 1)
+	m.myProposal = nil
+
+	// allow step1 to happen again
+	m.readyForNextRound = true
+}
+
+func (m *Machine) stateOf(period int64) *PeriodState {
+	ps, ok := m.periods[period]
+	if !ok {
+		s := initPeriodState(period)
+		ps = &s
+		m.periods[period] = ps
+	}
+	return ps
+}
+
+// completePeriod moves on from ps once 2t+1 voters next-voted the same value
+// in it, that value is where the next period starts. ps can be ahead of us
+// when we fell behind.
+func (m *Machine) completePeriod(ps *PeriodState) {
+	value := checkPeriodCompleted(ps, m.cfg.RequiredVotes)
+	if value == "" {
+		return
+	}
+	m.log().WithField("value", value).Infof("Period %v complete", ps.period)
+	m.enterPeriod(ps.period+1, value)
+}
+
+func (m *Machine) halt(period int64, value string) []Action {
+	m.log().WithField("value", value).Infof("AGREEMENT!")
+	m.decided, m.decidedPeriod = value, period
+	if value == Empty {
+		return m.commit(nil)
+	}
+
+	block := m.Block(value)
+	if block == nil {
+		m.log().WithField("value", value).Warnf("Never saw the proposal, fetching the block from its voters")
+		return []Action{m.fetchBlock()}
+	}
+	return m.commit(block)
+}
+
+func (m *Machine) commit(block *pb.Block) []Action {
+	commit := Commit{Round: m.round, Period: m.decidedPeriod, Value: m.decided, Block: block, Certificate: m.certificate()}
+
+	// the caller checked the seed of every block before it got here
+	if m.decided == Empty {
+		m.seed = EmptySeed(m.seed, m.round)
+	} else {
+		m.seed = block.Seed
+	}
+
+	// Handle Halting Condition
+	m.startRound(m.round + 1)
+	return []Action{commit}
+}
+
+// certificate gathers the cert votes that decided the round, in voter order.
+func (m *Machine) certificate() *pb.Certificate {
+	c := &pb.Certificate{Round: m.round, Period: m.decidedPeriod, Value: m.decided}
+	for key, sig := range m.votes {
+		if key.period == m.decidedPeriod && key.step == 3 && sig.Message[0] == m.decided {
+			c.Votes = append(c.Votes, sig)
+		}
+	}
+	sort.Slice(c.Votes, func(i, j int) bool { return c.Votes[i].UserId < c.Votes[j].UserId })
+	return c
+}
+
+// fetchBlock asks for the decided block from everyone who cert-voted it.
+func (m *Machine) fetchBlock() Action {
+	voters := []string{}
+	for key, sig := range m.votes {
+		if key.period == m.decidedPeriod && key.step == 3 && key.voter != m.cfg.UserId && sig.Message[0] == m.decided {
+			voters = append(voters, key.voter)
+		}
+	}
+	sort.Strings(voters)
+	return FetchBlock{Round: m.round, Period: m.decidedPeriod, Value: m.decided, Voters: voters}
+}
+
+func (m *Machine) blockFetched(e BlockFetched) []Action {
+	if e.Round != m.round || m.decided == "" || e.Value != m.decided || e.Block == nil {
+		// we already have it, or it's not what we wait for
+		return nil
+	}
+	m.log().WithField("value", e.Value).Infof("Fetched the decided block")
+	return m.commit(e.Block)
+}
+
+func (m *Machine) vote(value string, voteType string) Action {
+	message := voteMessage{value: value, voteType: voteType, period: m.period, step: m.step, round: m.round}
+	sig := SIG(m.cfg.UserId, message.strings())
+	if m.cfg.Signer != nil {
+		signature, ephemeral, err := m.cfg.Signer.Sign(m.round, VoteSigningBytes(sig))
+		if err != nil {
+			m.log().Warnf("Can't sign our %v vote, peers will ignore it: %v", voteType, err)
+		} else {
+			sig.Signature = hex.EncodeToString(signature)
+			sig.Ephemeral = ephemeral
+		}
+	}
+
+	// count our own vote like anyone else's
+	m.votes[message.key(m.cfg.UserId)] = sig
+	m.periodState.count(m.cfg.UserId, voteType, value)
+	return BroadcastVote{Vote: &pb.VoteArgs{Message: sig, Round: m.round, Peer: m.cfg.UserId}}
+}
+
+// resendVotes broadcasts the votes we already cast in this period and the
+// last one again, in order. Those of the last period are what a peer still
+// in it needs to complete it.
+func (m *Machine) resendVotes() []Action {
+	var actions []Action
+	for period := m.period - 1; period <= m.period; period++ {
+		for step := int64(2); step <= 5; step++ {
+			for _, bottom := range []bool{false, true} {
+				if sig, ok := m.votes[voteKey{voter: m.cfg.UserId, period: period, step: step, bottom: bottom}]; ok {
+					actions = append(actions, BroadcastVote{Vote: &pb.VoteArgs{Message: sig, Round: m.round, Peer: m.cfg.UserId}})
+				}
+			}
+		}
+	}
+	return actions
+}
+
+func (m *Machine) roundTimeout() []Action {
+	var actions []Action
+	m.syncRequested = false
+
+	// most voters finishing our round without us seeing it means we missed the
+	// commit, give their votes one round interval to reach us before syncing
+	if m.votersAhead() >= m.cfg.RequiredVotes {
+		if m.behind {
+			actions = append(actions, m.requestSync()...)
+		}
+		m.behind = true
+	} else {
+		m.behind = false
+	}
+
+	// the round is decided, all that's left is getting its block
+	if m.decided != "" {
+		return append(actions, m.fetchBlock(), SetTimer{Timer: RoundTimer, Duration: m.cfg.Params.RoundInterval})
+	}
+
+	// propose block if last round complete or very first round
+	if m.readyForNextRound {
+		m.log().Infof("Starting round %v, period %v", m.round, m.period)
+		m.readyForNextRound = false
+
+		// we don't want step two to happen too quick before users can collect proposedBlocks
+		actions = append(actions, SetTimer{Timer: StepTimer, Duration: m.cfg.Params.StepTimeout()})
+
+		// the caller captures its pending transactions at the time agreement starts
+		actions = append(actions, AssembleBlock{Round: m.round, Period: m.period, PrevSeed: m.seed, Seed: sortitionSeed(m.seed, m.round, m.period)})
+	}
+
+	return append(actions, SetTimer{Timer: RoundTimer, Duration: m.cfg.Params.RoundInterval})
+}
+
+func (m *Machine) blockAssembled(e BlockAssembled) []Action {
+	if e.Round != m.round || e.Period != m.period {
+		// agreement moved on while the block was being assembled
+		return nil
+	}
+	m.myValue = e.Value
+
+	// every period draws its proposers anew, each user from its own VRF output
+	output, err := vrf.Verify(m.cfg.VRFKeys[m.cfg.UserId], sortitionSeed(m.seed, m.round, m.period), e.Proof)
+	if err != nil {
+		m.log().Warnf("Our sortition proof doesn't match our VRF key, can't propose")
+		return nil
+	}
+	votes, priority := m.sortition(m.cfg.UserId, output)
+	if votes == 0 {
+		return nil
+	}
+
+	// Value proposal step
+	sigParams := []string{strconv.FormatInt(m.round, 10), strconv.FormatInt(m.period, 10)}
+	sig := SIG(m.cfg.UserId, sigParams)
+	claim := &pb.Priority{
+		UserId:   m.cfg.UserId,
+		Round:    m.round,
+		Period:   m.period,
+		Value:    e.Value,
+		Proof:    hex.EncodeToString(e.Proof),
+		Priority: priority,
+	}
+
+	// add your own proposal to proposedBlock map
+	m.periodState.proposedValues[priority] = e.Value
+	m.periodState.valueToBlock[e.Value] = e.Block
+	m.periodState.claim(priority)
+
+	m.myProposal = &pb.ProposeBlockArgs{Block: e.Block, Credential: sig, Value: e.Value, Round: m.round, Peer: m.cfg.UserId, Priority: claim}
+	if m.CurrentProposal() == nil {
+		m.log().Debugf("Selected with priority %.8v, but %.8v leads already", priority, m.periodState.bestPriority)
+		return nil
+	}
+	return []Action{
+		BroadcastPriority{Priority: &pb.ProposePriorityArgs{Priority: claim, Peer: m.cfg.UserId}},
+		BroadcastProposal{Proposal: m.myProposal},
+	}
+}
+
+func (m *Machine) stepTimeout() []Action {
+	var actions []Action
+	requiredVotes := m.cfg.RequiredVotes
+
+	if m.decided != "" {
+		return []Action{SetTimer{Timer: StepTimer, Duration: m.cfg.Params.StepTimeout()}}
+	}
+
+	// if we are currently in agreement protocol
+	if !m.readyForNextRound && m.step < 5 {
+		m.step++
+	}
+
+	if m.step == 2 {
+		softVoteV := runStep2(m.periodState, m.lastPeriodState, requiredVotes)
+		m.log().Infof("STEP 2, soft vote is %v", softVoteV)
+
+		if softVoteV != "" {
+			actions = append(actions, m.vote(softVoteV, "soft"))
+		}
+	} else if m.step == 3 {
+		certVoteV := runStep3(m.periodState, requiredVotes)
+		m.log().Infof("STEP 3, cert vote is %v", certVoteV)
+
+		if certVoteV != "" {
+			m.periodState.myCertVote = certVoteV
+			actions = append(actions, m.vote(certVoteV, "cert"))
+
+			// check if our own vote helped us reach requiredVotes
+			haltValue := checkHaltingCondition(m.periodState, requiredVotes)
+			if haltValue != "" {
+				actions = append(actions, m.halt(m.period, haltValue)...)
+			}
+		}
+	} else if m.step == 4 {
+		nextVoteV := runStep4(m.periodState, m.lastPeriodState, requiredVotes)
+		m.log().Infof("STEP 4, next vote is %v", nextVoteV)
+
+		actions = append(actions, m.vote(nextVoteV, "next"))
+		m.completePeriod(m.periodState)
+	} else if m.step == 5 {
+		// step 5 is checked again and again until the period completes, we
+		// next-vote every value it turns up once
+		nextVoteV := runStep5(m.periodState, m.lastPeriodState, requiredVotes)
+		next := voteMessage{value: nextVoteV, voteType: "next", period: m.period, step: m.step}
+		if _, voted := m.votes[next.key(m.cfg.UserId)]; voted {
+			nextVoteV = ""
+		}
+
+		if nextVoteV != "" {
+			m.log().Infof("STEP 5, next vote is %v", nextVoteV)
+			actions = append(actions, m.vote(nextVoteV, "next"))
+			m.completePeriod(m.periodState)
+		} else {
+			// some of our votes may have been lost, send them again until the period completes
+			actions = append(actions, m.resendVotes()...)
+		}
+	}
+
+	// we want a shorter timout for continously checking step5 again and again
+	if m.step == 5 {
+		actions = append(actions, SetTimer{Timer: StepTimer, Duration: m.cfg.Params.RecoveryTimeout()})
+	} else {
+		actions = append(actions, SetTimer{Timer: StepTimer, Duration: m.cfg.Params.StepTimeout()})
+	}
+	return actions
+}
+
+func (m *Machine) proposalReceived(arg *pb.ProposeBlockArgs) ([]Action, error) {
+	if arg.Round < m.round {
+		// late or replayed, the round already has its block
+		return nil, ErrPastRound
+	}
+	claim := arg.Priority
+	if arg.Credential == nil || claim == nil || claim.UserId != arg.Credential.UserId || claim.Round != arg.Round || claim.Value != arg.Value {
+		return nil, ErrBadSignature
+	}
+	if arg.Round > m.round || claim.Period > m.period {
+		return m.buffer(futureMessage{round: arg.Round, period: claim.Period, sender: claim.UserId, event: ProposalReceived{Proposal: arg}, unchecked: arg.Round > m.round}, arg.Peer)
+	}
+	if claim.Period < m.period {
+		return nil, ErrPastPeriod
+	}
+
+	proposerId := claim.UserId
+	m.log().WithField("peer", arg.Peer).Debugf("ProposeBlock from %v", proposerId)
+
+	if err := m.verifyPriority(claim); err != nil {
+		// rejected proposed block
+		m.log().WithField("peer", arg.Peer).Warnf("DENIED that %v is on the committee for round %v period %v: %v", proposerId, m.round, m.period, err)
+		return nil, err
+	}
+	m.log().WithField("peer", arg.Peer).Debugf("VERIFIED that %v is on the committee for round %v period %v", proposerId, m.round, m.period)
+
+	// add verified block to list of blocks I've seen this period, even an
+	// outranked one: its leader may never send the block it announced
+	m.periodState.proposedValues[claim.Priority] = arg.Value
+	m.periodState.valueToBlock[arg.Value] = arg.Block
+	m.periodState.claim(claim.Priority)
+	if arg.Value == m.decided && arg.Block != nil {
+		// the proposal we were missing came in after all
+		return m.commit(arg.Block), nil
+	}
+	return nil, nil
+}
+
+// priorityReceived notes a proposer's claim to the period. The best claim
+// known stops us from sending a proposal it outranks.
+func (m *Machine) priorityReceived(arg *pb.ProposePriorityArgs) ([]Action, error) {
+	claim := arg.Priority
+	if claim == nil {
+		return nil, ErrBadSignature
+	}
+	if claim.Round < m.round {
+		return nil, ErrPastRound
+	}
+	if claim.Round > m.round || claim.Period > m.period {
+		return m.buffer(futureMessage{round: claim.Round, period: claim.Period, sender: claim.UserId, event: PriorityReceived{Priority: arg}}, arg.Peer)
+	}
+	if claim.Period < m.period {
+		return nil, ErrPastPeriod
+	}
+
+	if err := m.verifyPriority(claim); err != nil {
+		m.log().WithField("peer", arg.Peer).Warnf("DENIED that %v is on the committee for round %v period %v: %v", claim.UserId, m.round, m.period, err)
+		return nil, err
+	}
+	m.log().WithField("peer", arg.Peer).Debugf("Priority %.8v from %v for %v", claim.Priority, claim.UserId, claim.Value)
+	m.periodState.claim(claim.Priority)
+	return nil, nil
+}
+
+func (m *Machine) voteReceived(arg *pb.VoteArgs) ([]Action, error) {
+	if arg.Round < m.round {
+		return nil, ErrPastRound
+	}
+	message, err := parseVote(arg.Message)
+	if err == nil && message.round != arg.Round {
+		err = ErrBadSignature
+	}
+	if err != nil {
+		m.log().WithField("peer", arg.Peer).Warnf("Ignoring vote with a bad signature")
+		return nil, err
+	}
+	if message.round > m.round {
+		return m.buffer(futureMessage{round: message.round, period: message.period, sender: arg.Message.UserId, event: VoteReceived{Vote: arg}}, arg.Peer)
+	}
+	if message.period > m.period {
+		// counted right away, a quorum of next-votes there means we are behind
+		if m.laterVotes >= m.cfg.FutureMessages {
+			return nil, ErrFutureRound
+		}
+		m.laterVotes++
+	}
+
+	voterId := arg.Message.UserId
+	voteValue := message.value
+	voteType := message.voteType
+	m.log().WithField("peer", arg.Peer).Debugf("Received %vVote from: %v", voteType, voterId)
+
+	key := message.key(voterId)
+	if first, ok := m.votes[key]; ok {
+		if first.Message[0] == voteValue || m.accused[key] {
+			return nil, ErrAlreadyVoted
+		}
+		m.accused[key] = true
+		m.log().WithField("peer", arg.Peer).Warnf("EQUIVOCATION: %v voted both %v and %v in period %v step %v", voterId, first.Message[0], voteValue, message.period, message.step)
+		evidence := &pb.Evidence{
+			UserId: voterId,
+			Round:  m.round,
+			Period: message.period,
+			Step:   message.step,
+			First:  first,
+			Second: arg.Message,
+		}
+		return []Action{Equivocation{Evidence: evidence}}, ErrEquivocation
+	}
+	m.votes[key] = arg.Message
+
+	// votes for earlier periods still count, a late cert quorum ends the round all the same
+	ps := m.stateOf(message.period)
+	switch err := ps.count(voterId, voteType, voteValue); err {
+	case ErrUnknownVote:
+		m.log().WithField("peer", arg.Peer).Warnf("Unknown vote type %q", voteType)
+		return nil, err
+	case ErrAlreadyVoted:
+		m.log().WithField("peer", arg.Peer).Debugf("Ignoring %vVote from %v: already %vVoted %v in period %v", voteType, voterId, voteType, voteValue, ps.period)
+		return nil, err
+	}
+
+	switch voteType {
+	case "cert":
+		// we need to check for halting condition anytime we see a new cert vote
+		haltValue := checkHaltingCondition(ps, m.cfg.RequiredVotes)
+		if haltValue != "" && m.decided == "" {
+			return m.halt(ps.period, haltValue), nil
+		}
+	case "next":
+		if ps.period >= m.period {
+			m.completePeriod(ps)
+		}
+	}
+	return nil, nil
+}
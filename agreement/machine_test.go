@@ -0,0 +1,483 @@
+package agreement_test
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/journal"
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+	"github.com/nyu-distributed-systems-fa18/algorand/participation"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+	"github.com/nyu-distributed-systems-fa18/algorand/vrf"
+)
+
+func TestMain(m *testing.M) {
+	logging.SetOutput(ioutil.Discard)
+	logging.SetLevel(logging.PanicLevel)
+	os.Exit(m.Run())
+}
+
+// Four users with one unit of stake each, we are "a". A committee size above
+// the total stake selects everyone to propose.
+var users = []string{"a", "b", "c", "d"}
+
+// newMachine returns our machine, required votes make a quorum.
+func newMachine(required int64) (*agreement.Machine, ed25519.PrivateKey) {
+	keys := make(map[string]ed25519.PublicKey)
+	var ours ed25519.PrivateKey
+	for i, user := range users {
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0] = byte(i + 1)
+		key := ed25519.NewKeyFromSeed(seed)
+		keys[user] = key.Public().(ed25519.PublicKey)
+		if user == "a" {
+			ours = key
+		}
+	}
+	return agreement.NewMachine(agreement.Config{
+		UserId:         "a",
+		Candidates:     users,
+		VRFKeys:        keys,
+		Seed:           "genesis",
+		RequiredVotes:  required,
+		Params:         agreement.DefaultParams,
+		FutureRounds:   agreement.DefaultFutureRounds,
+		FutureMessages: agreement.DefaultFutureMessages,
+	}), ours
+}
+
+var (
+	roundTimer = agreement.Timeout{Timer: agreement.RoundTimer}
+	stepTimer  = agreement.Timeout{Timer: agreement.StepTimer}
+)
+
+func vote(user, value, voteType string, round, period, step int64) agreement.Event {
+	message := []string{value, voteType, strconv.FormatInt(period, 10), strconv.FormatInt(step, 10), strconv.FormatInt(round, 10)}
+	return agreement.VoteReceived{Vote: &pb.VoteArgs{Message: agreement.SIG(user, message), Round: round, Peer: user}}
+}
+
+// proposal is user's proposal of value, with a claim to the period but no proof.
+func proposal(user, value string, round, period int64) agreement.Event {
+	return agreement.ProposalReceived{Proposal: &pb.ProposeBlockArgs{
+		Credential: &pb.SIGRet{UserId: user},
+		Block:      &pb.Block{Id: round, Proposer: user},
+		Value:      value,
+		Round:      round,
+		Peer:       user,
+		Priority:   &pb.Priority{UserId: user, Round: round, Period: period, Value: value},
+	}}
+}
+
+// participationKeys sign the votes of every user for its first 100 rounds.
+var participationKeys = func() map[string]*participation.Key {
+	keys := make(map[string]*participation.Key)
+	for i, user := range users {
+		seed := make([]byte, ed25519.SeedSize)
+		seed[0], seed[1] = byte(i+1), 'p'
+		keys[user] = participation.Insecure(ed25519.NewKeyFromSeed(seed), 1, 100)
+	}
+	return keys
+}()
+
+// signedVote is user's vote signed with the participation key of signer.
+func signedVote(user, signer, value, voteType string, round, period, step int64) agreement.Event {
+	event := vote(user, value, voteType, round, period, step).(agreement.VoteReceived)
+	sig := event.Vote.Message
+	signature, ephemeral, err := participationKeys[signer].Sign(round, agreement.VoteSigningBytes(sig))
+	if err != nil {
+		panic(err)
+	}
+	sig.Signature = hex.EncodeToString(signature)
+	sig.Ephemeral = ephemeral
+	return event
+}
+
+// A step feeds one event to the machine. Its actions, as journal.Describe
+// puts them, have to include every line of want and none of not.
+type step struct {
+	event agreement.Event
+	// answer the last AssembleBlock with a block of this value instead
+	assemble string
+	want     []string
+	not      []string
+	err      error
+	// the event's vote conflicts with an earlier one: the step has to report
+	// both, and VerifyEvidence with the voter's participation key returns
+	// evidenceErr for them
+	equivocates bool
+	evidenceErr error
+}
+
+func TestMachine(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []step
+		// where the machine is after the last step
+		round, period int64
+		// votes a quorum takes, 3 of the 4 if 0
+		required int64
+	}{
+		{
+			name: "soft and cert quorums commit the proposal",
+			steps: []step{
+				{event: roundTimer, want: []string{"assemble round 1 period 1"}},
+				{assemble: "v1", want: []string{"propose v1 round 1"}},
+				{event: stepTimer, want: []string{"vote v1 soft 1 2 1"}},
+				{event: vote("b", "v1", "soft", 1, 1, 2)},
+				{event: vote("c", "v1", "soft", 1, 1, 2)},
+				{event: stepTimer, want: []string{"vote v1 cert 1 3 1"}, not: []string{"commit round 1 period 1 value v1"}},
+				{event: vote("b", "v1", "cert", 1, 1, 3), not: []string{"commit round 1 period 1 value v1"}},
+				{event: vote("c", "v1", "cert", 1, 1, 3), want: []string{"commit round 1 period 1 value v1"}},
+			},
+			round: 2, period: 1,
+		},
+		{
+			name: "no soft quorum means no cert vote",
+			steps: []step{
+				{event: roundTimer},
+				{assemble: "v1"},
+				{event: stepTimer, want: []string{"vote v1 soft 1 2 1"}},
+				{event: vote("b", "v1", "soft", 1, 1, 2)},
+				{event: vote("c", "v2", "soft", 1, 1, 2)},
+				{event: stepTimer, not: []string{"vote v1 cert 1 3 1"}},
+			},
+			round: 1, period: 1,
+		},
+		{
+			name: "nothing proposed settles on the empty block",
+			steps: []step{
+				{event: roundTimer, want: []string{"assemble round 1 period 1"}},
+				{event: stepTimer, want: []string{"vote empty soft 1 2 1"}},
+				{event: vote("b", agreement.Empty, "soft", 1, 1, 2)},
+				{event: vote("c", agreement.Empty, "soft", 1, 1, 2)},
+				{event: stepTimer, want: []string{"vote empty cert 1 3 1"}},
+				{event: vote("b", agreement.Empty, "cert", 1, 1, 3)},
+				{event: vote("d", agreement.Empty, "cert", 1, 1, 3), want: []string{"commit round 1 period 1 value empty"}},
+			},
+			round: 2, period: 1,
+		},
+		{
+			name: "a next quorum for bottom starts the next period",
+			steps: []step{
+				{event: roundTimer},
+				{event: stepTimer, want: []string{"vote empty soft 1 2 1"}},
+				{event: stepTimer, not: []string{"vote empty cert 1 3 1"}},
+				{event: stepTimer, want: []string{"vote _|_ next 1 4 1"}},
+				{event: vote("b", agreement.Bottom, "next", 1, 1, 4)},
+				{event: vote("c", agreement.Bottom, "next", 1, 1, 4)},
+				{event: roundTimer, want: []string{"assemble round 1 period 2"}},
+			},
+			round: 1, period: 2,
+		},
+		{
+			name: "a next quorum for a value carries it into the next period",
+			steps: []step{
+				{event: roundTimer},
+				{assemble: "v1"},
+				{event: stepTimer},
+				{event: vote("b", "v1", "soft", 1, 1, 2)},
+				{event: vote("c", "v1", "soft", 1, 1, 2)},
+				{event: stepTimer, want: []string{"vote v1 cert 1 3 1"}},
+				{event: stepTimer, want: []string{"vote v1 next 1 4 1"}},
+				{event: vote("b", "v1", "next", 1, 1, 4)},
+				{event: vote("c", "v1", "next", 1, 1, 4)},
+				{event: roundTimer},
+				{assemble: "v2"},
+				// the value next-voted in period 1 is soft-voted again, not the new proposal
+				{event: stepTimer, want: []string{"vote v1 soft 2 2 1"}, not: []string{"vote v2 soft 2 2 1"}},
+			},
+			round: 1, period: 2,
+		},
+		{
+			name: "a voter's next-votes count for one value",
+			steps: []step{
+				{event: roundTimer},
+				{event: stepTimer},
+				{event: stepTimer},
+				{event: vote("b", "v1", "next", 1, 1, 4)},
+				{event: vote("c", "v1", "next", 1, 1, 4)},
+				{event: vote("d", "v1", "next", 1, 1, 4)},
+				// in step 5 the same voters next-vote another value
+				{event: vote("b", "v2", "next", 1, 1, 5), err: agreement.ErrAlreadyVoted},
+				{event: vote("c", "v2", "next", 1, 1, 5), err: agreement.ErrAlreadyVoted},
+				{event: vote("d", "v2", "next", 1, 1, 5), err: agreement.ErrAlreadyVoted},
+				// _|_ besides the value still counts
+				{event: vote("b", agreement.Bottom, "next", 1, 1, 5)},
+				{event: roundTimer},
+				{event: stepTimer, want: []string{"vote v1 soft 2 2 1"}, not: []string{"vote v2 soft 2 2 1"}},
+			},
+			round: 1, period: 2,
+		},
+		{
+			name: "two values with a next quorum, every node carries the smallest",
+			steps: []step{
+				{event: roundTimer},
+				{event: vote("b", "v2", "next", 1, 1, 4)},
+				{event: vote("c", "v2", "next", 1, 1, 4)},
+				{event: vote("d", "v1", "next", 1, 1, 4)},
+				{event: vote("a", "v1", "next", 1, 1, 4)},
+				{event: roundTimer},
+				{event: stepTimer, want: []string{"vote v1 soft 2 2 1"}, not: []string{"vote v2 soft 2 2 1"}},
+			},
+			round: 1, period: 2,
+			// too few to rule out two quorums, as with more faulty stake than assumed
+			required: 2,
+		},
+		{
+			name: "votes for the next round wait for it, a decided block we never saw is fetched",
+			steps: []step{
+				{event: vote("b", "v2", "cert", 2, 1, 3)},
+				{event: vote("c", "v2", "cert", 2, 1, 3)},
+				{event: vote("d", "v2", "cert", 2, 1, 3)},
+				{event: roundTimer},
+				{event: stepTimer},
+				{event: vote("b", agreement.Empty, "soft", 1, 1, 2)},
+				{event: vote("c", agreement.Empty, "soft", 1, 1, 2)},
+				{event: stepTimer},
+				{event: vote("b", agreement.Empty, "cert", 1, 1, 3)},
+				{event: vote("c", agreement.Empty, "cert", 1, 1, 3), want: []string{
+					"commit round 1 period 1 value empty",
+					"fetch block v2 round 2 period 1 from b,c,d",
+				}},
+				// fetching is repeated until the block turns up
+				{event: roundTimer, want: []string{"fetch block v2 round 2 period 1 from b,c,d"}},
+				{event: roundTimer, want: []string{"fetch block v2 round 2 period 1 from b,c,d"}},
+				{event: agreement.BlockFetched{Round: 2, Value: "v2", Block: &pb.Block{Id: 2, Seed: "s2"}}, want: []string{"commit round 2 period 1 value v2"}},
+				{event: roundTimer, want: []string{"assemble round 3 period 1"}, not: []string{"fetch block v2 round 2 period 1 from b,c,d"}},
+			},
+			round: 3, period: 1,
+		},
+		{
+			name: "a proposal for the next round is handed back once we get there",
+			steps: []step{
+				{event: proposal("b", "v2", 2, 1)},
+				{event: roundTimer},
+				{event: stepTimer, not: []string{"vote v2 soft 1 2 1"}},
+				{event: vote("b", agreement.Empty, "soft", 1, 1, 2)},
+				{event: vote("c", agreement.Empty, "soft", 1, 1, 2)},
+				{event: stepTimer},
+				{event: vote("b", agreement.Empty, "cert", 1, 1, 3), not: []string{"check proposal v2 round 2"}},
+				// after the commit, so the caller checks it against round 1's block
+				{event: vote("c", agreement.Empty, "cert", 1, 1, 3), want: []string{
+					"commit round 1 period 1 value empty",
+					"check proposal v2 round 2",
+				}},
+			},
+			round: 2, period: 1,
+		},
+		{
+			name: "a conflicting vote is evidence and isn't counted",
+			steps: []step{
+				{event: roundTimer},
+				{event: stepTimer, want: []string{"vote empty soft 1 2 1"}},
+				{event: signedVote("b", "b", "v1", "soft", 1, 1, 2)},
+				{event: signedVote("b", "b", "v2", "soft", 1, 1, 2), want: []string{"equivocation by b period 1 step 2"}, err: agreement.ErrEquivocation, equivocates: true},
+				// reported once
+				{event: signedVote("b", "b", "v3", "soft", 1, 1, 2), not: []string{"equivocation by b period 1 step 2"}, err: agreement.ErrAlreadyVoted},
+				{event: signedVote("c", "c", "v2", "soft", 1, 1, 2)},
+				{event: signedVote("d", "d", "v2", "soft", 1, 1, 2)},
+				// b's second vote would have made three
+				{event: stepTimer, not: []string{"vote v2 cert 1 3 1"}},
+				// the same vote again is no equivocation
+				{event: signedVote("c", "c", "v2", "soft", 1, 1, 2), not: []string{"equivocation by c period 1 step 2"}, err: agreement.ErrAlreadyVoted},
+			},
+			round: 1, period: 1,
+		},
+		{
+			name: "evidence from forged votes doesn't verify",
+			steps: []step{
+				{event: roundTimer},
+				{event: signedVote("b", "b", "v1", "soft", 1, 1, 2)},
+				{event: vote("b", "v2", "soft", 1, 1, 2), want: []string{"equivocation by b period 1 step 2"}, err: agreement.ErrEquivocation, equivocates: true, evidenceErr: agreement.ErrUnsignedVote},
+				{event: signedVote("c", "c", "v1", "cert", 1, 1, 3)},
+				{event: signedVote("c", "d", "v2", "cert", 1, 1, 3), want: []string{"equivocation by c period 1 step 3"}, err: agreement.ErrEquivocation, equivocates: true, evidenceErr: agreement.ErrUnsignedVote},
+			},
+			round: 1, period: 1,
+		},
+		{
+			name: "messages too far ahead make us sync",
+			steps: []step{
+				{event: vote("b", "v9", "soft", 1+agreement.DefaultFutureRounds+1, 1, 2), want: []string{"request sync"}, err: agreement.ErrFutureRound},
+				// once per round interval
+				{event: vote("c", "v9", "soft", 1+agreement.DefaultFutureRounds+1, 1, 2), not: []string{"request sync"}, err: agreement.ErrFutureRound},
+				{event: roundTimer},
+				{event: vote("d", "v9", "soft", 1+agreement.DefaultFutureRounds+1, 1, 2), want: []string{"request sync"}, err: agreement.ErrFutureRound},
+			},
+			round: 1, period: 1,
+		},
+		{
+			name: "past and duplicate votes are turned away",
+			steps: []step{
+				{event: roundTimer},
+				{event: stepTimer},
+				{event: vote("b", agreement.Empty, "soft", 1, 1, 2)},
+				{event: vote("b", agreement.Empty, "soft", 1, 1, 2), err: agreement.ErrAlreadyVoted},
+				{event: vote("c", agreement.Empty, "soft", 1, 1, 2)},
+				{event: stepTimer},
+				{event: vote("b", agreement.Empty, "cert", 1, 1, 3)},
+				{event: vote("c", agreement.Empty, "cert", 1, 1, 3), want: []string{"commit round 1 period 1 value empty"}},
+				{event: vote("d", agreement.Empty, "cert", 1, 1, 3), err: agreement.ErrPastRound},
+			},
+			round: 2, period: 1,
+		},
+	}
+
+	for _, test := range tests {
+		if test.required == 0 {
+			test.required = 3
+		}
+		m, key := newMachine(test.required)
+		m.Start()
+		var assemble *agreement.AssembleBlock
+		for i, s := range test.steps {
+			event := s.event
+			if s.assemble != "" {
+				if assemble == nil {
+					t.Fatalf("%v: step %v: nothing to assemble", test.name, i)
+				}
+				_, proof := vrf.Prove(key, assemble.Seed)
+				block := &pb.Block{Id: assemble.Round, Proposer: "a"}
+				event = agreement.BlockAssembled{Round: assemble.Round, Period: assemble.Period, Block: block, Value: s.assemble, Proof: proof}
+			}
+
+			actions, err := m.Handle(event)
+			if err != s.err {
+				t.Errorf("%v: step %v: got error %v, want %v", test.name, i, err, s.err)
+			}
+			for _, a := range actions {
+				if a, ok := a.(agreement.AssembleBlock); ok {
+					assemble = &a
+				}
+			}
+			lines := journal.Describe(actions)
+			for _, want := range s.want {
+				if !contains(lines, want) {
+					t.Errorf("%v: step %v: no %q in %q", test.name, i, want, lines)
+				}
+			}
+			for _, not := range s.not {
+				if contains(lines, not) {
+					t.Errorf("%v: step %v: unexpected %q in %q", test.name, i, not, lines)
+				}
+			}
+			if s.equivocates {
+				checkEvidence(t, test.name, i, event, actions, s.evidenceErr)
+			}
+		}
+		if m.Round() != test.round || m.Period() != test.period {
+			t.Errorf("%v: ended in round %v period %v, want round %v period %v", test.name, m.Round(), m.Period(), test.round, test.period)
+		}
+	}
+}
+
+// The seed sortition draws from follows the chain: the committed block's,
+// or for an empty round one derived from the last.
+func TestMachineSeed(t *testing.T) {
+	m, _ := newMachine(3)
+	m.Start()
+	seedOf := func(actions []agreement.Action) []byte {
+		for _, a := range actions {
+			if a, ok := a.(agreement.AssembleBlock); ok {
+				return a.Seed
+			}
+		}
+		t.Fatalf("no AssembleBlock in %v", journal.Describe(actions))
+		return nil
+	}
+	commitEmpty := func(round int64) {
+		m.Handle(stepTimer)
+		m.Handle(vote("b", agreement.Empty, "soft", round, 1, 2))
+		m.Handle(vote("c", agreement.Empty, "soft", round, 1, 2))
+		m.Handle(stepTimer)
+		m.Handle(vote("b", agreement.Empty, "cert", round, 1, 3))
+		actions, _ := m.Handle(vote("c", agreement.Empty, "cert", round, 1, 3))
+		if !contains(journal.Describe(actions), "commit round "+strconv.FormatInt(round, 10)+" period 1 value empty") {
+			t.Fatalf("round %v didn't commit the empty block: %q", round, journal.Describe(actions))
+		}
+	}
+
+	actions, _ := m.Handle(roundTimer)
+	first := seedOf(actions)
+	if !strings.Contains(string(first), "genesis") {
+		t.Errorf("round 1 draws from %q, not the genesis seed", first)
+	}
+	commitEmpty(1)
+	actions, _ = m.Handle(roundTimer)
+	if second := seedOf(actions); !strings.Contains(string(second), agreement.EmptySeed("genesis", 1)) {
+		t.Errorf("round 2 draws from %q, not the seed of round 1's empty block", second)
+	}
+
+	m.Handle(agreement.Synced{Round: 7, Seed: "synced"})
+	actions, _ = m.Handle(roundTimer)
+	if synced := seedOf(actions); !strings.Contains(string(synced), "synced") || reflect.DeepEqual(synced, first) {
+		t.Errorf("round 7 draws from %q, not the seed of the chain we synced to", synced)
+	}
+}
+
+func TestBlockSeed(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	other := ed25519.NewKeyFromSeed(append([]byte{1}, make([]byte, ed25519.SeedSize-1)...))
+	block := &pb.Block{Id: 5}
+	block.Seed, block.SeedProof = agreement.BlockSeed(key, "prev", 5)
+
+	if err := agreement.VerifyBlockSeed(block, "prev", key.Public().(ed25519.PublicKey)); err != nil {
+		t.Errorf("valid seed: %v", err)
+	}
+	tests := []struct {
+		name     string
+		prevSeed string
+		key      ed25519.PublicKey
+		seed     string
+	}{
+		{"other previous seed", "other", key.Public().(ed25519.PublicKey), block.Seed},
+		{"other proposer", "prev", other.Public().(ed25519.PublicKey), block.Seed},
+		{"no proposer key", "prev", nil, block.Seed},
+		{"chosen seed", "prev", key.Public().(ed25519.PublicKey), agreement.EmptySeed("prev", 5)},
+	}
+	for _, test := range tests {
+		b := *block
+		b.Seed = test.seed
+		if err := agreement.VerifyBlockSeed(&b, test.prevSeed, test.key); err != agreement.ErrBadSeed {
+			t.Errorf("%v: got %v, want %v", test.name, err, agreement.ErrBadSeed)
+		}
+	}
+}
+
+// checkEvidence checks that actions report the vote of event together with
+// the voter's earlier, conflicting one.
+func checkEvidence(t *testing.T, name string, i int, event agreement.Event, actions []agreement.Action, want error) {
+	second := event.(agreement.VoteReceived).Vote.Message
+	var e *pb.Evidence
+	for _, a := range actions {
+		if a, ok := a.(agreement.Equivocation); ok {
+			e = a.Evidence
+		}
+	}
+	if e == nil {
+		t.Errorf("%v: step %v: no evidence in %q", name, i, journal.Describe(actions))
+		return
+	}
+	if e.UserId != second.UserId || e.Second != second || e.First == nil || e.First.Message[0] == second.Message[0] {
+		t.Errorf("%v: step %v: evidence against %v holds %v and %v", name, i, e.UserId, e.First.GetMessage(), e.Second.GetMessage())
+	}
+	if err := agreement.VerifyEvidence(e, participationKeys[e.UserId].Root); err != want {
+		t.Errorf("%v: step %v: verifying the evidence: got %v, want %v", name, i, err, want)
+	}
+}
+
+func contains(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
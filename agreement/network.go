@@ -0,0 +1,13 @@
+package agreement
+
+import (
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+// Network delivers our messages to every other participant. Delivery is best
+// effort, BA* tolerates lost messages through its timeouts.
+type Network interface {
+	BroadcastProposal(proposal *pb.ProposeBlockArgs)
+	BroadcastPriority(priority *pb.ProposePriorityArgs)
+	BroadcastVote(vote *pb.VoteArgs)
+}
@@ -0,0 +1,58 @@
+package agreement
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Params are the protocol's timing and committee parameters. Every node has
+// to run with the same ones. The timeouts follow the paper: λ bounds how long
+// a vote takes to reach everyone, Λ how long a block does.
+type Params struct {
+	// λ, time to gossip a vote. Step 5 checks for a quorum this often.
+	Lambda time.Duration
+	// Λ, time to gossip a block. Steps 1 to 4 each last this long so
+	// proposals and the votes on them can arrive.
+	BigLambda time.Duration
+	// how often a node checks whether it can start its next round or period
+	RoundInterval time.Duration
+
+	// committee size k, the number of proposers sortition selects per period
+	// on average. Sometimes none is selected and the period goes to ⊥.
+	K int64
+}
+
+// DefaultParams suit a small network on a LAN.
+var DefaultParams = Params{
+	Lambda:        2000 * time.Millisecond,
+	BigLambda:     10000 * time.Millisecond,
+	RoundInterval: 5000 * time.Millisecond,
+	K:             5,
+}
+
+// StepTimeout is how long each of steps 1 to 4 lasts.
+func (p Params) StepTimeout() time.Duration {
+	return p.BigLambda
+}
+
+// RecoveryTimeout is how often step 5 looks for a quorum again.
+func (p Params) RecoveryTimeout() time.Duration {
+	return p.Lambda
+}
+
+func (p Params) Validate() error {
+	if p.Lambda <= 0 {
+		return errors.New("lambda has to be positive")
+	}
+	if p.BigLambda < p.Lambda {
+		return fmt.Errorf("big lambda %v is shorter than lambda %v, a block can't spread faster than a vote", p.BigLambda, p.Lambda)
+	}
+	if p.RoundInterval <= 0 {
+		return errors.New("round interval has to be positive")
+	}
+	if p.K < 1 {
+		return fmt.Errorf("committee size %v has to be at least 1", p.K)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package agreement
+
+import (
+	"time"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+// A Journal is told about every input the machine handles and what it
+// decided, so a run can be replayed later. See the journal package.
+type Journal interface {
+	Started(at time.Time, cfg Config, actions []Action)
+	Handled(at time.Time, ev Event, actions []Action, err error, round, period, step int64)
+}
+
+// Service runs a Machine against a Clock and a Network. It carries out the
+// timer and broadcast actions itself and hands everything else (assembling
+// blocks, committing, syncing) back to the caller.
+//
+// A Service is not safe for concurrent use: the caller delivers every event,
+// including the timeouts read from Timeouts, from a single goroutine.
+type Service struct {
+	machine *Machine
+	clock   Clock
+	network Network
+
+	journal Journal
+
+	timers   map[TimerKind]Timer
+	timerSeq map[TimerKind]uint64
+	timeouts chan Timeout
+}
+
+func NewService(cfg Config, clock Clock, network Network) *Service {
+	return &Service{
+		machine:  NewMachine(cfg),
+		clock:    clock,
+		network:  network,
+		timers:   make(map[TimerKind]Timer),
+		timerSeq: make(map[TimerKind]uint64),
+		// room for a fired timer per kind plus one that fired while being reset
+		timeouts: make(chan Timeout, 4),
+	}
+}
+
+// SetJournal records everything from now on to j, set it before Start.
+func (s *Service) SetJournal(j Journal) {
+	s.journal = j
+}
+
+// Timeouts delivers fired timers, pass them back to Handle.
+func (s *Service) Timeouts() <-chan Timeout {
+	return s.timeouts
+}
+
+func (s *Service) Round() int64 {
+	return s.machine.Round()
+}
+
+func (s *Service) Period() int64 {
+	return s.machine.Period()
+}
+
+func (s *Service) Step() int64 {
+	return s.machine.Step()
+}
+
+func (s *Service) Snapshot() Snapshot {
+	return s.machine.Snapshot()
+}
+
+func (s *Service) CurrentProposal() *pb.ProposeBlockArgs {
+	return s.machine.CurrentProposal()
+}
+
+func (s *Service) Block(value string) *pb.Block {
+	return s.machine.Block(value)
+}
+
+// Start arms the first timers.
+func (s *Service) Start() []Action {
+	actions := s.machine.Start()
+	if s.journal != nil {
+		s.journal.Started(s.clock.Now(), s.machine.Config(), actions)
+	}
+	return s.perform(actions)
+}
+
+// Handle passes ev to the machine and returns the actions left for the caller.
+func (s *Service) Handle(ev Event) ([]Action, error) {
+	if t, ok := ev.(Timeout); ok && t.seq != s.timerSeq[t.Timer] {
+		// the timer was reset after this fired
+		return nil, nil
+	}
+	actions, err := s.machine.Handle(ev)
+	if s.journal != nil {
+		s.journal.Handled(s.clock.Now(), ev, actions, err, s.machine.Round(), s.machine.Period(), s.machine.Step())
+	}
+	return s.perform(actions), err
+}
+
+func (s *Service) perform(actions []Action) []Action {
+	var rest []Action
+	for _, a := range actions {
+		switch a := a.(type) {
+		case SetTimer:
+			s.setTimer(a)
+		case BroadcastProposal:
+			s.network.BroadcastProposal(a.Proposal)
+		case BroadcastPriority:
+			s.network.BroadcastPriority(a.Priority)
+		case BroadcastVote:
+			s.network.BroadcastVote(a.Vote)
+		default:
+			rest = append(rest, a)
+		}
+	}
+	return rest
+}
+
+func (s *Service) setTimer(a SetTimer) {
+	if timer, ok := s.timers[a.Timer]; ok {
+		timer.Stop()
+	}
+	s.timerSeq[a.Timer]++
+	timeout := Timeout{Timer: a.Timer, seq: s.timerSeq[a.Timer]}
+	s.timers[a.Timer] = s.clock.AfterFunc(a.Duration, func() {
+		s.timeouts <- timeout
+	})
+}
+
+// Stop disarms every timer, the machine stays in its current step. Call it
+// from the goroutine that delivers events, once no more will be delivered.
+func (s *Service) Stop() {
+	for _, timer := range s.timers {
+		timer.Stop()
+	}
+}
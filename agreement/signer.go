@@ -0,0 +1,43 @@
+package agreement
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/participation"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+var ErrUnsignedVote = errors.New("vote isn't signed by the voter's participation key for the round")
+
+// A Signer signs our votes with the one-time key of their round, which the
+// participation key registered for the round set up. It returns that key
+// along with the signature, see package participation.
+type Signer interface {
+	Sign(round int64, message []byte) ([]byte, *pb.EphemeralKey, error)
+}
+
+// VoteSigningBytes is what a voter signs: its user id and the vote, each
+// prefixed with its length so no two votes share an encoding.
+func VoteSigningBytes(sig *pb.SIGRet) []byte {
+	var b []byte
+	for _, field := range append([]string{sig.UserId}, sig.Message...) {
+		b = strconv.AppendInt(b, int64(len(field)), 10)
+		b = append(b, ':')
+		b = append(b, field...)
+	}
+	return b
+}
+
+// VerifyVoteSignature checks that sig was signed by the one-time key of
+// round that key, the voter's participation key for the round, set up.
+func VerifyVoteSignature(sig *pb.SIGRet, round int64, key ed25519.PublicKey) error {
+	signature, err := hex.DecodeString(sig.GetSignature())
+	if err != nil || sig.GetEphemeral() == nil || participation.Verify(key, round, VoteSigningBytes(sig), signature, sig.GetEphemeral()) != nil {
+		return ErrUnsignedVote
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package agreement
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+	"github.com/nyu-distributed-systems-fa18/algorand/vrf"
+)
+
+func signMessage(message []string) string {
+	s := strings.Join(message[:], "")
+
+	h := sha256.New()
+	h.Write([]byte(s))
+	hashed := h.Sum(nil)
+	return hex.EncodeToString(hashed)
+}
+
+// sortitionSeed is what every user proves with its VRF key to find out
+// whether it may propose in round and period. seed is the seed of the block
+// before round, which nobody could pick, see BlockSeedInput. Drawing anew
+// each period means a period whose proposers all stay silent doesn't doom
+// the next.
+func sortitionSeed(seed string, round int64, period int64) []byte {
+	return []byte("proposer," + seed + "," + strconv.FormatInt(round, 10) + "," + strconv.FormatInt(period, 10))
+}
+
+// BlockSeedInput is what the proposer of a block for round proves with its
+// VRF key, the output is the block's seed. prevSeed is the seed of the block
+// before. The proposer can't choose the input or the output, so it can't
+// grind the seed to win sortition in later rounds.
+func BlockSeedInput(prevSeed string, round int64) []byte {
+	return []byte("block," + prevSeed + "," + strconv.FormatInt(round, 10))
+}
+
+// BlockSeed returns the seed of a block for round and its proof, proven with
+// the proposer's VRF key.
+func BlockSeed(key ed25519.PrivateKey, prevSeed string, round int64) (seed string, proof string) {
+	output, p := vrf.Prove(key, BlockSeedInput(prevSeed, round))
+	return hex.EncodeToString(output), hex.EncodeToString(p)
+}
+
+// EmptySeed is the seed of the empty block of round, see Empty.
+func EmptySeed(prevSeed string, round int64) string {
+	h := sha256.Sum256([]byte("empty," + prevSeed + "," + strconv.FormatInt(round, 10)))
+	return hex.EncodeToString(h[:])
+}
+
+// VerifyBlockSeed checks that the seed of block, proposed by the owner of
+// key, follows from prevSeed.
+func VerifyBlockSeed(block *pb.Block, prevSeed string, key ed25519.PublicKey) error {
+	proof, err := hex.DecodeString(block.SeedProof)
+	if err != nil || key == nil {
+		return ErrBadSeed
+	}
+	output, err := vrf.Verify(key, BlockSeedInput(prevSeed, block.Id), proof)
+	if err != nil || hex.EncodeToString(output) != block.Seed {
+		return ErrBadSeed
+	}
+	return nil
+}
+
+// subUsers splits a user's stake into one sub-user per unit, each selected
+// with probability k/total. Which are selected follows from the user's VRF
+// output alone, so it can neither pick nor hide them. priority is the lowest
+// hash of a selected sub-user, hex encoded, "" with none selected.
+func subUsers(output []byte, stake int64, total int64, k int64) (votes int64, priority string) {
+	// a sub-user is selected when the first 8 bytes of its hash fall below threshold
+	threshold := new(big.Int).Lsh(big.NewInt(k), 64)
+	threshold.Div(threshold, big.NewInt(total))
+	everyone := threshold.BitLen() > 64
+
+	var best []byte
+	for j := int64(0); j < stake; j++ {
+		h := sha256.New()
+		h.Write(output)
+		binary.Write(h, binary.BigEndian, j)
+		hashed := h.Sum(nil)
+
+		if !everyone && new(big.Int).SetUint64(binary.BigEndian.Uint64(hashed)).Cmp(threshold) >= 0 {
+			continue
+		}
+		votes++
+		if best == nil || bytes.Compare(hashed, best) < 0 {
+			best = hashed
+		}
+	}
+	if votes == 0 {
+		return 0, ""
+	}
+	return votes, hex.EncodeToString(best)
+}
+
+// stakeOf counts how often userId appears among the candidates.
+func stakeOf(userId string, candidates []string) int64 {
+	stake := int64(0)
+	for _, candidate := range candidates {
+		if candidate == userId {
+			stake++
+		}
+	}
+	return stake
+}
+
+// sortition tells from our VRF output for the period whether we may propose
+// in it, and with what priority.
+func (m *Machine) sortition(userId string, output []byte) (int64, string) {
+	return subUsers(output, stakeOf(userId, m.cfg.Candidates), int64(len(m.cfg.Candidates)), m.cfg.Params.K)
+}
+
+// verifyPriority checks that p was won through sortition: its proof is its
+// proposer's for the period, and the proven output selects the proposer with
+// the priority it claims.
+func (m *Machine) verifyPriority(p *pb.Priority) error {
+	if p == nil {
+		return ErrBadSignature
+	}
+	key, ok := m.cfg.VRFKeys[p.UserId]
+	if !ok {
+		return ErrNotOnCommittee
+	}
+	proof, err := hex.DecodeString(p.Proof)
+	if err != nil {
+		return ErrBadSignature
+	}
+	output, err := vrf.Verify(key, sortitionSeed(m.seed, p.Round, p.Period), proof)
+	if err != nil {
+		return ErrBadSignature
+	}
+	votes, priority := m.sortition(p.UserId, output)
+	if votes == 0 {
+		return ErrNotOnCommittee
+	}
+	if priority != p.Priority {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+func SIG(i string, message []string) *pb.SIGRet {
+	signedMessage := signMessage(message)
+	return &pb.SIGRet{UserId: i, Message: message, SignedMessage: signedMessage}
+}
+
+// verifySIG checks that sig was produced by SIG for the message it carries.
+func verifySIG(sig *pb.SIGRet) bool {
+	return sig != nil && sig.SignedMessage == signMessage(sig.Message)
+}
+
+// selectLeader picks the value proposed with the lowest priority.
+func selectLeader(proposedValues map[string]string) string {
+	minCredential := ""
+	value := ""
+
+	for k, v := range proposedValues {
+		if minCredential == "" {
+			minCredential = k
+			value = v
+		} else if k < minCredential {
+			minCredential = k
+			value = v
+		}
+	}
+
+	return value
+}
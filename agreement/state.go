@@ -0,0 +1,158 @@
+package agreement
+
+import (
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+// The value voted for when a period should not settle on any proposal.
+const Bottom = "_|_"
+
+// The value soft-voted when no proposal reached us in a period. Agreeing on
+// it settles the round on its empty block: no transactions, built by every
+// node the same way from the previous block, see Commit. Unlike Bottom it is
+// a value like any other, so a period certifying it ends the round.
+const Empty = "empty"
+
+type PeriodState struct {
+	// priority to the value proposed with it, for the proposals we received
+	proposedValues map[string]string
+	valueToBlock   map[string]*pb.Block
+	// the lowest priority claimed this period, whether its block arrived or not
+	bestPriority string
+
+	nextVotes map[string]int64
+	softVotes map[string]int64
+	certVotes map[string]int64
+
+	// steps 4 and 5 can both next-vote: each voter counts for at most one
+	// value besides _|_, and for _|_ once
+	nextVoted       map[string]string
+	nextVotedBottom map[string]bool
+	haveSoftVoted   map[string]bool
+	haveCertVoted   map[string]bool
+
+	myCertVote    string
+	startingValue string
+	period        int64
+}
+
+func initPeriodState(p int64) PeriodState {
+	newPeriodState := PeriodState{
+		proposedValues: make(map[string]string),
+		valueToBlock:   make(map[string]*pb.Block),
+
+		nextVotes: make(map[string]int64),
+		softVotes: make(map[string]int64),
+		certVotes: make(map[string]int64),
+
+		nextVoted:       make(map[string]string),
+		nextVotedBottom: make(map[string]bool),
+		haveSoftVoted:   make(map[string]bool),
+		haveCertVoted:   make(map[string]bool),
+
+		myCertVote:    "",
+		startingValue: "",
+		period:        p,
+	}
+
+	return newPeriodState
+}
+
+// claim notes a verified priority for the period.
+func (ps *PeriodState) claim(priority string) {
+	if ps.bestPriority == "" || priority < ps.bestPriority {
+		ps.bestPriority = priority
+	}
+}
+
+// count adds voter's vote to the tallies, unless it already voted that way this period.
+func (ps *PeriodState) count(voter string, voteType string, value string) error {
+	switch voteType {
+	case "soft":
+		if ps.haveSoftVoted[voter] {
+			return ErrAlreadyVoted
+		}
+		ps.haveSoftVoted[voter] = true
+		ps.softVotes[value]++
+	case "cert":
+		if ps.haveCertVoted[voter] {
+			return ErrAlreadyVoted
+		}
+		ps.haveCertVoted[voter] = true
+		ps.certVotes[value]++
+	case "next":
+		if value == Bottom {
+			if ps.nextVotedBottom[voter] {
+				return ErrAlreadyVoted
+			}
+			ps.nextVotedBottom[voter] = true
+		} else {
+			if _, ok := ps.nextVoted[voter]; ok {
+				return ErrAlreadyVoted
+			}
+			ps.nextVoted[voter] = value
+		}
+		ps.nextVotes[value]++
+	default:
+		return ErrUnknownVote
+	}
+	return nil
+}
+
+// PeriodSnapshot is a copy of a period's tallies for inspection.
+type PeriodSnapshot struct {
+	Period int64
+	// priority to proposed value
+	Proposals map[string]string
+	// the lowest priority claimed, its block may not be in Proposals
+	BestPriority string
+	// values we have the block for
+	Blocks map[string]bool
+
+	SoftVotes map[string]int64
+	CertVotes map[string]int64
+	NextVotes map[string]int64
+
+	StartingValue string
+	MyCertVote    string
+}
+
+func copyCounts(m map[string]int64) map[string]int64 {
+	c := make(map[string]int64, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func (ps *PeriodState) snapshot() PeriodSnapshot {
+	s := PeriodSnapshot{
+		Period:        ps.period,
+		Proposals:     make(map[string]string, len(ps.proposedValues)),
+		Blocks:        make(map[string]bool, len(ps.valueToBlock)),
+		SoftVotes:     copyCounts(ps.softVotes),
+		CertVotes:     copyCounts(ps.certVotes),
+		NextVotes:     copyCounts(ps.nextVotes),
+		BestPriority:  ps.bestPriority,
+		StartingValue: ps.startingValue,
+		MyCertVote:    ps.myCertVote,
+	}
+	for priority, value := range ps.proposedValues {
+		s.Proposals[priority] = value
+	}
+	for value, block := range ps.valueToBlock {
+		s.Blocks[value] = block != nil
+	}
+	return s
+}
+
+// Snapshot is a copy of where a Machine is and what it has seen this round.
+type Snapshot struct {
+	Round  int64
+	Period int64
+	Step   int64
+
+	Current PeriodSnapshot
+	// the previous period of this round, Period is 0 in period 1
+	Last PeriodSnapshot
+}
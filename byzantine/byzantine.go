@@ -0,0 +1,312 @@
+// Package byzantine turns an honest node into a faulty one for testing. It
+// wraps the network the agreement service broadcasts through and rewrites,
+// duplicates, drops or replays what goes out, so the rest of the network sees
+// the kind of misbehaviour BA* has to tolerate from up to t nodes.
+//
+// Never enable it on a node that holds real stake.
+package byzantine
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+type Mode string
+
+const (
+	// propose two different blocks for the same round and period, each to half the peers
+	Equivocate Mode = "equivocate"
+	// send two different votes for the same step, in opposite order to each half of the peers
+	DoubleVote Mode = "double-vote"
+	// propose with a priority sortition didn't give us and send votes whose signature doesn't match
+	Forge Mode = "forge"
+	// never vote
+	Withhold Mode = "withhold"
+	// propose blocks that don't hash to the proposed value and don't link to the chain
+	InvalidBlock Mode = "invalid-block"
+	// send every message again in later rounds
+	Replay Mode = "replay"
+)
+
+var AllModes = []Mode{Equivocate, DoubleVote, Forge, Withhold, InvalidBlock, Replay}
+
+// Modes is a set of enabled modes.
+type Modes map[Mode]bool
+
+// ParseModes parses a comma separated list of modes, "all" enables every one of them.
+func ParseModes(s string) (Modes, error) {
+	modes := make(Modes)
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if name == "all" {
+			for _, m := range AllModes {
+				modes[m] = true
+			}
+			continue
+		}
+		known := false
+		for _, m := range AllModes {
+			if Mode(name) == m {
+				known = true
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown byzantine mode %q, expected one of %v or all", name, AllModes)
+		}
+		modes[Mode(name)] = true
+	}
+	return modes, nil
+}
+
+func (m Modes) String() string {
+	names := []string{}
+	for mode := range m {
+		names = append(names, string(mode))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// PeerNetwork is an agreement.Network that can also address peers one at a time.
+type PeerNetwork interface {
+	agreement.Network
+
+	Peers() []string
+	SendProposal(peer string, proposal *pb.ProposeBlockArgs)
+	SendVote(peer string, vote *pb.VoteArgs)
+}
+
+type Config struct {
+	UserId string
+	Modes  Modes
+	// Hash computes the value of a block, used to make conflicting proposals look legitimate
+	Hash func(*pb.Block) string
+	Rand *rand.Rand
+	// Signer signs the extra votes with our real participation key, nil
+	// leaves them unsigned like the node's own
+	Signer agreement.Signer
+}
+
+// Network implements agreement.Network, misbehaving as configured on top of inner.
+type Network struct {
+	inner PeerNetwork
+	cfg   Config
+
+	// everything we sent, for Replay
+	sentProposals []*pb.ProposeBlockArgs
+	sentVotes     []*pb.VoteArgs
+	replayedRound int64
+}
+
+func NewNetwork(inner PeerNetwork, cfg Config) *Network {
+	if cfg.Rand == nil {
+		cfg.Rand = rand.New(rand.NewSource(1))
+	}
+	return &Network{inner: inner, cfg: cfg}
+}
+
+func (n *Network) Modes() Modes {
+	return n.cfg.Modes
+}
+
+// split shuffles the peers and cuts them in two halves.
+func (n *Network) split() ([]string, []string) {
+	peers := append([]string{}, n.inner.Peers()...)
+	n.cfg.Rand.Shuffle(len(peers), func(i, j int) { peers[i], peers[j] = peers[j], peers[i] })
+	return peers[:len(peers)/2], peers[len(peers)/2:]
+}
+
+// conflicting returns a copy of proposal for a different block that still hashes correctly.
+func (n *Network) conflicting(proposal *pb.ProposeBlockArgs) *pb.ProposeBlockArgs {
+	other := proto.Clone(proposal).(*pb.ProposeBlockArgs)
+	if other.Block == nil {
+		other.Block = &pb.Block{Id: proposal.Round}
+	}
+	if t, err := time.Parse(time.RFC3339Nano, other.Block.Timestamp); err == nil {
+		// still a timestamp honest nodes accept
+		other.Block.Timestamp = t.Add(time.Millisecond).Format(time.RFC3339Nano)
+	} else {
+		other.Block.Timestamp += " (equivocation)"
+	}
+	other.Block.Hash = ""
+	if n.cfg.Hash != nil {
+		other.Block.Hash = n.cfg.Hash(other.Block)
+	}
+	other.Value = other.Block.Hash
+	if other.Priority != nil {
+		// the priority doesn't depend on the value, it is as good for the other block
+		other.Priority.Value = other.Value
+	}
+	return other
+}
+
+func (n *Network) BroadcastProposal(proposal *pb.ProposeBlockArgs) {
+	n.replay(proposal.Round)
+
+	if n.cfg.Modes[InvalidBlock] {
+		invalid := proto.Clone(proposal).(*pb.ProposeBlockArgs)
+		if invalid.Block == nil {
+			invalid.Block = &pb.Block{Id: proposal.Round}
+		}
+		invalid.Block.PrevHash = "not-the-previous-block"
+		invalid.Block.Id += 1000
+		// Value is left alone, so it no longer matches the block
+		logging.Network.Warnf("BYZANTINE: proposing an invalid block for round %v", proposal.Round)
+		proposal = invalid
+	}
+
+	if n.cfg.Modes[Equivocate] {
+		other := n.conflicting(proposal)
+		left, right := n.split()
+		logging.Network.Warnf("BYZANTINE: equivocating in round %v, %.8v to %v and %.8v to %v", proposal.Round, proposal.Value, left, other.Value, right)
+		for _, p := range left {
+			n.inner.SendProposal(p, proposal)
+		}
+		for _, p := range right {
+			n.inner.SendProposal(p, other)
+		}
+		n.sentProposals = append(n.sentProposals, proposal, other)
+		return
+	}
+
+	n.inner.BroadcastProposal(proposal)
+	n.sentProposals = append(n.sentProposals, proposal)
+}
+
+func (n *Network) BroadcastPriority(priority *pb.ProposePriorityArgs) {
+	n.inner.BroadcastPriority(priority)
+}
+
+// Assembled is called whenever the node assembled its block for a period,
+// whether or not sortition selected it to propose. proof is its real VRF
+// proof for the period.
+func (n *Network) Assembled(round, period int64, block *pb.Block, value string, proof []byte) {
+	if !n.cfg.Modes[Forge] {
+		return
+	}
+	// a correctly signed credential and our real proof, claiming the best priority there is
+	credential := agreement.SIG(n.cfg.UserId, []string{strconv.FormatInt(round, 10), strconv.FormatInt(period, 10)})
+	claim := &pb.Priority{
+		UserId:   n.cfg.UserId,
+		Round:    round,
+		Period:   period,
+		Value:    value,
+		Proof:    hex.EncodeToString(proof),
+		Priority: strings.Repeat("0", 64),
+	}
+	logging.Network.Warnf("BYZANTINE: claiming the lead in round %v period %v", round, period)
+	n.inner.BroadcastPriority(&pb.ProposePriorityArgs{Priority: claim, Peer: n.cfg.UserId})
+	n.BroadcastProposal(&pb.ProposeBlockArgs{Block: block, Credential: credential, Value: value, Round: round, Peer: n.cfg.UserId, Priority: claim})
+}
+
+func (n *Network) BroadcastVote(vote *pb.VoteArgs) {
+	n.replay(vote.Round)
+
+	if n.cfg.Modes[Withhold] {
+		logging.Network.Warnf("BYZANTINE: withholding %v vote in round %v", vote.Message.Message[1], vote.Round)
+		return
+	}
+
+	if n.cfg.Modes[Forge] {
+		forged := proto.Clone(vote).(*pb.VoteArgs)
+		forged.Message.Message[0] = "forged-" + forged.Message.Message[0]
+		// SignedMessage and Signature still cover the original value
+		logging.Network.Warnf("BYZANTINE: forging %v vote in round %v", vote.Message.Message[1], vote.Round)
+		vote = forged
+	}
+
+	if n.cfg.Modes[DoubleVote] {
+		message := append([]string{}, vote.Message.Message...)
+		if message[0] == agreement.Bottom {
+			message[0] = "double-vote"
+		} else {
+			message[0] = agreement.Bottom
+		}
+		other := &pb.VoteArgs{Message: agreement.SIG(n.cfg.UserId, message), Round: vote.Round, Peer: vote.Peer}
+		if n.cfg.Signer != nil {
+			if signature, ephemeral, err := n.cfg.Signer.Sign(vote.Round, agreement.VoteSigningBytes(other.Message)); err == nil {
+				other.Message.Signature = hex.EncodeToString(signature)
+				other.Message.Ephemeral = ephemeral
+			}
+		}
+
+		left, right := n.split()
+		logging.Network.Warnf("BYZANTINE: double voting %v in round %v, %.8v and %.8v", message[1], vote.Round, vote.Message.Message[0], message[0])
+		for _, p := range left {
+			n.inner.SendVote(p, vote)
+			n.inner.SendVote(p, other)
+		}
+		for _, p := range right {
+			n.inner.SendVote(p, other)
+			n.inner.SendVote(p, vote)
+		}
+		n.sentVotes = append(n.sentVotes, vote, other)
+		return
+	}
+
+	n.inner.BroadcastVote(vote)
+	n.sentVotes = append(n.sentVotes, vote)
+}
+
+// replay sends everything from earlier rounds again, once per round.
+func (n *Network) replay(round int64) {
+	if !n.cfg.Modes[Replay] || round <= n.replayedRound {
+		return
+	}
+	n.replayedRound = round
+
+	replayed := 0
+	for _, p := range n.sentProposals {
+		if p.Round < round {
+			n.inner.BroadcastProposal(p)
+			replayed++
+		}
+	}
+	for _, v := range n.sentVotes {
+		if v.Round < round {
+			n.inner.BroadcastVote(v)
+			replayed++
+		}
+	}
+	if replayed > 0 {
+		logging.Network.Warnf("BYZANTINE: replayed %v old messages in round %v", replayed, round)
+	}
+
+	// only keep the last round around, the network doesn't need to be flooded
+	n.sentProposals = keepProposals(n.sentProposals, round-1)
+	n.sentVotes = keepVotes(n.sentVotes, round-1)
+}
+
+func keepProposals(proposals []*pb.ProposeBlockArgs, round int64) []*pb.ProposeBlockArgs {
+	kept := proposals[:0]
+	for _, p := range proposals {
+		if p.Round >= round {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+func keepVotes(votes []*pb.VoteArgs, round int64) []*pb.VoteArgs {
+	kept := votes[:0]
+	for _, v := range votes {
+		if v.Round >= round {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
@@ -10,18 +10,76 @@ import (
 
 	context "golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/nyu-distributed-systems-fa18/algorand/pb"
 )
 
 func usage() {
-	fmt.Printf("Usage %s <message>\n", os.Args[0])
+	fmt.Printf("Usage %s <endpoint> [status|peers|period-state|mempool]\n", os.Args[0])
+	fmt.Printf("Without a command sends test transactions to the client endpoint, the commands query the admin endpoint.\n")
+	fmt.Printf("       %s wallet build|show|sign|submit ... builds, signs and submits transactions in separate steps.\n", os.Args[0])
 	flag.PrintDefaults()
 }
 
+// tokenCredentials attaches the admin token to every call.
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// Call the admin service and print its answer.
+func runAdmin(conn *grpc.ClientConn, command string) {
+	admin := pb.NewAdminClient(conn)
+	ctx := context.Background()
+
+	var res interface{}
+	var err error
+	switch command {
+	case "status":
+		res, err = admin.GetStatus(ctx, &pb.Empty{})
+	case "peers":
+		res, err = admin.GetPeerInfo(ctx, &pb.Empty{})
+	case "period-state":
+		res, err = admin.GetPeriodState(ctx, &pb.Empty{})
+	case "mempool":
+		res, err = admin.GetMempool(ctx, &pb.Empty{})
+	default:
+		flag.Usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatalf("%v error %v", command, err)
+	}
+
+	bytes, err := json.MarshalIndent(res, "", "    ")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Println(string(bytes))
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "wallet" {
+		runWallet(os.Args[2:])
+		return
+	}
+
 	// Take endpoint as input
 	flag.Usage = usage
+	var evidence bool
+	flag.BoolVar(&evidence, "evidence", false, "Print the equivocation evidence the server collected instead of sending transactions")
+	var finality bool
+	flag.BoolVar(&finality, "finality", false, "Print which blocks are final and which only tentative instead of sending transactions")
+	var token string
+	flag.StringVar(&token, "token", os.Getenv("ALGORAND_ADMIN_TOKEN"), "Admin token for the admin commands, defaults to $ALGORAND_ADMIN_TOKEN")
+	var ca string
+	flag.StringVar(&ca, "ca", "", "CA certificate to verify the server with, for servers that have TLS turned on")
 	flag.Parse()
 	// If there is no endpoint fail
 	if flag.NArg() < 1 {
@@ -30,17 +88,59 @@ func main() {
 	}
 	endpoint := flag.Args()[0]
 	log.Printf("Connecting to %v", endpoint)
-	// Connect to the server. We use WithInsecure since we do not configure https in this class.
-	conn, err := grpc.Dial(endpoint, grpc.WithInsecure())
+	// Connect to the server. We use WithInsecure unless the server has TLS turned on.
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+	if ca != "" {
+		creds, err := credentials.NewClientTLSFromFile(ca, "")
+		if err != nil {
+			log.Fatalf("Could not load CA certificate %v", err)
+		}
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	}
+	if token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials(token)))
+	}
+	conn, err := grpc.Dial(endpoint, opts...)
 	//Ensure connection did not fail.
 	if err != nil {
 		log.Fatalf("Failed to dial GRPC server %v", err)
 	}
 	log.Printf("Connected")
 
+	if flag.NArg() > 1 {
+		runAdmin(conn, flag.Args()[1])
+		return
+	}
+
 	// Create Algorand client
 	bcc := pb.NewBCStoreClient(conn)
 
+	if evidence {
+		res, err := bcc.GetEvidence(context.Background(), &pb.Empty{})
+		if err != nil {
+			log.Fatalf("GetEvidence error %v", err)
+		}
+		bytes, err := json.MarshalIndent(res.GetEvidence(), "", "    ")
+		if err != nil {
+			log.Println(err)
+		}
+		log.Printf(string(bytes))
+		return
+	}
+
+	if finality {
+		res, err := bcc.GetFinality(context.Background(), &pb.Empty{})
+		if err != nil {
+			log.Fatalf("GetFinality error %v", err)
+		}
+		bytes, err := json.MarshalIndent(res.GetFinality(), "", "    ")
+		if err != nil {
+			log.Println(err)
+		}
+		log.Printf(string(bytes))
+		return
+	}
+
 	// Send Transactions
 	transReq := &pb.Transaction{V: "Eric and Nick are good at blockchain"}
 
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/keystore"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+	"github.com/nyu-distributed-systems-fa18/algorand/wallet"
+)
+
+const walletUsage = `Usage: %[1]s wallet build transfer -from address -to address -amount n (-nonce n | -endpoint host:port) -out file
+       %[1]s wallet build register -from owner -user id -key hex -first round -last round (-nonce n | -endpoint host:port) -out file
+       %[1]s wallet show file
+       %[1]s wallet sign -keystore file -passphrase-file file -name key -out file file
+       %[1]s wallet submit [-ca file] [-wait duration] file endpoint
+
+build writes an unsigned transaction, sign signs it with an account key from
+a keystore and needs no network, submit sends the signed one to a node.
+The keystore passphrase comes from -passphrase-file.
+`
+
+// Build, sign and submit transactions in separate steps, see package wallet.
+func runWallet(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintf(os.Stderr, walletUsage, os.Args[0])
+		os.Exit(2)
+	}
+	switch args[0] {
+	case "build":
+		walletBuild(args[1:])
+	case "show":
+		if len(args) != 2 {
+			fmt.Fprintf(os.Stderr, walletUsage, os.Args[0])
+			os.Exit(2)
+		}
+		tx, err := wallet.Read(args[1])
+		if err != nil {
+			walletFail("show", err)
+		}
+		fmt.Println(wallet.Describe(tx))
+	case "sign":
+		walletSign(args[1:])
+	case "submit":
+		walletSubmit(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, walletUsage, os.Args[0])
+		os.Exit(2)
+	}
+}
+
+func walletFail(command string, err error) {
+	fmt.Fprintf(os.Stderr, "wallet %v: %v\n", command, err)
+	os.Exit(1)
+}
+
+func walletBuild(args []string) {
+	if len(args) < 1 || (args[0] != "transfer" && args[0] != "register") {
+		fmt.Fprintf(os.Stderr, walletUsage, os.Args[0])
+		os.Exit(2)
+	}
+	kind := args[0]
+	flags := flag.NewFlagSet("wallet build "+kind, flag.ExitOnError)
+	from := flags.String("from", "", "Sending account, the owner for registrations")
+	to := flags.String("to", "", "Receiving account")
+	amount := flags.Int64("amount", 0, "Amount to transfer")
+	user := flags.String("user", "", "User id to register the key for")
+	key := flags.String("key", "", "Hex encoded participation key to register")
+	first := flags.Int64("first", 0, "First round the registered key votes in")
+	last := flags.Int64("last", 0, "Last round the registered key votes in")
+	nonce := flags.Int64("nonce", 0, "Nonce, one more than the sender's previous transaction")
+	endpoint := flags.String("endpoint", "", "Node to look the nonce up on, instead of -nonce")
+	ca := flags.String("ca", "", "CA certificate to verify the node with")
+	note := flags.String("note", "", "Note to carry along")
+	out := flags.String("out", "", "File to write the unsigned transaction to")
+	flags.Parse(args[1:])
+	if *out == "" {
+		walletFail("build", fmt.Errorf("-out is required"))
+	}
+
+	if *nonce == 0 {
+		if *endpoint == "" {
+			walletFail("build", fmt.Errorf("-nonce or -endpoint is required"))
+		}
+		conn, err := dial(*endpoint, *ca)
+		if err != nil {
+			walletFail("build", err)
+		}
+		res, err := pb.NewBCStoreClient(conn).Get(context.Background(), &pb.Empty{})
+		conn.Close()
+		if err != nil {
+			walletFail("build", err)
+		}
+		*nonce = wallet.NextNonce(res.GetBc().GetBlocks(), *from)
+	}
+
+	var tx *pb.Transaction
+	var err error
+	if kind == "transfer" {
+		tx, err = wallet.Transfer(*from, *to, *amount, *nonce)
+	} else {
+		var pub []byte
+		pub, err = hex.DecodeString(*key)
+		if err == nil && len(pub) != ed25519.PublicKeySize {
+			err = fmt.Errorf("-key has to be %v hex encoded bytes", ed25519.PublicKeySize)
+		}
+		if err == nil {
+			tx, err = wallet.Registration(*from, *user, pub, *first, *last, *nonce)
+		}
+	}
+	if err != nil {
+		walletFail("build", err)
+	}
+	tx.V = *note
+	if err := wallet.Write(*out, tx); err != nil {
+		walletFail("build", err)
+	}
+	fmt.Println(wallet.Describe(tx))
+}
+
+func walletSign(args []string) {
+	flags := flag.NewFlagSet("wallet sign", flag.ExitOnError)
+	keystorePath := flags.String("keystore", os.Getenv("ALGORAND_KEYSTORE"), "Keystore holding the account key, defaults to $ALGORAND_KEYSTORE")
+	passphraseFile := flags.String("passphrase-file", "", "File holding the keystore passphrase")
+	name := flags.String("name", "", "Name of the account key in the keystore")
+	out := flags.String("out", "", "File to write the signed transaction to")
+	flags.Parse(args)
+	if flags.NArg() != 1 || *keystorePath == "" || *passphraseFile == "" || *name == "" || *out == "" {
+		fmt.Fprintf(os.Stderr, walletUsage, os.Args[0])
+		os.Exit(2)
+	}
+
+	tx, err := wallet.Read(flags.Arg(0))
+	if err != nil {
+		walletFail("sign", err)
+	}
+	passphrase, err := ioutil.ReadFile(*passphraseFile)
+	if err != nil {
+		walletFail("sign", err)
+	}
+	ks, err := keystore.Open(*keystorePath, bytes.TrimRight(passphrase, "\r\n"))
+	if err != nil {
+		walletFail("sign", err)
+	}
+	var found bool
+	for _, e := range ks.Entries(keystore.Account) {
+		found = found || e.Name == *name
+	}
+	if !found {
+		walletFail("sign", fmt.Errorf("no account key %v in %v", *name, *keystorePath))
+	}
+	seed, err := ks.Secret(*name)
+	if err != nil {
+		walletFail("sign", err)
+	}
+	if err := wallet.Sign(tx, ed25519.NewKeyFromSeed(seed)); err != nil {
+		walletFail("sign", err)
+	}
+	if err := wallet.Write(*out, tx); err != nil {
+		walletFail("sign", err)
+	}
+	fmt.Println(wallet.Describe(tx))
+}
+
+func walletSubmit(args []string) {
+	flags := flag.NewFlagSet("wallet submit", flag.ExitOnError)
+	ca := flags.String("ca", "", "CA certificate to verify the node with")
+	wait := flags.Duration("wait", 0, "How long to wait for the transaction to land in a block, 0 not to")
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, walletUsage, os.Args[0])
+		os.Exit(2)
+	}
+
+	tx, err := wallet.Read(flags.Arg(0))
+	if err != nil {
+		walletFail("submit", err)
+	}
+	conn, err := dial(flags.Arg(1), *ca)
+	if err != nil {
+		walletFail("submit", err)
+	}
+	defer conn.Close()
+	client := pb.NewBCStoreClient(conn)
+	if _, err := wallet.Submit(context.Background(), client, tx); err != nil {
+		walletFail("submit", err)
+	}
+	fmt.Printf("Submitted: %v\n", wallet.Describe(tx))
+	if *wait <= 0 {
+		return
+	}
+
+	for deadline := time.Now().Add(*wait); time.Now().Before(deadline); time.Sleep(time.Second) {
+		res, err := client.Get(context.Background(), &pb.Empty{})
+		if err != nil {
+			walletFail("submit", err)
+		}
+		if round := wallet.Included(res.GetBc().GetBlocks(), tx); round > 0 {
+			fmt.Printf("Included in the block of round %v\n", round)
+			return
+		}
+	}
+	walletFail("submit", fmt.Errorf("not in a block after %v, the node may have refused it", *wait))
+}
+
+// dial connects to a node's client endpoint, over TLS if ca is set.
+func dial(endpoint, ca string) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+	if ca != "" {
+		creds, err := credentials.NewClientTLSFromFile(ca, "")
+		if err != nil {
+			return nil, err
+		}
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	}
+	return grpc.Dial(endpoint, opts...)
+}
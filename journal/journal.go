@@ -0,0 +1,222 @@
+// Package journal records everything an agreement machine is fed, and what it
+// decided, to a compact binary file. Because the machine does no I/O of its
+// own, feeding the same inputs to a fresh machine reproduces every decision,
+// see Replay.
+//
+// A journal file is a magic string followed by records, each a uvarint length
+// and a pb.JournalRecord. The first record holds the machine's configuration.
+package journal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+const magic = "ALGJ1\n"
+
+var ErrNotJournal = errors.New("not a journal file")
+
+// Writer implements agreement.Journal. The first write error is kept and
+// reported by Err and Close, the journal is best effort and never stops agreement.
+type Writer struct {
+	w      *bufio.Writer
+	closer io.Closer
+	err    error
+}
+
+// Create truncates path and starts a journal in it.
+func Create(path string) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := NewWriter(f)
+	w.closer = f
+	return w, w.err
+}
+
+func NewWriter(out io.Writer) *Writer {
+	w := &Writer{w: bufio.NewWriter(out)}
+	_, w.err = w.w.WriteString(magic)
+	return w
+}
+
+func (w *Writer) Err() error {
+	return w.err
+}
+
+func (w *Writer) Close() error {
+	if err := w.w.Flush(); w.err == nil {
+		w.err = err
+	}
+	if w.closer != nil {
+		if err := w.closer.Close(); w.err == nil {
+			w.err = err
+		}
+	}
+	return w.err
+}
+
+func (w *Writer) Started(at time.Time, cfg agreement.Config, actions []agreement.Action) {
+	w.write(&pb.JournalRecord{
+		Time: at.UnixNano(),
+		Event: &pb.JournalRecord_Start{Start: &pb.JournalConfig{
+			UserId:         cfg.UserId,
+			Candidates:     cfg.Candidates,
+			K:              cfg.Params.K,
+			RequiredVotes:  cfg.RequiredVotes,
+			Lambda:         int64(cfg.Params.Lambda),
+			BigLambda:      int64(cfg.Params.BigLambda),
+			RoundInterval:  int64(cfg.Params.RoundInterval),
+			FutureRounds:   cfg.FutureRounds,
+			FutureMessages: int64(cfg.FutureMessages),
+			VrfKeys:        journalKeys(cfg.VRFKeys),
+			Seed:           cfg.Seed,
+		}},
+		Actions: Describe(actions),
+	})
+}
+
+// journalKeys lists keys by user, in order so that the same configuration
+// always journals the same.
+func journalKeys(keys map[string]ed25519.PublicKey) []*pb.JournalKey {
+	users := make([]string, 0, len(keys))
+	for user := range keys {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	journaled := make([]*pb.JournalKey, len(users))
+	for i, user := range users {
+		journaled[i] = &pb.JournalKey{UserId: user, Key: hex.EncodeToString(keys[user])}
+	}
+	return journaled
+}
+
+func (w *Writer) Handled(at time.Time, ev agreement.Event, actions []agreement.Action, err error, round, period, step int64) {
+	rec := &pb.JournalRecord{
+		Time:    at.UnixNano(),
+		Actions: Describe(actions),
+		Round:   round,
+		Period:  period,
+		Step:    step,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	switch e := ev.(type) {
+	case agreement.Timeout:
+		rec.Event = &pb.JournalRecord_Timeout{Timeout: &pb.JournalTimeout{Timer: int64(e.Timer)}}
+	case agreement.ProposalReceived:
+		rec.Event = &pb.JournalRecord_Proposal{Proposal: e.Proposal}
+	case agreement.PriorityReceived:
+		rec.Event = &pb.JournalRecord_Priority{Priority: e.Priority}
+	case agreement.VoteReceived:
+		rec.Event = &pb.JournalRecord_Vote{Vote: e.Vote}
+	case agreement.BlockAssembled:
+		rec.Event = &pb.JournalRecord_Assembled{Assembled: &pb.JournalBlockAssembled{Round: e.Round, Period: e.Period, Block: e.Block, Value: e.Value, Proof: hex.EncodeToString(e.Proof)}}
+	case agreement.Synced:
+		rec.Event = &pb.JournalRecord_Synced{Synced: &pb.JournalSynced{Round: e.Round, Seed: e.Seed}}
+	case agreement.BlockFetched:
+		rec.Event = &pb.JournalRecord_Fetched{Fetched: &pb.JournalBlockFetched{Round: e.Round, Value: e.Value, Block: e.Block}}
+	default:
+		// new event types have to be added here to be replayable
+		if w.err == nil {
+			w.err = fmt.Errorf("can't journal event %T", ev)
+		}
+		return
+	}
+	w.write(rec)
+}
+
+func (w *Writer) write(rec *pb.JournalRecord) {
+	if w.err != nil {
+		return
+	}
+	data, err := proto.Marshal(rec)
+	if err != nil {
+		w.err = err
+		return
+	}
+
+	var length [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(length[:], uint64(len(data)))
+	if _, w.err = w.w.Write(length[:n]); w.err != nil {
+		return
+	}
+	if _, w.err = w.w.Write(data); w.err != nil {
+		return
+	}
+	// a journal is most useful right after something went wrong, don't sit on records
+	w.err = w.w.Flush()
+}
+
+// Reader reads the records of a journal in order.
+type Reader struct {
+	r      *bufio.Reader
+	closer io.Closer
+}
+
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.closer = f
+	return r, nil
+}
+
+func NewReader(in io.Reader) (*Reader, error) {
+	r := &Reader{r: bufio.NewReader(in)}
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(r.r, header); err != nil || string(header) != magic {
+		return nil, ErrNotJournal
+	}
+	return r, nil
+}
+
+// Next returns the next record, io.EOF after the last one. A record cut short
+// by a crash reads as io.ErrUnexpectedEOF.
+func (r *Reader) Next() (*pb.JournalRecord, error) {
+	length, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	rec := &pb.JournalRecord{}
+	if err := proto.Unmarshal(data, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+func (r *Reader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package journal
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+func TestMain(m *testing.M) {
+	logging.SetOutput(ioutil.Discard)
+	logging.SetLevel(logging.PanicLevel)
+	os.Exit(m.Run())
+}
+
+func vote(user, value, voteType string, period, step int64) agreement.Event {
+	message := []string{value, voteType, strconv.FormatInt(period, 10), strconv.FormatInt(step, 10), "1"}
+	return agreement.VoteReceived{Vote: &pb.VoteArgs{Message: agreement.SIG(user, message), Round: 1, Peer: user}}
+}
+
+// record runs a machine through round 1, which settles on the empty block,
+// and journals it the way agreement.Service does.
+func record(t *testing.T) []byte {
+	users := []string{"a", "b", "c", "d"}
+	keys := make(map[string]ed25519.PublicKey)
+	for i, user := range users {
+		keys[user] = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{byte(i + 1)}, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+	}
+	m := agreement.NewMachine(agreement.Config{
+		UserId:         "a",
+		Candidates:     users,
+		VRFKeys:        keys,
+		Seed:           "genesis",
+		RequiredVotes:  3,
+		Params:         agreement.DefaultParams,
+		FutureRounds:   agreement.DefaultFutureRounds,
+		FutureMessages: agreement.DefaultFutureMessages,
+	})
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	at := time.Unix(1, 0)
+	w.Started(at, m.Config(), m.Start())
+	for _, ev := range []agreement.Event{
+		agreement.Timeout{Timer: agreement.RoundTimer},
+		agreement.Timeout{Timer: agreement.StepTimer},
+		vote("b", agreement.Empty, "soft", 1, 2),
+		vote("b", agreement.Empty, "soft", 1, 2),
+		vote("c", agreement.Empty, "soft", 1, 2),
+		agreement.Timeout{Timer: agreement.StepTimer},
+		vote("b", agreement.Empty, "cert", 1, 3),
+		vote("c", agreement.Empty, "cert", 1, 3),
+		agreement.Synced{Round: 5, Seed: "synced"},
+		agreement.Timeout{Timer: agreement.RoundTimer},
+	} {
+		at = at.Add(time.Second)
+		actions, err := m.Handle(ev)
+		w.Handled(at, ev, actions, err, m.Round(), m.Period(), m.Step())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func readAll(t *testing.T, journal []byte) []*pb.JournalRecord {
+	r, err := NewReader(bytes.NewReader(journal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []*pb.JournalRecord
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, rec)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	journal := record(t)
+	records := readAll(t, journal)
+	if len(records) != 11 {
+		t.Fatalf("read %v records, wrote 11", len(records))
+	}
+	if err := records[4].Error; err != agreement.ErrAlreadyVoted.Error() {
+		t.Errorf("the repeated vote was journaled with error %q", err)
+	}
+	if !contains(records[8].Actions, "commit round 1 period 1 value empty") {
+		t.Errorf("the commit was journaled as %q", records[8].Actions)
+	}
+
+	r, err := NewReader(bytes.NewReader(journal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	visited := 0
+	step, err := Replay(r, func(*Step) { visited++ })
+	if step != nil || err != nil {
+		t.Fatalf("replay diverged at %+v, %v", step, err)
+	}
+	if visited != len(records) {
+		t.Errorf("replay visited %v records of %v", visited, len(records))
+	}
+}
+
+// A journal whose machine decided differently than a fresh one does stops
+// the replay at that step.
+func TestReplayDiverges(t *testing.T) {
+	journal := record(t)
+	tests := []struct {
+		name   string
+		index  int
+		change func(*pb.JournalRecord)
+	}{
+		{"action", 8, func(rec *pb.JournalRecord) { rec.Actions[0] = "commit round 1 period 1 value v1" }},
+		{"missing action", 2, func(rec *pb.JournalRecord) { rec.Actions = rec.Actions[1:] }},
+		{"error", 4, func(rec *pb.JournalRecord) { rec.Error = "" }},
+		{"period", 9, func(rec *pb.JournalRecord) { rec.Period = 2 }},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		w := NewWriter(&buf)
+		for i, rec := range readAll(t, journal) {
+			if i == test.index {
+				test.change(rec)
+			}
+			w.write(rec)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := NewReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		step, err := Replay(r, nil)
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		if step == nil || step.Index != test.index || !step.Diverged() {
+			t.Errorf("%v: diverged at %+v, want record %v", test.name, step, test.index)
+		}
+	}
+}
+
+// A record cut short, as by a crash, reads as io.ErrUnexpectedEOF.
+func TestTruncated(t *testing.T) {
+	journal := record(t)
+	records := readAll(t, journal)
+
+	r, err := NewReader(bytes.NewReader(journal[:len(journal)-1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range records {
+		rec, err := r.Next()
+		if i < len(records)-1 {
+			if err != nil {
+				t.Fatalf("record %v: %v", i, err)
+			}
+			continue
+		}
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("last record: got %v, %v, want %v", rec, err, io.ErrUnexpectedEOF)
+		}
+	}
+
+	r, err = NewReader(bytes.NewReader(journal[:len(journal)-1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Replay(r, nil); err == nil {
+		t.Errorf("replayed a truncated journal")
+	}
+
+	if _, err := NewReader(bytes.NewReader(journal[:len(magic)-1])); err != ErrNotJournal {
+		t.Errorf("cut into the header: got %v, want %v", err, ErrNotJournal)
+	}
+}
+
+func contains(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
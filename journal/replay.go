@@ -0,0 +1,201 @@
+package journal
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+// Describe turns actions into the lines a journal stores and Replay compares.
+func Describe(actions []agreement.Action) []string {
+	lines := []string{}
+	for _, action := range actions {
+		switch a := action.(type) {
+		case agreement.SetTimer:
+			lines = append(lines, fmt.Sprintf("set %v timer %v", a.Timer, a.Duration))
+		case agreement.BroadcastProposal:
+			lines = append(lines, fmt.Sprintf("propose %v round %v", a.Proposal.Value, a.Proposal.Round))
+		case agreement.BroadcastPriority:
+			lines = append(lines, fmt.Sprintf("priority %v for %v period %v", a.Priority.Priority.Priority, a.Priority.Priority.Value, a.Priority.Priority.Period))
+		case agreement.BroadcastVote:
+			lines = append(lines, fmt.Sprintf("vote %v", strings.Join(a.Vote.Message.Message, " ")))
+		case agreement.AssembleBlock:
+			lines = append(lines, fmt.Sprintf("assemble round %v period %v", a.Round, a.Period))
+		case agreement.Commit:
+			lines = append(lines, fmt.Sprintf("commit round %v period %v value %v", a.Round, a.Period, a.Value))
+		case agreement.FetchBlock:
+			lines = append(lines, fmt.Sprintf("fetch block %v round %v period %v from %v", a.Value, a.Round, a.Period, strings.Join(a.Voters, ",")))
+		case agreement.CheckProposal:
+			lines = append(lines, fmt.Sprintf("check proposal %v round %v", a.Proposal.Value, a.Proposal.Round))
+		case agreement.RequestSync:
+			lines = append(lines, "request sync")
+		case agreement.Equivocation:
+			lines = append(lines, fmt.Sprintf("equivocation by %v period %v step %v", a.Evidence.UserId, a.Evidence.Period, a.Evidence.Step))
+		default:
+			lines = append(lines, fmt.Sprintf("%T", action))
+		}
+	}
+	return lines
+}
+
+// DescribeEvent is a one line summary of the input in rec.
+func DescribeEvent(rec *pb.JournalRecord) string {
+	switch e := rec.Event.(type) {
+	case *pb.JournalRecord_Start:
+		return fmt.Sprintf("start as %v", e.Start.UserId)
+	case *pb.JournalRecord_Timeout:
+		return fmt.Sprintf("%v timeout", agreement.TimerKind(e.Timeout.Timer))
+	case *pb.JournalRecord_Proposal:
+		return fmt.Sprintf("proposal %v round %v from %v", e.Proposal.Value, e.Proposal.Round, e.Proposal.Credential.GetUserId())
+	case *pb.JournalRecord_Priority:
+		p := e.Priority.GetPriority()
+		return fmt.Sprintf("priority %v for %v round %v period %v from %v", p.GetPriority(), p.GetValue(), p.GetRound(), p.GetPeriod(), p.GetUserId())
+	case *pb.JournalRecord_Vote:
+		return fmt.Sprintf("vote %v from %v", strings.Join(e.Vote.Message.GetMessage(), " "), e.Vote.Message.GetUserId())
+	case *pb.JournalRecord_Assembled:
+		return fmt.Sprintf("assembled %v round %v period %v", e.Assembled.Value, e.Assembled.Round, e.Assembled.Period)
+	case *pb.JournalRecord_Synced:
+		return fmt.Sprintf("synced to round %v", e.Synced.Round)
+	case *pb.JournalRecord_Fetched:
+		return fmt.Sprintf("fetched %v round %v", e.Fetched.Value, e.Fetched.Round)
+	}
+	return "unknown"
+}
+
+func vrfKeys(journaled []*pb.JournalKey) (map[string]ed25519.PublicKey, error) {
+	keys := make(map[string]ed25519.PublicKey, len(journaled))
+	for _, k := range journaled {
+		key, err := hex.DecodeString(k.Key)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("bad VRF key for %v", k.UserId)
+		}
+		keys[k.UserId] = key
+	}
+	return keys, nil
+}
+
+func toEvent(rec *pb.JournalRecord) (agreement.Event, error) {
+	switch e := rec.Event.(type) {
+	case *pb.JournalRecord_Timeout:
+		return agreement.Timeout{Timer: agreement.TimerKind(e.Timeout.Timer)}, nil
+	case *pb.JournalRecord_Proposal:
+		return agreement.ProposalReceived{Proposal: e.Proposal}, nil
+	case *pb.JournalRecord_Priority:
+		return agreement.PriorityReceived{Priority: e.Priority}, nil
+	case *pb.JournalRecord_Vote:
+		return agreement.VoteReceived{Vote: e.Vote}, nil
+	case *pb.JournalRecord_Assembled:
+		proof, err := hex.DecodeString(e.Assembled.Proof)
+		if err != nil {
+			return nil, fmt.Errorf("assembled block has a bad proof: %v", err)
+		}
+		return agreement.BlockAssembled{Round: e.Assembled.Round, Period: e.Assembled.Period, Block: e.Assembled.Block, Value: e.Assembled.Value, Proof: proof}, nil
+	case *pb.JournalRecord_Synced:
+		return agreement.Synced{Round: e.Synced.Round, Seed: e.Synced.Seed}, nil
+	case *pb.JournalRecord_Fetched:
+		return agreement.BlockFetched{Round: e.Fetched.Round, Value: e.Fetched.Value, Block: e.Fetched.Block}, nil
+	}
+	return nil, fmt.Errorf("record has no replayable event")
+}
+
+// Step is one replayed record: what the journal says happened and what the
+// replayed machine did.
+type Step struct {
+	Index  int
+	Time   time.Time
+	Record *pb.JournalRecord
+
+	Actions []string
+	Error   string
+	Round   int64
+	Period  int64
+	Step    int64
+}
+
+// Diverged reports whether the replayed machine decided differently than the recorded one.
+func (s *Step) Diverged() bool {
+	rec := s.Record
+	if !reflect.DeepEqual(s.Actions, nonNil(rec.Actions)) || s.Error != rec.Error {
+		return true
+	}
+	_, start := rec.Event.(*pb.JournalRecord_Start)
+	return !start && (s.Round != rec.Round || s.Period != rec.Period || s.Step != rec.Step)
+}
+
+func nonNil(lines []string) []string {
+	if lines == nil {
+		return []string{}
+	}
+	return lines
+}
+
+// Replay feeds the journal through a fresh agreement machine, calling visit
+// with every step. It stops at the first step that diverges and returns it.
+// It returns nil if every decision was reproduced.
+func Replay(r *Reader, visit func(*Step)) (*Step, error) {
+	var m *agreement.Machine
+
+	for i := 0; ; i++ {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("record %v: %v", i, err)
+		}
+
+		step := &Step{Index: i, Time: time.Unix(0, rec.Time), Record: rec}
+
+		if start, ok := rec.Event.(*pb.JournalRecord_Start); ok {
+			keys, err := vrfKeys(start.Start.VrfKeys)
+			if err != nil {
+				return nil, fmt.Errorf("record %v: %v", i, err)
+			}
+			m = agreement.NewMachine(agreement.Config{
+				UserId:        start.Start.UserId,
+				Candidates:    start.Start.Candidates,
+				VRFKeys:       keys,
+				Seed:          start.Start.Seed,
+				RequiredVotes: start.Start.RequiredVotes,
+				Params: agreement.Params{
+					Lambda:        time.Duration(start.Start.Lambda),
+					BigLambda:     time.Duration(start.Start.BigLambda),
+					RoundInterval: time.Duration(start.Start.RoundInterval),
+					K:             start.Start.K,
+				},
+				FutureRounds:   start.Start.FutureRounds,
+				FutureMessages: int(start.Start.FutureMessages),
+			})
+			step.Actions = Describe(m.Start())
+		} else {
+			if m == nil {
+				return nil, fmt.Errorf("record %v: journal doesn't start with the configuration", i)
+			}
+			ev, err := toEvent(rec)
+			if err != nil {
+				return nil, fmt.Errorf("record %v: %v", i, err)
+			}
+			actions, err := m.Handle(ev)
+			step.Actions = Describe(actions)
+			if err != nil {
+				step.Error = err.Error()
+			}
+		}
+		step.Round, step.Period, step.Step = m.Round(), m.Period(), m.Step()
+
+		if visit != nil {
+			visit(step)
+		}
+		if step.Diverged() {
+			return step, nil
+		}
+	}
+}
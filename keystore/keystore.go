@@ -0,0 +1,335 @@
+// Package keystore keeps a node's and an operator's private keys in one
+// file, encrypted with a key stretched from a passphrase by scrypt. Every
+// entry is sealed with AES-256-GCM on its own, together with its name, kind
+// and public key, so entries can't be swapped or relabelled unnoticed.
+// What an entry's public key is can be read without the passphrase.
+//
+// A keystore holds account keys, which sign transactions, participation
+// keys, which sign votes, and VRF keys, which prove sortition. Only one
+// process should have a keystore open for writing at a time: a node
+// rewrites the entries of its participation keys every round.
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+// The kinds of keys a keystore holds.
+const (
+	Account       = "account"
+	Participation = "participation"
+	VRF           = "vrf"
+)
+
+// scrypt parameters for new keystores, about 100ms and 32MB to open one
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var (
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupt keystore")
+	ErrNotFound        = errors.New("no such key in the keystore")
+	ErrExists          = errors.New("a key with that name is already in the keystore")
+)
+
+// Entry is what anyone may know about a key in a keystore.
+type Entry struct {
+	Name   string
+	Kind   string
+	Public ed25519.PublicKey
+	// rounds of participation keys
+	First, Last int64
+}
+
+// Keystore is an open keystore, it writes every change through to its file.
+type Keystore struct {
+	path string
+	aead cipher.AEAD
+	file *pb.Keystore
+}
+
+// Create makes a new empty keystore at path, there must be no file yet.
+func Create(path string, passphrase []byte) (*Keystore, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("%v already exists", path)
+	}
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	file := &pb.Keystore{ScryptN: scryptN, ScryptR: scryptR, ScryptP: scryptP, Salt: hex.EncodeToString(salt)}
+	aead, err := deriveKey(file, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	ks := &Keystore{path: path, aead: aead, file: file}
+	if ks.file.Check, err = ks.seal(nil, checkData()); err != nil {
+		return nil, err
+	}
+	return ks, ks.save()
+}
+
+// Open opens the keystore at path, it fails unless passphrase is the one
+// it was created with.
+func Open(path string, passphrase []byte) (*Keystore, error) {
+	file, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := deriveKey(file, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	ks := &Keystore{path: path, aead: aead, file: file}
+	if _, err := ks.open(file.Check, checkData()); err != nil {
+		return nil, err
+	}
+	for _, e := range file.Entries {
+		if _, err := publicEntry(e); err != nil {
+			return nil, fmt.Errorf("%v: %v", path, err)
+		}
+	}
+	return ks, nil
+}
+
+// List returns what is in the keystore at path without opening it.
+func List(path string) ([]Entry, error) {
+	file, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, e := range file.Entries {
+		entry, err := publicEntry(e)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", path, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Entries returns the keys of kind, all of them for "".
+func (ks *Keystore) Entries(kind string) []Entry {
+	var entries []Entry
+	for _, e := range ks.file.Entries {
+		if kind != "" && e.Kind != kind {
+			continue
+		}
+		// checked when the keystore was opened or the entry added
+		entry, _ := publicEntry(e)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Add seals secret, the private part of the key entry describes, into
+// the keystore.
+func (ks *Keystore) Add(entry Entry, secret []byte) error {
+	if entry.Name == "" {
+		return errors.New("keys need a name")
+	}
+	if entry.Kind != Account && entry.Kind != Participation && entry.Kind != VRF {
+		return fmt.Errorf("unknown kind of key %q", entry.Kind)
+	}
+	if len(entry.Public) != ed25519.PublicKeySize {
+		return errors.New("invalid ed25519 public key")
+	}
+	if ks.find(entry.Name) != nil {
+		return ErrExists
+	}
+	e := &pb.KeystoreEntry{
+		Name:       entry.Name,
+		Kind:       entry.Kind,
+		Public:     hex.EncodeToString(entry.Public),
+		FirstRound: entry.First,
+		LastRound:  entry.Last,
+	}
+	sealed, err := ks.seal(secret, entryData(e))
+	if err != nil {
+		return err
+	}
+	e.Sealed = sealed
+	ks.file.Entries = append(ks.file.Entries, e)
+	return ks.save()
+}
+
+// Secret returns the private part of key name.
+func (ks *Keystore) Secret(name string) ([]byte, error) {
+	e := ks.find(name)
+	if e == nil {
+		return nil, ErrNotFound
+	}
+	return ks.open(e.Sealed, entryData(e))
+}
+
+// Update replaces the private part of key name, participation keys shrink
+// as their rounds pass.
+func (ks *Keystore) Update(name string, secret []byte) error {
+	e := ks.find(name)
+	if e == nil {
+		return ErrNotFound
+	}
+	sealed, err := ks.seal(secret, entryData(e))
+	if err != nil {
+		return err
+	}
+	e.Sealed = sealed
+	return ks.save()
+}
+
+// Delete removes key name from the keystore.
+func (ks *Keystore) Delete(name string) error {
+	for i, e := range ks.file.Entries {
+		if e.Name == name {
+			ks.file.Entries = append(ks.file.Entries[:i], ks.file.Entries[i+1:]...)
+			return ks.save()
+		}
+	}
+	return ErrNotFound
+}
+
+// Slot is where the private part of one key is kept, it satisfies
+// participation.Store.
+type Slot struct {
+	ks   *Keystore
+	name string
+}
+
+func (ks *Keystore) Slot(name string) *Slot {
+	return &Slot{ks: ks, name: name}
+}
+
+func (s *Slot) Save(secret []byte) error {
+	return s.ks.Update(s.name, secret)
+}
+
+func (s *Slot) Remove() error {
+	return s.ks.Delete(s.name)
+}
+
+func (ks *Keystore) find(name string) *pb.KeystoreEntry {
+	for _, e := range ks.file.Entries {
+		if e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// seal encrypts plaintext with a fresh nonce, data is authenticated along.
+func (ks *Keystore) seal(plaintext, data []byte) (string, error) {
+	nonce := make([]byte, ks.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(ks.aead.Seal(nonce, nonce, plaintext, data)), nil
+}
+
+func (ks *Keystore) open(sealed string, data []byte) ([]byte, error) {
+	b, err := hex.DecodeString(sealed)
+	if err != nil || len(b) < ks.aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plaintext, err := ks.aead.Open(nil, b[:ks.aead.NonceSize()], b[ks.aead.NonceSize():], data)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// save replaces the keystore's file, a crash leaves either the old or the
+// new one whole.
+func (ks *Keystore) save() error {
+	b, err := proto.Marshal(ks.file)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(ks.path), filepath.Base(ks.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), ks.path)
+}
+
+func read(path string) (*pb.Keystore, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file := &pb.Keystore{}
+	if err := proto.Unmarshal(b, file); err != nil {
+		return nil, fmt.Errorf("%v: isn't a keystore: %v", path, err)
+	}
+	return file, nil
+}
+
+func deriveKey(file *pb.Keystore, passphrase []byte) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(file.Salt)
+	// refuse parameters that would take forever or all memory to open
+	if err != nil || len(salt) == 0 || file.ScryptN < 2 || file.ScryptN > 1<<20 || file.ScryptR < 1 || file.ScryptR > 32 || file.ScryptP < 1 || file.ScryptP > 16 {
+		return nil, errors.New("invalid keystore parameters")
+	}
+	key, err := scrypt.Key(passphrase, salt, int(file.ScryptN), int(file.ScryptR), int(file.ScryptP), 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func publicEntry(e *pb.KeystoreEntry) (Entry, error) {
+	pub, err := hex.DecodeString(e.Public)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return Entry{}, fmt.Errorf("key %v has an invalid public key", e.Name)
+	}
+	return Entry{Name: e.Name, Kind: e.Kind, Public: pub, First: e.FirstRound, Last: e.LastRound}, nil
+}
+
+func checkData() []byte {
+	return []byte("algorand keystore")
+}
+
+// entryData is what an entry's secret is sealed together with, each field
+// prefixed with its length.
+func entryData(e *pb.KeystoreEntry) []byte {
+	var b []byte
+	for _, field := range []string{e.Name, e.Kind, e.Public, strconv.FormatInt(e.FirstRound, 10), strconv.FormatInt(e.LastRound, 10)} {
+		b = strconv.AppendInt(b, int64(len(field)), 10)
+		b = append(b, ':')
+		b = append(b, field...)
+	}
+	return b
+}
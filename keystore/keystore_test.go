@@ -0,0 +1,188 @@
+package keystore
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+var (
+	passphrase = []byte("correct horse battery staple")
+	aliceKey   = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+	aliceVRF   = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{2}, ed25519.SeedSize))
+)
+
+// create makes a keystore at path with the account key "alice" and the VRF
+// key "alice-vrf".
+func create(t *testing.T, path string) {
+	ks, err := Create(path, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Add(Entry{Name: "alice", Kind: Account, Public: aliceKey.Public().(ed25519.PublicKey)}, aliceKey.Seed()); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Add(Entry{Name: "alice-vrf", Kind: VRF, Public: aliceVRF.Public().(ed25519.PublicKey)}, aliceVRF.Seed()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// rewrite changes the keystore file at path behind its back.
+func rewrite(t *testing.T, path string, change func(*pb.Keystore)) {
+	file, err := read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change(file)
+	b, err := proto.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keys")
+	create(t, path)
+
+	if _, err := Create(path, passphrase); err == nil {
+		t.Errorf("created a keystore over an existing one")
+	}
+	ks, err := Open(path, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, key := range map[string]ed25519.PrivateKey{"alice": aliceKey, "alice-vrf": aliceVRF} {
+		secret, err := ks.Secret(name)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if !bytes.Equal(secret, key.Seed()) {
+			t.Errorf("%v: got a different secret back", name)
+		}
+	}
+	if _, err := ks.Secret("bob"); err != ErrNotFound {
+		t.Errorf("bob: got %v, want %v", err, ErrNotFound)
+	}
+	if err := ks.Add(Entry{Name: "alice", Kind: Account, Public: aliceVRF.Public().(ed25519.PublicKey)}, nil); err != ErrExists {
+		t.Errorf("adding alice twice: got %v, want %v", err, ErrExists)
+	}
+
+	entries, err := List(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[1].Kind != VRF || !bytes.Equal(entries[1].Public, aliceVRF.Public().(ed25519.PublicKey)) {
+		t.Errorf("listed %v", entries)
+	}
+	if vrfs := ks.Entries(VRF); len(vrfs) != 1 || vrfs[0].Name != "alice-vrf" {
+		t.Errorf("VRF entries %v", vrfs)
+	}
+
+	if err := ks.Update("alice", []byte("shrunk")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Delete("alice-vrf"); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := Open(path, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret, err := reopened.Secret("alice"); err != nil || string(secret) != "shrunk" {
+		t.Errorf("alice after the update: %q, %v", secret, err)
+	}
+	if _, err := reopened.Secret("alice-vrf"); err != ErrNotFound {
+		t.Errorf("alice-vrf after deleting it: got %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestWrongPassphrase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keys")
+	create(t, path)
+
+	for _, wrong := range [][]byte{[]byte("correct horse battery stapler"), nil} {
+		if _, err := Open(path, wrong); err != ErrWrongPassphrase {
+			t.Errorf("%q: got %v, want %v", wrong, err, ErrWrongPassphrase)
+		}
+	}
+}
+
+// Entries are sealed together with what describes them, moving a secret
+// to another entry or relabelling one leaves it unreadable.
+func TestTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*pb.Keystore)
+		// keys that no longer open, the rest still do
+		broken []string
+	}{
+		{"swapped secrets", func(f *pb.Keystore) {
+			f.Entries[0].Sealed, f.Entries[1].Sealed = f.Entries[1].Sealed, f.Entries[0].Sealed
+		}, []string{"alice", "alice-vrf"}},
+		{"swapped names", func(f *pb.Keystore) {
+			f.Entries[0].Name, f.Entries[1].Name = f.Entries[1].Name, f.Entries[0].Name
+		}, []string{"alice", "alice-vrf"}},
+		{"relabelled kind", func(f *pb.Keystore) {
+			f.Entries[0].Kind = Participation
+		}, []string{"alice"}},
+		{"replaced public key", func(f *pb.Keystore) {
+			f.Entries[0].Public = f.Entries[1].Public
+		}, []string{"alice"}},
+		{"altered secret", func(f *pb.Keystore) {
+			b := []byte(f.Entries[0].Sealed)
+			if b[len(b)-1] == '0' {
+				b[len(b)-1] = '1'
+			} else {
+				b[len(b)-1] = '0'
+			}
+			f.Entries[0].Sealed = string(b)
+		}, []string{"alice"}},
+	}
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for i, test := range tests {
+		path := filepath.Join(dir, strconv.Itoa(i))
+		create(t, path)
+		rewrite(t, path, test.change)
+		ks, err := Open(path, passphrase)
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		for _, name := range []string{"alice", "alice-vrf"} {
+			want := error(nil)
+			for _, broken := range test.broken {
+				if name == broken {
+					want = ErrWrongPassphrase
+				}
+			}
+			if _, err := ks.Secret(name); err != want {
+				t.Errorf("%v: %v: got %v, want %v", test.name, name, err, want)
+			}
+		}
+	}
+}
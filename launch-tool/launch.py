@@ -52,11 +52,10 @@ def boot_pod(v1, pod_spec, service_spec, name, peers):
     pod_spec['spec']['containers'][0]['ports'][0]['name']="%s-client"%name
     pod_spec['spec']['containers'][0]['ports'][1]['name']="%s-algorand"%name
     peers = filter(lambda p: p != name, peers)
-    args = ['server']
-    for peer in peers:
-        args.append('-peer')
-        args.append('%s:3001'%peer)
-    pod_spec['spec']['containers'][0]['command'] = args
+    env = pod_spec['spec']['containers'][0]['env']
+    for var in env:
+        if var['name'] == 'ALGORAND_PEERS':
+            var['value'] = ','.join(map(lambda p: '%s:3001'%p, peers))
 
     service_spec = copy.deepcopy(service_spec)
     # Create a service spec for this service
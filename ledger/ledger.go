@@ -0,0 +1,297 @@
+// Package ledger holds the account balances a chain adds up to and decides
+// which transactions may move them.
+//
+// A transaction is either a plain record, which only carries V and moves no
+// funds, or a transfer of Amount from one account to another. Accounts are
+// named by their hex encoded ed25519 public key and every transfer has to be
+// signed by the key of the account it spends from, over SigningBytes. Funds
+// only enter the ledger through the allocations in the genesis block.
+//
+// Accounts hold the long lived keys that move funds. Voting uses separate
+// participation keys: the account that owns a user registers the key the
+// user votes with for a range of rounds, and can register the next one before
+// that range runs out. The genesis block names every user's owner and first
+// key. A leaked participation key only lets its thief vote until its last
+// round, and never moves funds.
+package ledger
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+var (
+	ErrBadAddress        = errors.New("not an account address")
+	ErrBadAmount         = errors.New("amount has to be positive")
+	ErrBadSignature      = errors.New("signature doesn't match the sending account")
+	ErrBadNonce          = errors.New("nonce doesn't follow the account's previous transfer")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrAllocation        = errors.New("funds can only be allocated in the genesis block")
+	ErrBadParticipation  = errors.New("invalid participation key registration")
+	ErrNotOwner          = errors.New("account doesn't own the user")
+)
+
+// Address names the account of pub.
+func Address(pub ed25519.PublicKey) string {
+	return hex.EncodeToString(pub)
+}
+
+// publicKey parses an address back into the key that signs for it.
+func publicKey(address string) (ed25519.PublicKey, error) {
+	b, err := hex.DecodeString(address)
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%v: %.16q", ErrBadAddress, address)
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+// IsRecord tells a plain record from a transfer or allocation.
+func IsRecord(tx *pb.Transaction) bool {
+	return tx.From == "" && tx.To == "" && tx.Amount == 0 && tx.Nonce == 0 && tx.Signature == "" && tx.Participation == nil
+}
+
+// SigningBytes is what the sender signs: every field but the signature, each
+// prefixed with its length so no two transactions share an encoding.
+func SigningBytes(tx *pb.Transaction) []byte {
+	fields := []string{tx.From, tx.To, strconv.FormatInt(tx.Amount, 10), strconv.FormatInt(tx.Nonce, 10), tx.V}
+	if p := tx.Participation; p != nil {
+		fields = append(fields, p.UserId, p.Key, strconv.FormatInt(p.FirstRound, 10), strconv.FormatInt(p.LastRound, 10))
+	}
+
+	var b []byte
+	for _, field := range fields {
+		b = strconv.AppendInt(b, int64(len(field)), 10)
+		b = append(b, ':')
+		b = append(b, field...)
+	}
+	return b
+}
+
+// Sign fills in From and Signature for the account of key.
+func Sign(tx *pb.Transaction, key ed25519.PrivateKey) {
+	tx.From = Address(key.Public().(ed25519.PublicKey))
+	tx.Signature = hex.EncodeToString(ed25519.Sign(key, SigningBytes(tx)))
+}
+
+// Register returns a registration of the participation key userId votes
+// with from firstRound through lastRound. The account that owns userId sets
+// its Nonce and signs it with Sign.
+func Register(userId string, key ed25519.PublicKey, firstRound, lastRound int64) *pb.Transaction {
+	return &pb.Transaction{Participation: &pb.Participation{UserId: userId, Key: hex.EncodeToString(key), FirstRound: firstRound, LastRound: lastRound}}
+}
+
+// Check verifies everything about tx that doesn't depend on the ledger: the
+// addresses, the amount or registration and the sender's signature.
+func Check(tx *pb.Transaction) error {
+	if IsRecord(tx) {
+		return nil
+	}
+	if tx.From == "" {
+		return ErrAllocation
+	}
+	from, err := publicKey(tx.From)
+	if err != nil {
+		return err
+	}
+	if tx.Participation != nil {
+		if tx.To != "" || tx.Amount != 0 {
+			return fmt.Errorf("%v: it can't move funds", ErrBadParticipation)
+		}
+		if err := checkParticipation(tx.Participation); err != nil {
+			return err
+		}
+	} else {
+		if _, err := publicKey(tx.To); err != nil {
+			return err
+		}
+		if tx.Amount <= 0 {
+			return ErrBadAmount
+		}
+	}
+	signature, err := hex.DecodeString(tx.Signature)
+	if err != nil || !ed25519.Verify(from, SigningBytes(tx), signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// checkParticipation verifies a registration on its own: the user, the key
+// and its range of rounds.
+func checkParticipation(p *pb.Participation) error {
+	if p.UserId == "" {
+		return fmt.Errorf("%v: no user", ErrBadParticipation)
+	}
+	if _, err := publicKey(p.Key); err != nil {
+		return fmt.Errorf("%v: key %.16q", ErrBadParticipation, p.Key)
+	}
+	if p.FirstRound < 1 || p.LastRound < p.FirstRound {
+		return fmt.Errorf("%v: rounds %v to %v", ErrBadParticipation, p.FirstRound, p.LastRound)
+	}
+	return nil
+}
+
+type account struct {
+	balance int64
+	// of the last transfer out of the account
+	nonce int64
+}
+
+type participant struct {
+	// account that may register the user's keys, empty if none may
+	owner string
+	// every registration so far, in chain order
+	keys []*pb.Participation
+}
+
+// Ledger is the state of every account and the participation keys of every
+// user. The zero value is not usable, start from Genesis.
+type Ledger struct {
+	accounts     map[string]account
+	participants map[string]participant
+	// of the last block applied
+	round int64
+}
+
+// Genesis starts a ledger from the allocations in the genesis block, every
+// transaction in it with an empty From credits To with Amount. One with
+// Participation instead makes To the owner of the user and registers the
+// user's first key.
+func Genesis(block *pb.Block) (*Ledger, error) {
+	l := &Ledger{accounts: make(map[string]account), participants: make(map[string]participant)}
+	// no balance can overflow once the total supply fits
+	supply := int64(0)
+	for _, tx := range block.GetTx() {
+		if IsRecord(tx) {
+			continue
+		}
+		if tx.From != "" || tx.Signature != "" || tx.Nonce != 0 {
+			return nil, fmt.Errorf("genesis may only allocate funds, not transfer them")
+		}
+		if p := tx.Participation; p != nil {
+			if err := checkParticipation(p); err != nil {
+				return nil, err
+			}
+			if tx.To != "" {
+				if _, err := publicKey(tx.To); err != nil {
+					return nil, err
+				}
+			}
+			if tx.Amount != 0 {
+				return nil, fmt.Errorf("%v: it can't move funds", ErrBadParticipation)
+			}
+			if _, ok := l.participants[p.UserId]; ok {
+				return nil, fmt.Errorf("%v: user %v is registered twice", ErrBadParticipation, p.UserId)
+			}
+			l.participants[p.UserId] = participant{owner: tx.To, keys: []*pb.Participation{p}}
+			continue
+		}
+		if _, err := publicKey(tx.To); err != nil {
+			return nil, err
+		}
+		if tx.Amount <= 0 || supply+tx.Amount < supply {
+			return nil, fmt.Errorf("%v: %v to %.16v", ErrBadAmount, tx.Amount, tx.To)
+		}
+		supply += tx.Amount
+		a := l.accounts[tx.To]
+		a.balance += tx.Amount
+		l.accounts[tx.To] = a
+	}
+	return l, nil
+}
+
+// Clone returns a copy to try transactions on.
+func (l *Ledger) Clone() *Ledger {
+	c := &Ledger{accounts: make(map[string]account, len(l.accounts)), participants: make(map[string]participant, len(l.participants)), round: l.round}
+	for address, a := range l.accounts {
+		c.accounts[address] = a
+	}
+	for user, p := range l.participants {
+		// registering on the copy must not write to our keys
+		p.keys = p.keys[:len(p.keys):len(p.keys)]
+		c.participants[user] = p
+	}
+	return c
+}
+
+func (l *Ledger) Balance(address string) int64 {
+	return l.accounts[address].balance
+}
+
+// Nonce is the nonce of the last transfer out of address, the next has to use one more.
+func (l *Ledger) Nonce(address string) int64 {
+	return l.accounts[address].nonce
+}
+
+// VotingKey is the participation key userId votes with in round: the last
+// one registered for a range that covers it, nil if there is none. A key
+// only counts from after the round it was registered in, so later
+// registrations never change the key of a round already voted on.
+func (l *Ledger) VotingKey(userId string, round int64) ed25519.PublicKey {
+	keys := l.participants[userId].keys
+	for i := len(keys) - 1; i >= 0; i-- {
+		if p := keys[i]; p.FirstRound <= round && round <= p.LastRound {
+			key, _ := publicKey(p.Key)
+			return key
+		}
+	}
+	return nil
+}
+
+// Apply moves the funds of tx or registers its key, or leaves the ledger
+// alone and says why it can't. tx goes into the block after the last one
+// applied.
+func (l *Ledger) Apply(tx *pb.Transaction) error {
+	if err := Check(tx); err != nil {
+		return err
+	}
+	if IsRecord(tx) {
+		return nil
+	}
+	from := l.accounts[tx.From]
+	if tx.Nonce != from.nonce+1 {
+		return fmt.Errorf("%v: got %v, expected %v", ErrBadNonce, tx.Nonce, from.nonce+1)
+	}
+	if p := tx.Participation; p != nil {
+		user, ok := l.participants[p.UserId]
+		if !ok || user.owner != tx.From {
+			return fmt.Errorf("%v: %.16v doesn't own %v", ErrNotOwner, tx.From, p.UserId)
+		}
+		if p.FirstRound <= l.round+1 {
+			return fmt.Errorf("%v: first round %v has to come after round %v it is registered in", ErrBadParticipation, p.FirstRound, l.round+1)
+		}
+		user.keys = append(user.keys, p)
+		l.participants[p.UserId] = user
+		from.nonce = tx.Nonce
+		l.accounts[tx.From] = from
+		return nil
+	}
+	if from.balance < tx.Amount {
+		return fmt.Errorf("%v: %.16v has %v, sends %v", ErrInsufficientFunds, tx.From, from.balance, tx.Amount)
+	}
+	from.balance -= tx.Amount
+	from.nonce = tx.Nonce
+	l.accounts[tx.From] = from
+
+	to := l.accounts[tx.To]
+	to.balance += tx.Amount
+	l.accounts[tx.To] = to
+	return nil
+}
+
+// ApplyBlock applies every transaction of block in order. On error the ledger
+// is left partially applied, use it on a Clone.
+func (l *Ledger) ApplyBlock(block *pb.Block) error {
+	for i, tx := range block.GetTx() {
+		if err := l.Apply(tx); err != nil {
+			return fmt.Errorf("transaction %v: %v", i, err)
+		}
+	}
+	l.round = block.Id
+	return nil
+}
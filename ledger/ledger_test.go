@@ -0,0 +1,235 @@
+package ledger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+func pub(key ed25519.PrivateKey) ed25519.PublicKey {
+	return key.Public().(ed25519.PublicKey)
+}
+
+// is tells whether err is want, wrapped with details or not.
+func is(err, want error) bool {
+	if err == nil || want == nil {
+		return err == want
+	}
+	return strings.Contains(err.Error(), want.Error())
+}
+
+var (
+	alice          = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+	bob            = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{2}, ed25519.SeedSize))
+	aliceVotes     = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{3}, ed25519.SeedSize))
+	aliceVotesNext = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{4}, ed25519.SeedSize))
+	bobVotes       = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{5}, ed25519.SeedSize))
+)
+
+// genesis gives alice 100 and bob 10, each owns the user of its own name.
+func genesis(t *testing.T) *Ledger {
+	first := func(user string, owner, key ed25519.PrivateKey) *pb.Transaction {
+		tx := Register(user, pub(key), 1, 100)
+		tx.To = Address(pub(owner))
+		return tx
+	}
+	l, err := Genesis(&pb.Block{Tx: []*pb.Transaction{
+		{To: Address(pub(alice)), Amount: 100},
+		{To: Address(pub(bob)), Amount: 10},
+		first("alice", alice, aliceVotes),
+		first("bob", bob, bobVotes),
+		{V: "hello"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func transfer(from, to ed25519.PrivateKey, amount, nonce int64) *pb.Transaction {
+	tx := &pb.Transaction{To: Address(pub(to)), Amount: amount, Nonce: nonce}
+	Sign(tx, from)
+	return tx
+}
+
+func register(owner ed25519.PrivateKey, user string, key ed25519.PrivateKey, first, last, nonce int64) *pb.Transaction {
+	tx := Register(user, pub(key), first, last)
+	tx.Nonce = nonce
+	Sign(tx, owner)
+	return tx
+}
+
+func TestApply(t *testing.T) {
+	tampered := transfer(alice, bob, 5, 1)
+	tampered.Amount = 50
+	stolen := transfer(bob, alice, 5, 1)
+	stolen.From = Address(pub(alice))
+
+	tests := []struct {
+		name string
+		// applied in order, each has to fail with its error
+		txs  []*pb.Transaction
+		errs []error
+		// balances of alice and bob after
+		alice, bob int64
+	}{
+		{
+			name:  "transfers",
+			txs:   []*pb.Transaction{transfer(alice, bob, 30, 1), transfer(bob, alice, 5, 1), transfer(alice, bob, 1, 2)},
+			errs:  []error{nil, nil, nil},
+			alice: 70 - 1 + 5, bob: 10 + 30 + 1 - 5,
+		},
+		{
+			name:  "records move nothing",
+			txs:   []*pb.Transaction{{V: "note"}},
+			errs:  []error{nil},
+			alice: 100, bob: 10,
+		},
+		{
+			name:  "nonces follow each other",
+			txs:   []*pb.Transaction{transfer(alice, bob, 1, 2), transfer(alice, bob, 1, 1), transfer(alice, bob, 1, 1), transfer(alice, bob, 1, 3)},
+			errs:  []error{ErrBadNonce, nil, ErrBadNonce, ErrBadNonce},
+			alice: 99, bob: 11,
+		},
+		{
+			name:  "a failed transfer takes no nonce",
+			txs:   []*pb.Transaction{transfer(bob, alice, 11, 1), transfer(bob, alice, 10, 1)},
+			errs:  []error{ErrInsufficientFunds, nil},
+			alice: 110, bob: 0,
+		},
+		{
+			name: "signatures",
+			txs:  []*pb.Transaction{tampered, stolen, {To: Address(pub(bob)), Amount: 5}},
+			errs: []error{ErrBadSignature, ErrBadSignature, ErrAllocation},
+			// nothing moved
+			alice: 100, bob: 10,
+		},
+		{
+			name:  "amounts",
+			txs:   []*pb.Transaction{transfer(alice, bob, 0, 1), transfer(alice, bob, -5, 1)},
+			errs:  []error{ErrBadAmount, ErrBadAmount},
+			alice: 100, bob: 10,
+		},
+		{
+			name: "registrations take nonces from the owner",
+			txs: []*pb.Transaction{
+				register(alice, "alice", aliceVotesNext, 50, 200, 1),
+				transfer(alice, bob, 1, 1),
+				transfer(alice, bob, 1, 2),
+			},
+			errs:  []error{nil, ErrBadNonce, nil},
+			alice: 99, bob: 11,
+		},
+	}
+	for _, test := range tests {
+		l := genesis(t)
+		for i, tx := range test.txs {
+			if err := l.Apply(tx); !is(err, test.errs[i]) {
+				t.Errorf("%v: transaction %v: got %v, want %v", test.name, i, err, test.errs[i])
+			}
+		}
+		if a, b := l.Balance(Address(pub(alice))), l.Balance(Address(pub(bob))); a != test.alice || b != test.bob {
+			t.Errorf("%v: alice has %v and bob %v, want %v and %v", test.name, a, b, test.alice, test.bob)
+		}
+	}
+}
+
+func TestRegistration(t *testing.T) {
+	withFunds := register(alice, "alice", aliceVotesNext, 50, 200, 1)
+	withFunds.Amount = 5
+	Sign(withFunds, alice)
+
+	tests := []struct {
+		name string
+		tx   *pb.Transaction
+		err  error
+	}{
+		{"owner", register(alice, "alice", aliceVotesNext, 50, 200, 1), nil},
+		{"someone else's user", register(alice, "bob", aliceVotesNext, 50, 200, 1), ErrNotOwner},
+		{"unknown user", register(alice, "carol", aliceVotesNext, 50, 200, 1), ErrNotOwner},
+		{"the round it goes into", register(alice, "alice", aliceVotesNext, 11, 200, 1), ErrBadParticipation},
+		{"backwards range", register(alice, "alice", aliceVotesNext, 50, 49, 1), ErrBadParticipation},
+		{"moves funds", withFunds, ErrBadParticipation},
+		{"stale nonce", register(alice, "alice", aliceVotesNext, 50, 200, 0), ErrBadNonce},
+	}
+	for _, test := range tests {
+		l := genesis(t)
+		if err := l.ApplyBlock(&pb.Block{Id: 10}); err != nil {
+			t.Fatal(err)
+		}
+		err := l.ApplyBlock(&pb.Block{Id: 11, Tx: []*pb.Transaction{test.tx}})
+		if !is(err, test.err) {
+			t.Errorf("%v: got %v, want %v", test.name, err, test.err)
+		}
+
+		// keys only change when the registration went in
+		want := pub(aliceVotes)
+		if test.err == nil {
+			want = pub(aliceVotesNext)
+		}
+		if got := l.VotingKey("alice", 60); !bytes.Equal(got, want) {
+			t.Errorf("%v: alice votes in round 60 with %x, want %x", test.name, got, want)
+		}
+	}
+}
+
+func TestVotingKey(t *testing.T) {
+	l := genesis(t)
+	if err := l.Apply(register(alice, "alice", aliceVotesNext, 50, 200, 1)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		user  string
+		round int64
+		key   ed25519.PublicKey
+	}{
+		{"alice", 1, pub(aliceVotes)},
+		{"alice", 49, pub(aliceVotes)},
+		{"alice", 50, pub(aliceVotesNext)},
+		{"alice", 200, pub(aliceVotesNext)},
+		{"alice", 201, nil},
+		{"bob", 100, pub(bobVotes)},
+		{"bob", 101, nil},
+		{"carol", 1, nil},
+	}
+	for _, test := range tests {
+		if got := l.VotingKey(test.user, test.round); !bytes.Equal(got, test.key) {
+			t.Errorf("%v in round %v votes with %x, want %x", test.user, test.round, got, test.key)
+		}
+	}
+
+	// registering on a clone leaves the original alone
+	c := l.Clone()
+	if err := c.Apply(register(bob, "bob", bobVotes, 101, 300, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if l.VotingKey("bob", 150) != nil || c.VotingKey("bob", 150) == nil {
+		t.Errorf("registration on the clone leaked into the original")
+	}
+}
+
+func TestGenesis(t *testing.T) {
+	tests := []struct {
+		name string
+		tx   *pb.Transaction
+	}{
+		{"signed transfer", transfer(alice, bob, 1, 1)},
+		{"nothing allocated", &pb.Transaction{To: Address(pub(alice))}},
+		{"bad address", &pb.Transaction{To: "alice", Amount: 1}},
+		{"registration with funds", &pb.Transaction{To: Address(pub(alice)), Amount: 1, Participation: Register("alice", pub(aliceVotes), 1, 100).Participation}},
+		{"second registration", &pb.Transaction{To: Address(pub(bob)), Participation: Register("alice", pub(bobVotes), 1, 100).Participation}},
+	}
+	for _, test := range tests {
+		block := &pb.Block{Tx: []*pb.Transaction{
+			{To: Address(pub(alice)), Participation: Register("alice", pub(aliceVotes), 1, 100).Participation},
+			test.tx,
+		}}
+		if _, err := Genesis(block); err == nil {
+			t.Errorf("%v: genesis accepted it", test.name)
+		}
+	}
+}
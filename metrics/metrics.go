@@ -0,0 +1,106 @@
+// Package metrics holds the Prometheus metrics a node exports on /metrics.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+)
+
+// Agreement
+var (
+	Round = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "algorand_round",
+		Help: "Round the node is agreeing on.",
+	})
+	Period = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "algorand_period",
+		Help: "Period within the current round.",
+	})
+	Step = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "algorand_step",
+		Help: "BA* step within the current period.",
+	})
+
+	TimeToAgreement = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "algorand_time_to_agreement_seconds",
+		Help:    "Time from the start of a round until its block was agreed on.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 10),
+	})
+	PeriodsToAgreement = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "algorand_periods_to_agreement",
+		Help:    "Period in which a round's block was agreed on.",
+		Buckets: prometheus.LinearBuckets(1, 1, 8),
+	})
+
+	VotesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "algorand_votes_received_total",
+		Help: "Votes received from peers by vote type and whether they were counted.",
+	}, []string{"type", "result"})
+	ProposalsReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "algorand_proposals_received_total",
+		Help: "Block proposals received from peers by whether they were accepted.",
+	}, []string{"result"})
+	PrioritiesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "algorand_priorities_received_total",
+		Help: "Proposer priorities received from peers by whether they were accepted.",
+	}, []string{"result"})
+	Equivocations = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "algorand_equivocations_total",
+		Help: "Voters caught signing two different values for the same step.",
+	})
+)
+
+// Ledger
+var (
+	ChainLength = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "algorand_chain_length",
+		Help: "Number of blocks in the local chain, including genesis.",
+	})
+	TentativeBlocks = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "algorand_tentative_blocks",
+		Help: "Blocks at the end of the local chain adopted from peers without a certificate.",
+	})
+	CommittedTransactions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "algorand_committed_transactions_total",
+		Help: "Transactions in committed blocks, rate() gives transactions per second.",
+	})
+	MempoolSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "algorand_mempool_transactions",
+		Help: "Transactions waiting to go into a block.",
+	})
+	SyncLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "algorand_sync_lag_rounds",
+		Help: "How many rounds the node last saw itself behind its peers.",
+	})
+	Syncs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "algorand_syncs_total",
+		Help: "Chains received from peers by whether we switched to them.",
+	}, []string{"result"})
+)
+
+// Network
+var (
+	RejectedRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "algorand_rejected_requests_total",
+		Help: "Requests turned away because their queue was full or their deadline passed while queued.",
+	}, []string{"queue", "reason"})
+	PeerUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "algorand_peer_up",
+		Help: "1 if the last request to the peer succeeded, 0 if it failed.",
+	}, []string{"peer"})
+)
+
+// Serve exports the metrics over HTTP on addr, it never returns.
+func Serve(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	logging.API.Infof("Serving metrics on %v/metrics", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		logging.API.Fatalf("Failed to serve metrics %v", err)
+	}
+}
@@ -0,0 +1,335 @@
+package participation
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+// BatchSize is how many rounds share a batch key.
+const BatchSize = 256
+
+// MaxRounds is the longest range of rounds one key may cover, a key file
+// holds one batch seed for every BatchSize of them.
+const MaxRounds = 1 << 24
+
+var ErrBadEphemeralKey = errors.New("vote isn't signed by the one-time key of the voter's participation key for the round")
+
+// Key is a forward secure participation key. The key registered on chain
+// is only the root of a tree: it signs one batch key for every BatchSize
+// rounds, and each batch key signs a one-time key for every round of its
+// batch. The root secret is thrown away once the batch keys are signed, a
+// batch secret once its round keys are, and a round key once its round is
+// over. Whoever steals a node's keys later can't sign votes for rounds
+// that already passed.
+type Key struct {
+	Root        ed25519.PublicKey
+	First, Last int64
+
+	// keys of rounds before next are erased
+	next    int64
+	batches map[int64]*pb.ParticipationBatch
+	rounds  map[int64]*pb.ParticipationRoundKey
+	// only insecure keys keep their root and derive everything from it
+	root ed25519.PrivateKey
+	// where the key is kept, nil for keys that only live in memory
+	store Store
+}
+
+// A Store keeps a key between runs, see package keystore. Save gets what
+// is left of the key after every erasure, Remove is called once nothing is.
+type Store interface {
+	Save(key []byte) error
+	Remove() error
+}
+
+func newKey(root ed25519.PublicKey, first, last int64) *Key {
+	return &Key{
+		Root:    root,
+		First:   first,
+		Last:    last,
+		next:    first,
+		batches: make(map[int64]*pb.ParticipationBatch),
+		rounds:  make(map[int64]*pb.ParticipationRoundKey),
+	}
+}
+
+// Generate creates a key for rounds first to last. Its root secret is gone
+// by the time it returns, Keep the key somewhere before using it.
+func Generate(first, last int64) (*Key, error) {
+	if first < 1 || last < first {
+		return nil, fmt.Errorf("invalid rounds %v to %v", first, last)
+	}
+	if last-first >= MaxRounds {
+		return nil, fmt.Errorf("rounds %v to %v are more than %v", first, last, MaxRounds)
+	}
+	seed, err := randomSeed()
+	if err != nil {
+		return nil, err
+	}
+	root := ed25519.NewKeyFromSeed(seed)
+	k := newKey(root.Public().(ed25519.PublicKey), first, last)
+	for j := first / BatchSize; j <= last/BatchSize; j++ {
+		batchSeed, err := randomSeed()
+		if err != nil {
+			return nil, err
+		}
+		k.batches[j] = newBatch(root, j, batchSeed)
+	}
+	return k, nil
+}
+
+// Insecure returns a key that keeps root and derives every other key from
+// it, so erasing protects nothing. Only for testing.
+func Insecure(root ed25519.PrivateKey, first, last int64) *Key {
+	k := newKey(root.Public().(ed25519.PublicKey), first, last)
+	k.root = root
+	return k
+}
+
+// Unmarshal reads a key Marshal wrote, store keeps it from then on.
+func Unmarshal(b []byte, store Store) (*Key, error) {
+	file := &pb.ParticipationKeyFile{}
+	if err := proto.Unmarshal(b, file); err != nil {
+		return nil, err
+	}
+	root, err := decodeKey(file.Root)
+	if err != nil || file.FirstRound < 1 || file.LastRound < file.FirstRound || file.Next < file.FirstRound {
+		return nil, errors.New("isn't a participation key")
+	}
+	k := newKey(root, file.FirstRound, file.LastRound)
+	k.next = file.Next
+	for _, b := range file.Batches {
+		k.batches[b.Batch] = b
+	}
+	for _, r := range file.Rounds {
+		k.rounds[r.Round] = r
+	}
+	k.store = store
+	return k, nil
+}
+
+// Keep saves the key to store, which from then on follows every erasure.
+func (k *Key) Keep(store Store) error {
+	if k.root != nil {
+		return errors.New("insecure keys can't be kept")
+	}
+	k.store = store
+	return k.save()
+}
+
+// Remove deletes the key from its store.
+func (k *Key) Remove() error {
+	if k.store == nil {
+		return nil
+	}
+	return k.store.Remove()
+}
+
+// Sign signs message with the one-time key of round and returns the
+// signature with what ties the one-time key to Root, see Verify.
+func (k *Key) Sign(round int64, message []byte) ([]byte, *pb.EphemeralKey, error) {
+	if round < k.First || round > k.Last {
+		return nil, nil, fmt.Errorf("%v: key %x is for rounds %v to %v", ErrNoKey, []byte(k.Root), k.First, k.Last)
+	}
+	if round < k.next {
+		return nil, nil, fmt.Errorf("%v: the one-time key of round %v is erased", ErrNoKey, round)
+	}
+	r, ok := k.rounds[round]
+	if !ok {
+		if err := k.open(round / BatchSize); err != nil {
+			return nil, nil, err
+		}
+		r = k.rounds[round]
+	}
+	seed, err := hex.DecodeString(r.Seed)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, nil, fmt.Errorf("%v: the one-time key of round %v is corrupt", ErrNoKey, round)
+	}
+	key := ed25519.NewKeyFromSeed(seed)
+	proof := &pb.EphemeralKey{
+		Key:            hex.EncodeToString(key.Public().(ed25519.PublicKey)),
+		BatchKey:       r.BatchKey,
+		BatchSignature: r.BatchSignature,
+		Signature:      r.Signature,
+	}
+	return ed25519.Sign(key, message), proof, nil
+}
+
+// open sets up the one-time keys of batch j from round next on and erases
+// the batch secret.
+func (k *Key) open(j int64) error {
+	b, ok := k.batches[j]
+	if !ok && k.root != nil {
+		b = newBatch(k.root, j, derive(k.root.Seed(), "batch", j))
+	} else if !ok {
+		return fmt.Errorf("%v: the batch key of rounds %v to %v is erased", ErrNoKey, j*BatchSize, (j+1)*BatchSize-1)
+	}
+	seed, err := hex.DecodeString(b.Seed)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return fmt.Errorf("%v: the batch key of rounds %v to %v is corrupt", ErrNoKey, j*BatchSize, (j+1)*BatchSize-1)
+	}
+	batchKey := ed25519.NewKeyFromSeed(seed)
+	batchPub := hex.EncodeToString(batchKey.Public().(ed25519.PublicKey))
+	for round := max(j*BatchSize, k.next); round < (j+1)*BatchSize && round <= k.Last; round++ {
+		roundSeed := derive(seed, "round", round)
+		if k.root == nil {
+			// round secrets of real keys don't follow from the batch secret
+			if roundSeed, err = randomSeed(); err != nil {
+				return err
+			}
+		}
+		pub := ed25519.NewKeyFromSeed(roundSeed).Public().(ed25519.PublicKey)
+		k.rounds[round] = &pb.ParticipationRoundKey{
+			Round:          round,
+			Seed:           hex.EncodeToString(roundSeed),
+			Signature:      hex.EncodeToString(ed25519.Sign(batchKey, roundSigningBytes(round, pub))),
+			BatchKey:       batchPub,
+			BatchSignature: b.Signature,
+		}
+	}
+	delete(k.batches, j)
+	return k.save()
+}
+
+// Erase forgets the one-time keys of every round before round, and the
+// batch keys only those rounds need.
+func (k *Key) Erase(round int64) error {
+	if round <= k.next {
+		return nil
+	}
+	for r := range k.rounds {
+		if r < round {
+			delete(k.rounds, r)
+		}
+	}
+	for j := range k.batches {
+		if (j+1)*BatchSize <= round {
+			delete(k.batches, j)
+		}
+	}
+	k.next = round
+	return k.save()
+}
+
+// Expired tells whether every round of the key is erased.
+func (k *Key) Expired() bool {
+	return k.next > k.Last
+}
+
+// Marshal encodes what is left of the key, only the one-time keys of the
+// rounds to come and the batch keys that aren't opened yet.
+func (k *Key) Marshal() ([]byte, error) {
+	file := &pb.ParticipationKeyFile{
+		Root:       hex.EncodeToString(k.Root),
+		FirstRound: k.First,
+		LastRound:  k.Last,
+		Next:       k.next,
+	}
+	for _, b := range k.batches {
+		file.Batches = append(file.Batches, b)
+	}
+	for _, r := range k.rounds {
+		file.Rounds = append(file.Rounds, r)
+	}
+	sort.Slice(file.Batches, func(i, j int) bool { return file.Batches[i].Batch < file.Batches[j].Batch })
+	sort.Slice(file.Rounds, func(i, j int) bool { return file.Rounds[i].Round < file.Rounds[j].Round })
+	return proto.Marshal(file)
+}
+
+// save replaces the key in its store by what is left of it.
+func (k *Key) save() error {
+	if k.store == nil {
+		return nil
+	}
+	b, err := k.Marshal()
+	if err != nil {
+		return err
+	}
+	return k.store.Save(b)
+}
+
+// Verify checks that signature over message was made with the one-time key
+// root set up for round, proof carries that key and its signatures.
+func Verify(root ed25519.PublicKey, round int64, message, signature []byte, proof *pb.EphemeralKey) error {
+	key, err := decodeKey(proof.GetKey())
+	if err != nil {
+		return ErrBadEphemeralKey
+	}
+	batchKey, err := decodeKey(proof.GetBatchKey())
+	if err != nil {
+		return ErrBadEphemeralKey
+	}
+	batchSignature, err := hex.DecodeString(proof.GetBatchSignature())
+	if err != nil {
+		return ErrBadEphemeralKey
+	}
+	keySignature, err := hex.DecodeString(proof.GetSignature())
+	if err != nil {
+		return ErrBadEphemeralKey
+	}
+	if len(root) != ed25519.PublicKeySize ||
+		!ed25519.Verify(root, batchSigningBytes(round/BatchSize, batchKey), batchSignature) ||
+		!ed25519.Verify(batchKey, roundSigningBytes(round, key), keySignature) ||
+		!ed25519.Verify(key, message, signature) {
+		return ErrBadEphemeralKey
+	}
+	return nil
+}
+
+func newBatch(root ed25519.PrivateKey, j int64, seed []byte) *pb.ParticipationBatch {
+	pub := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+	return &pb.ParticipationBatch{
+		Batch:     j,
+		Seed:      hex.EncodeToString(seed),
+		Signature: hex.EncodeToString(ed25519.Sign(root, batchSigningBytes(j, pub))),
+	}
+}
+
+// batchSigningBytes and roundSigningBytes are what the root and batch keys
+// sign, labelled so neither passes for the other or for a vote.
+func batchSigningBytes(j int64, key ed25519.PublicKey) []byte {
+	return append(strconv.AppendInt([]byte("participation batch:"), j, 10), append([]byte{':'}, key...)...)
+}
+
+func roundSigningBytes(round int64, key ed25519.PublicKey) []byte {
+	return append(strconv.AppendInt([]byte("participation round:"), round, 10), append([]byte{':'}, key...)...)
+}
+
+func derive(seed []byte, label string, n int64) []byte {
+	h := sha256.Sum256(bytes.Join([][]byte{[]byte(label), strconv.AppendInt(nil, n, 10), seed}, []byte{':'}))
+	return h[:]
+}
+
+func randomSeed() ([]byte, error) {
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	return seed, nil
+}
+
+func decodeKey(s string) (ed25519.PublicKey, error) {
+	key, err := hex.DecodeString(s)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid ed25519 public key")
+	}
+	return key, nil
+}
+
+func max(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,83 @@
+// Package participation signs votes with the participation keys a node
+// holds. The ledger says which key a user votes with in each round, see
+// ledger.VotingKey. A node keeps the keys registered for its coming ranges
+// of rounds and switches to the next one at the boundary on its own.
+//
+// Participation keys are forward secure, see Key: every vote is signed with
+// a one-time key for its round that is erased once the round is over.
+package participation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+var ErrNoKey = errors.New("no participation key to vote with")
+
+// Lookup returns the key registered for us in round, nil if there is none.
+type Lookup func(round int64) ed25519.PublicKey
+
+// Keys signs with whichever of a node's participation keys is registered
+// for the round.
+type Keys struct {
+	keys   []*Key
+	lookup Lookup
+	// the key we signed with last
+	current ed25519.PublicKey
+}
+
+func NewKeys(keys []*Key, lookup Lookup) *Keys {
+	return &Keys{keys: keys, lookup: lookup}
+}
+
+// Sign signs message with the one-time key for round of whichever of our
+// keys is registered for it, it implements agreement.Signer.
+func (k *Keys) Sign(round int64, message []byte) ([]byte, *pb.EphemeralKey, error) {
+	registered := k.lookup(round)
+	if registered == nil {
+		return nil, nil, fmt.Errorf("%v: none is registered for round %v", ErrNoKey, round)
+	}
+	for _, key := range k.keys {
+		if !bytes.Equal(key.Root, registered) || round < key.First || round > key.Last {
+			continue
+		}
+		signature, ephemeral, err := key.Sign(round, message)
+		if err == nil && !bytes.Equal(key.Root, k.current) {
+			logging.Agreement.WithField("round", round).Infof("Voting with participation key %x", []byte(key.Root))
+			k.current = key.Root
+		}
+		return signature, ephemeral, err
+	}
+	return nil, nil, fmt.Errorf("%v: round %v needs key %x, which we don't hold", ErrNoKey, round, []byte(registered))
+}
+
+// Erase forgets the one-time keys of every round before round and removes
+// keys with no rounds left from their stores.
+func (k *Keys) Erase(round int64) error {
+	var errs []string
+	keys := k.keys[:0]
+	for _, key := range k.keys {
+		if err := key.Erase(round); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if !key.Expired() {
+			keys = append(keys, key)
+			continue
+		}
+		logging.Agreement.WithField("round", round).Infof("Participation key %x is used up, removing it", []byte(key.Root))
+		if err := key.Remove(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	k.keys = keys
+	if len(errs) > 0 {
+		return fmt.Errorf("could not erase participation keys: %v", errs)
+	}
+	return nil
+}
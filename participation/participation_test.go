@@ -0,0 +1,183 @@
+package participation
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// memory is a Store that keeps the last thing saved.
+type memory struct {
+	saved   []byte
+	removed bool
+}
+
+func (m *memory) Save(key []byte) error {
+	m.saved = append([]byte(nil), key...)
+	return nil
+}
+
+func (m *memory) Remove() error {
+	m.saved, m.removed = nil, true
+	return nil
+}
+
+func TestSignVerify(t *testing.T) {
+	key, err := Generate(1, 3*BatchSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := key.Keep(&memory{}); err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("vote")
+	for _, round := range []int64{1, BatchSize - 1, BatchSize, 3 * BatchSize} {
+		signature, proof, err := key.Sign(round, message)
+		if err != nil {
+			t.Fatalf("round %v: %v", round, err)
+		}
+		if err := Verify(key.Root, round, message, signature, proof); err != nil {
+			t.Errorf("round %v: %v", round, err)
+		}
+		if err := Verify(key.Root, round+1, message, signature, proof); err != ErrBadEphemeralKey {
+			t.Errorf("round %v: verified for round %v", round, round+1)
+		}
+		if err := Verify(key.Root, round, []byte("other vote"), signature, proof); err != ErrBadEphemeralKey {
+			t.Errorf("round %v: verified another message", round)
+		}
+	}
+	if _, _, err := key.Sign(3*BatchSize+1, message); err == nil {
+		t.Errorf("signed past the last round")
+	}
+}
+
+// Once a round is erased no copy of the key, the one in memory or what the
+// store holds, can sign for it again.
+func TestEraseThenSign(t *testing.T) {
+	store := &memory{}
+	key, err := Generate(1, 2*BatchSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := key.Keep(store); err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("vote")
+	for _, round := range []int64{1, 10, BatchSize + 1} {
+		if _, _, err := key.Sign(round, message); err != nil {
+			t.Fatalf("round %v: %v", round, err)
+		}
+	}
+
+	erased := BatchSize + 5
+	if err := key.Erase(int64(erased)); err != nil {
+		t.Fatal(err)
+	}
+	stolen, err := Unmarshal(store.saved, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []*Key{key, stolen} {
+		for _, round := range []int64{1, 10, BatchSize - 1, BatchSize + 1, int64(erased) - 1} {
+			if _, _, err := k.Sign(round, message); err == nil {
+				t.Errorf("signed round %v after erasing up to %v", round, erased)
+			}
+		}
+		if _, _, err := k.Sign(int64(erased), message); err != nil {
+			t.Errorf("round %v: %v", erased, err)
+		}
+	}
+
+	// erasing never goes back
+	if err := key.Erase(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := key.Sign(10, message); err == nil {
+		t.Errorf("signed round 10 after erasing back to round 1")
+	}
+
+	if err := key.Erase(2*BatchSize + 1); err != nil {
+		t.Fatal(err)
+	}
+	if !key.Expired() {
+		t.Errorf("key isn't expired after its last round")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	first, err := Generate(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Generate(5, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := []*memory{{}, {}}
+	for i, key := range []*Key{first, second} {
+		if err := key.Keep(stores[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the second key is registered from round 8 on
+	keys := NewKeys([]*Key{first, second}, func(round int64) ed25519.PublicKey {
+		switch {
+		case round < 8:
+			return first.Root
+		case round <= 20:
+			return second.Root
+		}
+		return nil
+	})
+
+	message := []byte("vote")
+	for _, test := range []struct {
+		round int64
+		root  ed25519.PublicKey
+	}{{1, first.Root}, {7, first.Root}, {8, second.Root}, {20, second.Root}} {
+		signature, proof, err := keys.Sign(test.round, message)
+		if err != nil {
+			t.Fatalf("round %v: %v", test.round, err)
+		}
+		if err := Verify(test.root, test.round, message, signature, proof); err != nil {
+			t.Errorf("round %v: %v", test.round, err)
+		}
+	}
+	if _, _, err := keys.Sign(21, message); err == nil {
+		t.Errorf("signed round 21 with no key registered")
+	}
+
+	if err := keys.Erase(12); err != nil {
+		t.Fatal(err)
+	}
+	if !stores[0].removed || stores[1].removed {
+		t.Errorf("erasing to round 12 removed the first key %v and the second %v", stores[0].removed, stores[1].removed)
+	}
+	if _, _, err := keys.Sign(9, message); err == nil {
+		t.Errorf("signed round 9 after erasing it")
+	}
+	if _, _, err := keys.Sign(12, message); err != nil {
+		t.Errorf("round 12: %v", err)
+	}
+}
+
+func TestInsecure(t *testing.T) {
+	root := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	a, b := Insecure(root, 1, 100), Insecure(root, 1, 100)
+	message := []byte("vote")
+	sa, proofA, err := a.Sign(42, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb, proofB, err := b.Sign(42, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sa, sb) || proofA.Key != proofB.Key {
+		t.Errorf("insecure keys from the same root sign differently")
+	}
+	if err := a.Keep(&memory{}); err == nil {
+		t.Errorf("kept an insecure key")
+	}
+}
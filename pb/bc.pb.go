@@ -25,18 +25,24 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 type Op int32
 
 const (
-	Op_GET  Op = 0
-	Op_SEND Op = 1
+	Op_GET      Op = 0
+	Op_SEND     Op = 1
+	Op_EVIDENCE Op = 2
+	Op_FINALITY Op = 3
 )
 
 var Op_name = map[int32]string{
 	0: "GET",
 	1: "SEND",
+	2: "EVIDENCE",
+	3: "FINALITY",
 }
 
 var Op_value = map[string]int32{
-	"GET":  0,
-	"SEND": 1,
+	"GET":      0,
+	"SEND":     1,
+	"EVIDENCE": 2,
+	"FINALITY": 3,
 }
 
 func (x Op) String() string {
@@ -150,11 +156,24 @@ func (m *Error) GetMsg() string {
 	return ""
 }
 
+// A transaction is either a plain record, only v set, or a transfer signed
+// by the account it spends from. See package ledger.
 type Transaction struct {
-	V                    string   `protobuf:"bytes,1,opt,name=v,proto3" json:"v,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	V string `protobuf:"bytes,1,opt,name=v,proto3" json:"v,omitempty"`
+	// hex encoded ed25519 public keys of the sending and receiving accounts,
+	// from is empty for the allocations in the genesis block
+	From   string `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To     string `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Amount int64  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	// one more than the sender's previous transfer, so none can be replayed
+	Nonce int64 `protobuf:"varint,5,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	// hex encoded signature by from over ledger.SigningBytes
+	Signature string `protobuf:"bytes,6,opt,name=signature,proto3" json:"signature,omitempty"`
+	// Set for a participation key registration, which moves no funds
+	Participation        *Participation `protobuf:"bytes,7,opt,name=participation,proto3" json:"participation,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
 }
 
 func (m *Transaction) Reset()         { *m = Transaction{} }
@@ -189,24 +208,142 @@ func (m *Transaction) GetV() string {
 	return ""
 }
 
+func (m *Transaction) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *Transaction) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+func (m *Transaction) GetAmount() int64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+func (m *Transaction) GetNonce() int64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
+}
+
+func (m *Transaction) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+func (m *Transaction) GetParticipation() *Participation {
+	if m != nil {
+		return m.Participation
+	}
+	return nil
+}
+
+// Registers the key user votes with from firstRound through lastRound. Only
+// the account that owns the user may register its keys, the genesis block
+// names the owners.
+type Participation struct {
+	UserId string `protobuf:"bytes,1,opt,name=userId,proto3" json:"userId,omitempty"`
+	// hex encoded ed25519 public key
+	Key                  string   `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	FirstRound           int64    `protobuf:"varint,3,opt,name=firstRound,proto3" json:"firstRound,omitempty"`
+	LastRound            int64    `protobuf:"varint,4,opt,name=lastRound,proto3" json:"lastRound,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Participation) Reset()         { *m = Participation{} }
+func (m *Participation) String() string { return proto.CompactTextString(m) }
+func (*Participation) ProtoMessage()    {}
+func (*Participation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{4}
+}
+
+func (m *Participation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Participation.Unmarshal(m, b)
+}
+func (m *Participation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Participation.Marshal(b, m, deterministic)
+}
+func (m *Participation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Participation.Merge(m, src)
+}
+func (m *Participation) XXX_Size() int {
+	return xxx_messageInfo_Participation.Size(m)
+}
+func (m *Participation) XXX_DiscardUnknown() {
+	xxx_messageInfo_Participation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Participation proto.InternalMessageInfo
+
+func (m *Participation) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *Participation) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Participation) GetFirstRound() int64 {
+	if m != nil {
+		return m.FirstRound
+	}
+	return 0
+}
+
+func (m *Participation) GetLastRound() int64 {
+	if m != nil {
+		return m.LastRound
+	}
+	return 0
+}
+
 // A single Block on a Blockchain
 type Block struct {
-	Id                   int64          `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Timestamp            string         `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	PrevHash             string         `protobuf:"bytes,3,opt,name=prevHash,proto3" json:"prevHash,omitempty"`
-	Hash                 string         `protobuf:"bytes,4,opt,name=hash,proto3" json:"hash,omitempty"`
-	Tx                   []*Transaction `protobuf:"bytes,5,rep,name=tx,proto3" json:"tx,omitempty"`
-	Seed                 string         `protobuf:"bytes,6,opt,name=seed,proto3" json:"seed,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
-	XXX_unrecognized     []byte         `json:"-"`
-	XXX_sizecache        int32          `json:"-"`
+	Id        int64          `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Timestamp string         `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	PrevHash  string         `protobuf:"bytes,3,opt,name=prevHash,proto3" json:"prevHash,omitempty"`
+	Hash      string         `protobuf:"bytes,4,opt,name=hash,proto3" json:"hash,omitempty"`
+	Tx        []*Transaction `protobuf:"bytes,5,rep,name=tx,proto3" json:"tx,omitempty"`
+	Seed      string         `protobuf:"bytes,6,opt,name=seed,proto3" json:"seed,omitempty"`
+	// Protocol version this block was built under
+	Protocol string `protobuf:"bytes,7,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// Protocol version the proposer would like to upgrade to, if any
+	UpgradeVote string `protobuf:"bytes,8,opt,name=upgradeVote,proto3" json:"upgradeVote,omitempty"`
+	// UserId of the proposer, used to weigh upgrade votes by stake
+	Proposer string `protobuf:"bytes,9,opt,name=proposer,proto3" json:"proposer,omitempty"`
+	// Hex encoded VRF proof of the proposer that seed follows from the seed
+	// of the block before, empty for empty blocks
+	SeedProof            string   `protobuf:"bytes,10,opt,name=seedProof,proto3" json:"seedProof,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Block) Reset()         { *m = Block{} }
 func (m *Block) String() string { return proto.CompactTextString(m) }
 func (*Block) ProtoMessage()    {}
 func (*Block) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{4}
+	return fileDescriptor_99e2a20f8b284799, []int{5}
 }
 
 func (m *Block) XXX_Unmarshal(b []byte) error {
@@ -269,20 +406,50 @@ func (m *Block) GetSeed() string {
 	return ""
 }
 
+func (m *Block) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *Block) GetUpgradeVote() string {
+	if m != nil {
+		return m.UpgradeVote
+	}
+	return ""
+}
+
+func (m *Block) GetProposer() string {
+	if m != nil {
+		return m.Proposer
+	}
+	return ""
+}
+
+func (m *Block) GetSeedProof() string {
+	if m != nil {
+		return m.SeedProof
+	}
+	return ""
+}
+
 // Input to AppendBlock
 type AppendBlockArgs struct {
-	Peer                 string   `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"`
-	Blockchain           []*Block `protobuf:"bytes,2,rep,name=blockchain,proto3" json:"blockchain,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Peer       string   `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"`
+	Blockchain []*Block `protobuf:"bytes,2,rep,name=blockchain,proto3" json:"blockchain,omitempty"`
+	// Certificates for blocks of the chain, see RequestBlockChainRet
+	Certificates         []*Certificate `protobuf:"bytes,3,rep,name=certificates,proto3" json:"certificates,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
 }
 
 func (m *AppendBlockArgs) Reset()         { *m = AppendBlockArgs{} }
 func (m *AppendBlockArgs) String() string { return proto.CompactTextString(m) }
 func (*AppendBlockArgs) ProtoMessage()    {}
 func (*AppendBlockArgs) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{5}
+	return fileDescriptor_99e2a20f8b284799, []int{6}
 }
 
 func (m *AppendBlockArgs) XXX_Unmarshal(b []byte) error {
@@ -317,6 +484,13 @@ func (m *AppendBlockArgs) GetBlockchain() []*Block {
 	return nil
 }
 
+func (m *AppendBlockArgs) GetCertificates() []*Certificate {
+	if m != nil {
+		return m.Certificates
+	}
+	return nil
+}
+
 type AppendBlockRet struct {
 	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -328,7 +502,7 @@ func (m *AppendBlockRet) Reset()         { *m = AppendBlockRet{} }
 func (m *AppendBlockRet) String() string { return proto.CompactTextString(m) }
 func (*AppendBlockRet) ProtoMessage()    {}
 func (*AppendBlockRet) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{6}
+	return fileDescriptor_99e2a20f8b284799, []int{7}
 }
 
 func (m *AppendBlockRet) XXX_Unmarshal(b []byte) error {
@@ -368,7 +542,7 @@ func (m *AppendTransactionArgs) Reset()         { *m = AppendTransactionArgs{} }
 func (m *AppendTransactionArgs) String() string { return proto.CompactTextString(m) }
 func (*AppendTransactionArgs) ProtoMessage()    {}
 func (*AppendTransactionArgs) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{7}
+	return fileDescriptor_99e2a20f8b284799, []int{8}
 }
 
 func (m *AppendTransactionArgs) XXX_Unmarshal(b []byte) error {
@@ -414,7 +588,7 @@ func (m *AppendTransactionRet) Reset()         { *m = AppendTransactionRet{} }
 func (m *AppendTransactionRet) String() string { return proto.CompactTextString(m) }
 func (*AppendTransactionRet) ProtoMessage()    {}
 func (*AppendTransactionRet) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{8}
+	return fileDescriptor_99e2a20f8b284799, []int{9}
 }
 
 func (m *AppendTransactionRet) XXX_Unmarshal(b []byte) error {
@@ -443,21 +617,23 @@ func (m *AppendTransactionRet) GetSuccess() bool {
 }
 
 type ProposeBlockArgs struct {
-	Credential           *SIGRet  `protobuf:"bytes,1,opt,name=credential,proto3" json:"credential,omitempty"`
-	Block                *Block   `protobuf:"bytes,2,opt,name=block,proto3" json:"block,omitempty"`
-	Value                string   `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
-	Round                int64    `protobuf:"varint,4,opt,name=round,proto3" json:"round,omitempty"`
-	Peer                 string   `protobuf:"bytes,5,opt,name=peer,proto3" json:"peer,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Credential *SIGRet `protobuf:"bytes,1,opt,name=credential,proto3" json:"credential,omitempty"`
+	Block      *Block  `protobuf:"bytes,2,opt,name=block,proto3" json:"block,omitempty"`
+	Value      string  `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Round      int64   `protobuf:"varint,4,opt,name=round,proto3" json:"round,omitempty"`
+	Peer       string  `protobuf:"bytes,5,opt,name=peer,proto3" json:"peer,omitempty"`
+	// The proposer's claim to the period, the same it gossiped ahead
+	Priority             *Priority `protobuf:"bytes,6,opt,name=priority,proto3" json:"priority,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
 }
 
 func (m *ProposeBlockArgs) Reset()         { *m = ProposeBlockArgs{} }
 func (m *ProposeBlockArgs) String() string { return proto.CompactTextString(m) }
 func (*ProposeBlockArgs) ProtoMessage()    {}
 func (*ProposeBlockArgs) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{9}
+	return fileDescriptor_99e2a20f8b284799, []int{10}
 }
 
 func (m *ProposeBlockArgs) XXX_Unmarshal(b []byte) error {
@@ -513,6 +689,13 @@ func (m *ProposeBlockArgs) GetPeer() string {
 	return ""
 }
 
+func (m *ProposeBlockArgs) GetPriority() *Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return nil
+}
+
 type ProposeBlockRet struct {
 	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -524,7 +707,7 @@ func (m *ProposeBlockRet) Reset()         { *m = ProposeBlockRet{} }
 func (m *ProposeBlockRet) String() string { return proto.CompactTextString(m) }
 func (*ProposeBlockRet) ProtoMessage()    {}
 func (*ProposeBlockRet) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{10}
+	return fileDescriptor_99e2a20f8b284799, []int{11}
 }
 
 func (m *ProposeBlockRet) XXX_Unmarshal(b []byte) error {
@@ -552,6 +735,176 @@ func (m *ProposeBlockRet) GetSuccess() bool {
 	return false
 }
 
+// A proposer's claim to lead a period, gossiped ahead of its block so that
+// peers learn the leader before the bodies arrive
+type Priority struct {
+	UserId string `protobuf:"bytes,1,opt,name=userId,proto3" json:"userId,omitempty"`
+	Round  int64  `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
+	Period int64  `protobuf:"varint,3,opt,name=period,proto3" json:"period,omitempty"`
+	// Hash of the proposed block
+	Value string `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	// Hex encoded VRF proof over the period's sortition seed
+	Proof string `protobuf:"bytes,5,opt,name=proof,proto3" json:"proof,omitempty"`
+	// Hex encoded, derived from the proof's output, the lowest leads
+	Priority             string   `protobuf:"bytes,6,opt,name=priority,proto3" json:"priority,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Priority) Reset()         { *m = Priority{} }
+func (m *Priority) String() string { return proto.CompactTextString(m) }
+func (*Priority) ProtoMessage()    {}
+func (*Priority) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{12}
+}
+
+func (m *Priority) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Priority.Unmarshal(m, b)
+}
+func (m *Priority) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Priority.Marshal(b, m, deterministic)
+}
+func (m *Priority) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Priority.Merge(m, src)
+}
+func (m *Priority) XXX_Size() int {
+	return xxx_messageInfo_Priority.Size(m)
+}
+func (m *Priority) XXX_DiscardUnknown() {
+	xxx_messageInfo_Priority.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Priority proto.InternalMessageInfo
+
+func (m *Priority) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *Priority) GetRound() int64 {
+	if m != nil {
+		return m.Round
+	}
+	return 0
+}
+
+func (m *Priority) GetPeriod() int64 {
+	if m != nil {
+		return m.Period
+	}
+	return 0
+}
+
+func (m *Priority) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *Priority) GetProof() string {
+	if m != nil {
+		return m.Proof
+	}
+	return ""
+}
+
+func (m *Priority) GetPriority() string {
+	if m != nil {
+		return m.Priority
+	}
+	return ""
+}
+
+type ProposePriorityArgs struct {
+	Priority             *Priority `protobuf:"bytes,1,opt,name=priority,proto3" json:"priority,omitempty"`
+	Peer                 string    `protobuf:"bytes,2,opt,name=peer,proto3" json:"peer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ProposePriorityArgs) Reset()         { *m = ProposePriorityArgs{} }
+func (m *ProposePriorityArgs) String() string { return proto.CompactTextString(m) }
+func (*ProposePriorityArgs) ProtoMessage()    {}
+func (*ProposePriorityArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{13}
+}
+
+func (m *ProposePriorityArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProposePriorityArgs.Unmarshal(m, b)
+}
+func (m *ProposePriorityArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProposePriorityArgs.Marshal(b, m, deterministic)
+}
+func (m *ProposePriorityArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProposePriorityArgs.Merge(m, src)
+}
+func (m *ProposePriorityArgs) XXX_Size() int {
+	return xxx_messageInfo_ProposePriorityArgs.Size(m)
+}
+func (m *ProposePriorityArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProposePriorityArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProposePriorityArgs proto.InternalMessageInfo
+
+func (m *ProposePriorityArgs) GetPriority() *Priority {
+	if m != nil {
+		return m.Priority
+	}
+	return nil
+}
+
+func (m *ProposePriorityArgs) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+type ProposePriorityRet struct {
+	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ProposePriorityRet) Reset()         { *m = ProposePriorityRet{} }
+func (m *ProposePriorityRet) String() string { return proto.CompactTextString(m) }
+func (*ProposePriorityRet) ProtoMessage()    {}
+func (*ProposePriorityRet) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{14}
+}
+
+func (m *ProposePriorityRet) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProposePriorityRet.Unmarshal(m, b)
+}
+func (m *ProposePriorityRet) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProposePriorityRet.Marshal(b, m, deterministic)
+}
+func (m *ProposePriorityRet) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProposePriorityRet.Merge(m, src)
+}
+func (m *ProposePriorityRet) XXX_Size() int {
+	return xxx_messageInfo_ProposePriorityRet.Size(m)
+}
+func (m *ProposePriorityRet) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProposePriorityRet.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProposePriorityRet proto.InternalMessageInfo
+
+func (m *ProposePriorityRet) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
 type VoteArgs struct {
 	Message              *SIGRet  `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
 	Round                int64    `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
@@ -565,7 +918,7 @@ func (m *VoteArgs) Reset()         { *m = VoteArgs{} }
 func (m *VoteArgs) String() string { return proto.CompactTextString(m) }
 func (*VoteArgs) ProtoMessage()    {}
 func (*VoteArgs) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{11}
+	return fileDescriptor_99e2a20f8b284799, []int{15}
 }
 
 func (m *VoteArgs) XXX_Unmarshal(b []byte) error {
@@ -618,7 +971,7 @@ func (m *VoteRet) Reset()         { *m = VoteRet{} }
 func (m *VoteRet) String() string { return proto.CompactTextString(m) }
 func (*VoteRet) ProtoMessage()    {}
 func (*VoteRet) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{12}
+	return fileDescriptor_99e2a20f8b284799, []int{16}
 }
 
 func (m *VoteRet) XXX_Unmarshal(b []byte) error {
@@ -647,19 +1000,23 @@ func (m *VoteRet) GetSuccess() bool {
 }
 
 type SIGRet struct {
-	UserId               string   `protobuf:"bytes,1,opt,name=userId,proto3" json:"userId,omitempty"`
-	Message              []string `protobuf:"bytes,2,rep,name=message,proto3" json:"message,omitempty"`
-	SignedMessage        string   `protobuf:"bytes,3,opt,name=signedMessage,proto3" json:"signedMessage,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	UserId        string   `protobuf:"bytes,1,opt,name=userId,proto3" json:"userId,omitempty"`
+	Message       []string `protobuf:"bytes,2,rep,name=message,proto3" json:"message,omitempty"`
+	SignedMessage string   `protobuf:"bytes,3,opt,name=signedMessage,proto3" json:"signedMessage,omitempty"`
+	// hex encoded signature over agreement.VoteSigningBytes by the one-time
+	// key the user's participation key set up for the round
+	Signature            string        `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	Ephemeral            *EphemeralKey `protobuf:"bytes,5,opt,name=ephemeral,proto3" json:"ephemeral,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
 }
 
 func (m *SIGRet) Reset()         { *m = SIGRet{} }
 func (m *SIGRet) String() string { return proto.CompactTextString(m) }
 func (*SIGRet) ProtoMessage()    {}
 func (*SIGRet) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{13}
+	return fileDescriptor_99e2a20f8b284799, []int{17}
 }
 
 func (m *SIGRet) XXX_Unmarshal(b []byte) error {
@@ -701,267 +1058,2520 @@ func (m *SIGRet) GetSignedMessage() string {
 	return ""
 }
 
-type RequestBlockChainArgs struct {
-	Peer                 string   `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"`
+func (m *SIGRet) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+func (m *SIGRet) GetEphemeral() *EphemeralKey {
+	if m != nil {
+		return m.Ephemeral
+	}
+	return nil
+}
+
+// The one-time key a vote is signed with and the signatures that tie it to
+// the voter's registered participation key, see package participation.
+type EphemeralKey struct {
+	// hex encoded ed25519 public keys
+	Key      string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	BatchKey string `protobuf:"bytes,2,opt,name=batchKey,proto3" json:"batchKey,omitempty"`
+	// by the participation key over the batch key, hex encoded
+	BatchSignature string `protobuf:"bytes,3,opt,name=batchSignature,proto3" json:"batchSignature,omitempty"`
+	// by the batch key over the one-time key, hex encoded
+	Signature            string   `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *RequestBlockChainArgs) Reset()         { *m = RequestBlockChainArgs{} }
-func (m *RequestBlockChainArgs) String() string { return proto.CompactTextString(m) }
-func (*RequestBlockChainArgs) ProtoMessage()    {}
-func (*RequestBlockChainArgs) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{14}
+func (m *EphemeralKey) Reset()         { *m = EphemeralKey{} }
+func (m *EphemeralKey) String() string { return proto.CompactTextString(m) }
+func (*EphemeralKey) ProtoMessage()    {}
+func (*EphemeralKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{18}
+}
+
+func (m *EphemeralKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EphemeralKey.Unmarshal(m, b)
+}
+func (m *EphemeralKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EphemeralKey.Marshal(b, m, deterministic)
+}
+func (m *EphemeralKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EphemeralKey.Merge(m, src)
+}
+func (m *EphemeralKey) XXX_Size() int {
+	return xxx_messageInfo_EphemeralKey.Size(m)
+}
+func (m *EphemeralKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_EphemeralKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EphemeralKey proto.InternalMessageInfo
+
+func (m *EphemeralKey) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *EphemeralKey) GetBatchKey() string {
+	if m != nil {
+		return m.BatchKey
+	}
+	return ""
+}
+
+func (m *EphemeralKey) GetBatchSignature() string {
+	if m != nil {
+		return m.BatchSignature
+	}
+	return ""
+}
+
+func (m *EphemeralKey) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+// A participation key as kept in a keystore, only the one-time keys of
+// rounds to come. See package participation.
+type ParticipationKeyFile struct {
+	// hex encoded ed25519 public key, the one registered on chain
+	Root       string `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	FirstRound int64  `protobuf:"varint,2,opt,name=firstRound,proto3" json:"firstRound,omitempty"`
+	LastRound  int64  `protobuf:"varint,3,opt,name=lastRound,proto3" json:"lastRound,omitempty"`
+	// keys of rounds before this one are erased
+	Next                 int64                    `protobuf:"varint,4,opt,name=next,proto3" json:"next,omitempty"`
+	Batches              []*ParticipationBatch    `protobuf:"bytes,5,rep,name=batches,proto3" json:"batches,omitempty"`
+	Rounds               []*ParticipationRoundKey `protobuf:"bytes,6,rep,name=rounds,proto3" json:"rounds,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                 `json:"-"`
+	XXX_unrecognized     []byte                   `json:"-"`
+	XXX_sizecache        int32                    `json:"-"`
+}
+
+func (m *ParticipationKeyFile) Reset()         { *m = ParticipationKeyFile{} }
+func (m *ParticipationKeyFile) String() string { return proto.CompactTextString(m) }
+func (*ParticipationKeyFile) ProtoMessage()    {}
+func (*ParticipationKeyFile) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{19}
+}
+
+func (m *ParticipationKeyFile) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ParticipationKeyFile.Unmarshal(m, b)
+}
+func (m *ParticipationKeyFile) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ParticipationKeyFile.Marshal(b, m, deterministic)
+}
+func (m *ParticipationKeyFile) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ParticipationKeyFile.Merge(m, src)
+}
+func (m *ParticipationKeyFile) XXX_Size() int {
+	return xxx_messageInfo_ParticipationKeyFile.Size(m)
+}
+func (m *ParticipationKeyFile) XXX_DiscardUnknown() {
+	xxx_messageInfo_ParticipationKeyFile.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ParticipationKeyFile proto.InternalMessageInfo
+
+func (m *ParticipationKeyFile) GetRoot() string {
+	if m != nil {
+		return m.Root
+	}
+	return ""
+}
+
+func (m *ParticipationKeyFile) GetFirstRound() int64 {
+	if m != nil {
+		return m.FirstRound
+	}
+	return 0
+}
+
+func (m *ParticipationKeyFile) GetLastRound() int64 {
+	if m != nil {
+		return m.LastRound
+	}
+	return 0
+}
+
+func (m *ParticipationKeyFile) GetNext() int64 {
+	if m != nil {
+		return m.Next
+	}
+	return 0
+}
+
+func (m *ParticipationKeyFile) GetBatches() []*ParticipationBatch {
+	if m != nil {
+		return m.Batches
+	}
+	return nil
+}
+
+func (m *ParticipationKeyFile) GetRounds() []*ParticipationRoundKey {
+	if m != nil {
+		return m.Rounds
+	}
+	return nil
+}
+
+type ParticipationBatch struct {
+	Batch int64 `protobuf:"varint,1,opt,name=batch,proto3" json:"batch,omitempty"`
+	// hex encoded ed25519 seed
+	Seed string `protobuf:"bytes,2,opt,name=seed,proto3" json:"seed,omitempty"`
+	// by the participation key over the batch key, hex encoded
+	Signature            string   `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ParticipationBatch) Reset()         { *m = ParticipationBatch{} }
+func (m *ParticipationBatch) String() string { return proto.CompactTextString(m) }
+func (*ParticipationBatch) ProtoMessage()    {}
+func (*ParticipationBatch) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{20}
+}
+
+func (m *ParticipationBatch) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ParticipationBatch.Unmarshal(m, b)
+}
+func (m *ParticipationBatch) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ParticipationBatch.Marshal(b, m, deterministic)
+}
+func (m *ParticipationBatch) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ParticipationBatch.Merge(m, src)
+}
+func (m *ParticipationBatch) XXX_Size() int {
+	return xxx_messageInfo_ParticipationBatch.Size(m)
+}
+func (m *ParticipationBatch) XXX_DiscardUnknown() {
+	xxx_messageInfo_ParticipationBatch.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ParticipationBatch proto.InternalMessageInfo
+
+func (m *ParticipationBatch) GetBatch() int64 {
+	if m != nil {
+		return m.Batch
+	}
+	return 0
+}
+
+func (m *ParticipationBatch) GetSeed() string {
+	if m != nil {
+		return m.Seed
+	}
+	return ""
+}
+
+func (m *ParticipationBatch) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+type ParticipationRoundKey struct {
+	Round int64 `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	// hex encoded ed25519 seed
+	Seed string `protobuf:"bytes,2,opt,name=seed,proto3" json:"seed,omitempty"`
+	// by the batch key over the round key, hex encoded
+	Signature            string   `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	BatchKey             string   `protobuf:"bytes,4,opt,name=batchKey,proto3" json:"batchKey,omitempty"`
+	BatchSignature       string   `protobuf:"bytes,5,opt,name=batchSignature,proto3" json:"batchSignature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ParticipationRoundKey) Reset()         { *m = ParticipationRoundKey{} }
+func (m *ParticipationRoundKey) String() string { return proto.CompactTextString(m) }
+func (*ParticipationRoundKey) ProtoMessage()    {}
+func (*ParticipationRoundKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{21}
+}
+
+func (m *ParticipationRoundKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ParticipationRoundKey.Unmarshal(m, b)
+}
+func (m *ParticipationRoundKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ParticipationRoundKey.Marshal(b, m, deterministic)
+}
+func (m *ParticipationRoundKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ParticipationRoundKey.Merge(m, src)
+}
+func (m *ParticipationRoundKey) XXX_Size() int {
+	return xxx_messageInfo_ParticipationRoundKey.Size(m)
+}
+func (m *ParticipationRoundKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_ParticipationRoundKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ParticipationRoundKey proto.InternalMessageInfo
+
+func (m *ParticipationRoundKey) GetRound() int64 {
+	if m != nil {
+		return m.Round
+	}
+	return 0
+}
+
+func (m *ParticipationRoundKey) GetSeed() string {
+	if m != nil {
+		return m.Seed
+	}
+	return ""
+}
+
+func (m *ParticipationRoundKey) GetSignature() string {
+	if m != nil {
+		return m.Signature
+	}
+	return ""
+}
+
+func (m *ParticipationRoundKey) GetBatchKey() string {
+	if m != nil {
+		return m.BatchKey
+	}
+	return ""
+}
+
+func (m *ParticipationRoundKey) GetBatchSignature() string {
+	if m != nil {
+		return m.BatchSignature
+	}
+	return ""
+}
+
+// Two votes signed by the same key for different values in the same round,
+// period and step. Either one on its own is a valid vote, together they prove
+// the key equivocated.
+type Evidence struct {
+	UserId               string   `protobuf:"bytes,1,opt,name=userId,proto3" json:"userId,omitempty"`
+	Round                int64    `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
+	Period               int64    `protobuf:"varint,3,opt,name=period,proto3" json:"period,omitempty"`
+	Step                 int64    `protobuf:"varint,4,opt,name=step,proto3" json:"step,omitempty"`
+	First                *SIGRet  `protobuf:"bytes,5,opt,name=first,proto3" json:"first,omitempty"`
+	Second               *SIGRet  `protobuf:"bytes,6,opt,name=second,proto3" json:"second,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Evidence) Reset()         { *m = Evidence{} }
+func (m *Evidence) String() string { return proto.CompactTextString(m) }
+func (*Evidence) ProtoMessage()    {}
+func (*Evidence) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{22}
+}
+
+func (m *Evidence) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Evidence.Unmarshal(m, b)
+}
+func (m *Evidence) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Evidence.Marshal(b, m, deterministic)
+}
+func (m *Evidence) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Evidence.Merge(m, src)
+}
+func (m *Evidence) XXX_Size() int {
+	return xxx_messageInfo_Evidence.Size(m)
+}
+func (m *Evidence) XXX_DiscardUnknown() {
+	xxx_messageInfo_Evidence.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Evidence proto.InternalMessageInfo
+
+func (m *Evidence) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *Evidence) GetRound() int64 {
+	if m != nil {
+		return m.Round
+	}
+	return 0
+}
+
+func (m *Evidence) GetPeriod() int64 {
+	if m != nil {
+		return m.Period
+	}
+	return 0
+}
+
+func (m *Evidence) GetStep() int64 {
+	if m != nil {
+		return m.Step
+	}
+	return 0
+}
+
+func (m *Evidence) GetFirst() *SIGRet {
+	if m != nil {
+		return m.First
+	}
+	return nil
+}
+
+func (m *Evidence) GetSecond() *SIGRet {
+	if m != nil {
+		return m.Second
+	}
+	return nil
+}
+
+type EvidenceList struct {
+	Evidence             []*Evidence `protobuf:"bytes,1,rep,name=evidence,proto3" json:"evidence,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *EvidenceList) Reset()         { *m = EvidenceList{} }
+func (m *EvidenceList) String() string { return proto.CompactTextString(m) }
+func (*EvidenceList) ProtoMessage()    {}
+func (*EvidenceList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{23}
+}
+
+func (m *EvidenceList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EvidenceList.Unmarshal(m, b)
+}
+func (m *EvidenceList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EvidenceList.Marshal(b, m, deterministic)
+}
+func (m *EvidenceList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EvidenceList.Merge(m, src)
+}
+func (m *EvidenceList) XXX_Size() int {
+	return xxx_messageInfo_EvidenceList.Size(m)
+}
+func (m *EvidenceList) XXX_DiscardUnknown() {
+	xxx_messageInfo_EvidenceList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EvidenceList proto.InternalMessageInfo
+
+func (m *EvidenceList) GetEvidence() []*Evidence {
+	if m != nil {
+		return m.Evidence
+	}
+	return nil
+}
+
+// 2t+1 cert votes for value in one period of a round, the proof that the
+// round decided it. Value is a block hash, or "empty" for the empty block.
+type Certificate struct {
+	Round                int64     `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Period               int64     `protobuf:"varint,2,opt,name=period,proto3" json:"period,omitempty"`
+	Value                string    `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Votes                []*SIGRet `protobuf:"bytes,4,rep,name=votes,proto3" json:"votes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *Certificate) Reset()         { *m = Certificate{} }
+func (m *Certificate) String() string { return proto.CompactTextString(m) }
+func (*Certificate) ProtoMessage()    {}
+func (*Certificate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{24}
+}
+
+func (m *Certificate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Certificate.Unmarshal(m, b)
+}
+func (m *Certificate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Certificate.Marshal(b, m, deterministic)
+}
+func (m *Certificate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Certificate.Merge(m, src)
+}
+func (m *Certificate) XXX_Size() int {
+	return xxx_messageInfo_Certificate.Size(m)
+}
+func (m *Certificate) XXX_DiscardUnknown() {
+	xxx_messageInfo_Certificate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Certificate proto.InternalMessageInfo
+
+func (m *Certificate) GetRound() int64 {
+	if m != nil {
+		return m.Round
+	}
+	return 0
+}
+
+func (m *Certificate) GetPeriod() int64 {
+	if m != nil {
+		return m.Period
+	}
+	return 0
+}
+
+func (m *Certificate) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *Certificate) GetVotes() []*SIGRet {
+	if m != nil {
+		return m.Votes
+	}
+	return nil
+}
+
+// A block is final once it, or a later block that links back to it by
+// hash, was certified. Blocks adopted from a peer without a certificate are
+// tentative until one arrives.
+type BlockStatus struct {
+	Round                int64    `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Hash                 string   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Final                bool     `protobuf:"varint,3,opt,name=final,proto3" json:"final,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlockStatus) Reset()         { *m = BlockStatus{} }
+func (m *BlockStatus) String() string { return proto.CompactTextString(m) }
+func (*BlockStatus) ProtoMessage()    {}
+func (*BlockStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{25}
+}
+
+func (m *BlockStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockStatus.Unmarshal(m, b)
+}
+func (m *BlockStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockStatus.Marshal(b, m, deterministic)
+}
+func (m *BlockStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockStatus.Merge(m, src)
+}
+func (m *BlockStatus) XXX_Size() int {
+	return xxx_messageInfo_BlockStatus.Size(m)
+}
+func (m *BlockStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockStatus proto.InternalMessageInfo
+
+func (m *BlockStatus) GetRound() int64 {
+	if m != nil {
+		return m.Round
+	}
+	return 0
+}
+
+func (m *BlockStatus) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *BlockStatus) GetFinal() bool {
+	if m != nil {
+		return m.Final
+	}
+	return false
+}
+
+type Finality struct {
+	// Every block up to this round is final, the genesis block always is
+	FinalRound           int64          `protobuf:"varint,1,opt,name=finalRound,proto3" json:"finalRound,omitempty"`
+	Blocks               []*BlockStatus `protobuf:"bytes,2,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *Finality) Reset()         { *m = Finality{} }
+func (m *Finality) String() string { return proto.CompactTextString(m) }
+func (*Finality) ProtoMessage()    {}
+func (*Finality) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{26}
+}
+
+func (m *Finality) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Finality.Unmarshal(m, b)
+}
+func (m *Finality) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Finality.Marshal(b, m, deterministic)
+}
+func (m *Finality) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Finality.Merge(m, src)
+}
+func (m *Finality) XXX_Size() int {
+	return xxx_messageInfo_Finality.Size(m)
+}
+func (m *Finality) XXX_DiscardUnknown() {
+	xxx_messageInfo_Finality.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Finality proto.InternalMessageInfo
+
+func (m *Finality) GetFinalRound() int64 {
+	if m != nil {
+		return m.FinalRound
+	}
+	return 0
+}
+
+func (m *Finality) GetBlocks() []*BlockStatus {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+type GetProposalArgs struct {
+	Round                int64    `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Period               int64    `protobuf:"varint,2,opt,name=period,proto3" json:"period,omitempty"`
+	Value                string   `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Peer                 string   `protobuf:"bytes,4,opt,name=peer,proto3" json:"peer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetProposalArgs) Reset()         { *m = GetProposalArgs{} }
+func (m *GetProposalArgs) String() string { return proto.CompactTextString(m) }
+func (*GetProposalArgs) ProtoMessage()    {}
+func (*GetProposalArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{27}
+}
+
+func (m *GetProposalArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetProposalArgs.Unmarshal(m, b)
+}
+func (m *GetProposalArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetProposalArgs.Marshal(b, m, deterministic)
+}
+func (m *GetProposalArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetProposalArgs.Merge(m, src)
+}
+func (m *GetProposalArgs) XXX_Size() int {
+	return xxx_messageInfo_GetProposalArgs.Size(m)
+}
+func (m *GetProposalArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetProposalArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetProposalArgs proto.InternalMessageInfo
+
+func (m *GetProposalArgs) GetRound() int64 {
+	if m != nil {
+		return m.Round
+	}
+	return 0
+}
+
+func (m *GetProposalArgs) GetPeriod() int64 {
+	if m != nil {
+		return m.Period
+	}
+	return 0
+}
+
+func (m *GetProposalArgs) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *GetProposalArgs) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+type GetProposalRet struct {
+	Found                bool     `protobuf:"varint,1,opt,name=found,proto3" json:"found,omitempty"`
+	Block                *Block   `protobuf:"bytes,2,opt,name=block,proto3" json:"block,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetProposalRet) Reset()         { *m = GetProposalRet{} }
+func (m *GetProposalRet) String() string { return proto.CompactTextString(m) }
+func (*GetProposalRet) ProtoMessage()    {}
+func (*GetProposalRet) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{28}
+}
+
+func (m *GetProposalRet) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetProposalRet.Unmarshal(m, b)
+}
+func (m *GetProposalRet) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetProposalRet.Marshal(b, m, deterministic)
+}
+func (m *GetProposalRet) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetProposalRet.Merge(m, src)
+}
+func (m *GetProposalRet) XXX_Size() int {
+	return xxx_messageInfo_GetProposalRet.Size(m)
+}
+func (m *GetProposalRet) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetProposalRet.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetProposalRet proto.InternalMessageInfo
+
+func (m *GetProposalRet) GetFound() bool {
+	if m != nil {
+		return m.Found
+	}
+	return false
+}
+
+func (m *GetProposalRet) GetBlock() *Block {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+type RequestBlockChainArgs struct {
+	Peer                 string   `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestBlockChainArgs) Reset()         { *m = RequestBlockChainArgs{} }
+func (m *RequestBlockChainArgs) String() string { return proto.CompactTextString(m) }
+func (*RequestBlockChainArgs) ProtoMessage()    {}
+func (*RequestBlockChainArgs) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{29}
+}
+
+func (m *RequestBlockChainArgs) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RequestBlockChainArgs.Unmarshal(m, b)
+}
+func (m *RequestBlockChainArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RequestBlockChainArgs.Marshal(b, m, deterministic)
+}
+func (m *RequestBlockChainArgs) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestBlockChainArgs.Merge(m, src)
+}
+func (m *RequestBlockChainArgs) XXX_Size() int {
+	return xxx_messageInfo_RequestBlockChainArgs.Size(m)
+}
+func (m *RequestBlockChainArgs) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestBlockChainArgs.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestBlockChainArgs proto.InternalMessageInfo
+
+func (m *RequestBlockChainArgs) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+type RequestBlockChainRet struct {
+	Peer       string   `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"`
+	Blockchain []*Block `protobuf:"bytes,2,rep,name=blockchain,proto3" json:"blockchain,omitempty"`
+	// Certificates for the blocks the peer has one for, in round order
+	Certificates         []*Certificate `protobuf:"bytes,3,rep,name=certificates,proto3" json:"certificates,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *RequestBlockChainRet) Reset()         { *m = RequestBlockChainRet{} }
+func (m *RequestBlockChainRet) String() string { return proto.CompactTextString(m) }
+func (*RequestBlockChainRet) ProtoMessage()    {}
+func (*RequestBlockChainRet) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{30}
+}
+
+func (m *RequestBlockChainRet) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RequestBlockChainRet.Unmarshal(m, b)
+}
+func (m *RequestBlockChainRet) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RequestBlockChainRet.Marshal(b, m, deterministic)
+}
+func (m *RequestBlockChainRet) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestBlockChainRet.Merge(m, src)
+}
+func (m *RequestBlockChainRet) XXX_Size() int {
+	return xxx_messageInfo_RequestBlockChainRet.Size(m)
+}
+func (m *RequestBlockChainRet) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestBlockChainRet.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestBlockChainRet proto.InternalMessageInfo
+
+func (m *RequestBlockChainRet) GetPeer() string {
+	if m != nil {
+		return m.Peer
+	}
+	return ""
+}
+
+func (m *RequestBlockChainRet) GetBlockchain() []*Block {
+	if m != nil {
+		return m.Blockchain
+	}
+	return nil
+}
+
+func (m *RequestBlockChainRet) GetCertificates() []*Certificate {
+	if m != nil {
+		return m.Certificates
+	}
+	return nil
+}
+
+type Blockchain struct {
+	Blocks               []*Block `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Blockchain) Reset()         { *m = Blockchain{} }
+func (m *Blockchain) String() string { return proto.CompactTextString(m) }
+func (*Blockchain) ProtoMessage()    {}
+func (*Blockchain) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{31}
+}
+
+func (m *Blockchain) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Blockchain.Unmarshal(m, b)
+}
+func (m *Blockchain) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Blockchain.Marshal(b, m, deterministic)
+}
+func (m *Blockchain) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Blockchain.Merge(m, src)
+}
+func (m *Blockchain) XXX_Size() int {
+	return xxx_messageInfo_Blockchain.Size(m)
+}
+func (m *Blockchain) XXX_DiscardUnknown() {
+	xxx_messageInfo_Blockchain.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Blockchain proto.InternalMessageInfo
+
+func (m *Blockchain) GetBlocks() []*Block {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+type Result struct {
+	// Types that are valid to be assigned to Result:
+	//	*Result_Bc
+	//	*Result_S
+	//	*Result_Evidence
+	//	*Result_Finality
+	Result               isResult_Result `protobuf_oneof:"result"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *Result) Reset()         { *m = Result{} }
+func (m *Result) String() string { return proto.CompactTextString(m) }
+func (*Result) ProtoMessage()    {}
+func (*Result) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{32}
+}
+
+func (m *Result) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Result.Unmarshal(m, b)
+}
+func (m *Result) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Result.Marshal(b, m, deterministic)
+}
+func (m *Result) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Result.Merge(m, src)
+}
+func (m *Result) XXX_Size() int {
+	return xxx_messageInfo_Result.Size(m)
+}
+func (m *Result) XXX_DiscardUnknown() {
+	xxx_messageInfo_Result.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Result proto.InternalMessageInfo
+
+type isResult_Result interface {
+	isResult_Result()
+}
+
+type Result_Bc struct {
+	Bc *Blockchain `protobuf:"bytes,1,opt,name=bc,proto3,oneof"`
+}
+
+type Result_S struct {
+	S *Success `protobuf:"bytes,2,opt,name=s,proto3,oneof"`
+}
+
+type Result_Evidence struct {
+	Evidence *EvidenceList `protobuf:"bytes,3,opt,name=evidence,proto3,oneof"`
+}
+
+type Result_Finality struct {
+	Finality *Finality `protobuf:"bytes,4,opt,name=finality,proto3,oneof"`
+}
+
+func (*Result_Bc) isResult_Result() {}
+
+func (*Result_S) isResult_Result() {}
+
+func (*Result_Evidence) isResult_Result() {}
+
+func (*Result_Finality) isResult_Result() {}
+
+func (m *Result) GetResult() isResult_Result {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *Result) GetBc() *Blockchain {
+	if x, ok := m.GetResult().(*Result_Bc); ok {
+		return x.Bc
+	}
+	return nil
+}
+
+func (m *Result) GetS() *Success {
+	if x, ok := m.GetResult().(*Result_S); ok {
+		return x.S
+	}
+	return nil
+}
+
+func (m *Result) GetEvidence() *EvidenceList {
+	if x, ok := m.GetResult().(*Result_Evidence); ok {
+		return x.Evidence
+	}
+	return nil
+}
+
+func (m *Result) GetFinality() *Finality {
+	if x, ok := m.GetResult().(*Result_Finality); ok {
+		return x.Finality
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*Result) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _Result_OneofMarshaler, _Result_OneofUnmarshaler, _Result_OneofSizer, []interface{}{
+		(*Result_Bc)(nil),
+		(*Result_S)(nil),
+		(*Result_Evidence)(nil),
+		(*Result_Finality)(nil),
+	}
+}
+
+func _Result_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*Result)
+	// result
+	switch x := m.Result.(type) {
+	case *Result_Bc:
+		b.EncodeVarint(1<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Bc); err != nil {
+			return err
+		}
+	case *Result_S:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.S); err != nil {
+			return err
+		}
+	case *Result_Evidence:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Evidence); err != nil {
+			return err
+		}
+	case *Result_Finality:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Finality); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("Result.Result has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _Result_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*Result)
+	switch tag {
+	case 1: // result.bc
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Blockchain)
+		err := b.DecodeMessage(msg)
+		m.Result = &Result_Bc{msg}
+		return true, err
+	case 2: // result.s
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Success)
+		err := b.DecodeMessage(msg)
+		m.Result = &Result_S{msg}
+		return true, err
+	case 3: // result.evidence
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(EvidenceList)
+		err := b.DecodeMessage(msg)
+		m.Result = &Result_Evidence{msg}
+		return true, err
+	case 4: // result.finality
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Finality)
+		err := b.DecodeMessage(msg)
+		m.Result = &Result_Finality{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _Result_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*Result)
+	// result
+	switch x := m.Result.(type) {
+	case *Result_Bc:
+		s := proto.Size(x.Bc)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Result_S:
+		s := proto.Size(x.S)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Result_Evidence:
+		s := proto.Size(x.Evidence)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Result_Finality:
+		s := proto.Size(x.Finality)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+// A type for arguments across all operations
+type Command struct {
+	Operation Op `protobuf:"varint,1,opt,name=operation,proto3,enum=pb.Op" json:"operation,omitempty"`
+	// Types that are valid to be assigned to Arg:
+	//	*Command_Empty
+	//	*Command_Tx
+	Arg                  isCommand_Arg `protobuf_oneof:"arg"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *Command) Reset()         { *m = Command{} }
+func (m *Command) String() string { return proto.CompactTextString(m) }
+func (*Command) ProtoMessage()    {}
+func (*Command) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{33}
+}
+
+func (m *Command) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Command.Unmarshal(m, b)
+}
+func (m *Command) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Command.Marshal(b, m, deterministic)
+}
+func (m *Command) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Command.Merge(m, src)
+}
+func (m *Command) XXX_Size() int {
+	return xxx_messageInfo_Command.Size(m)
+}
+func (m *Command) XXX_DiscardUnknown() {
+	xxx_messageInfo_Command.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Command proto.InternalMessageInfo
+
+func (m *Command) GetOperation() Op {
+	if m != nil {
+		return m.Operation
+	}
+	return Op_GET
+}
+
+type isCommand_Arg interface {
+	isCommand_Arg()
+}
+
+type Command_Empty struct {
+	Empty *Empty `protobuf:"bytes,2,opt,name=empty,proto3,oneof"`
+}
+
+type Command_Tx struct {
+	Tx *Transaction `protobuf:"bytes,3,opt,name=tx,proto3,oneof"`
+}
+
+func (*Command_Empty) isCommand_Arg() {}
+
+func (*Command_Tx) isCommand_Arg() {}
+
+func (m *Command) GetArg() isCommand_Arg {
+	if m != nil {
+		return m.Arg
+	}
+	return nil
+}
+
+func (m *Command) GetEmpty() *Empty {
+	if x, ok := m.GetArg().(*Command_Empty); ok {
+		return x.Empty
+	}
+	return nil
+}
+
+func (m *Command) GetTx() *Transaction {
+	if x, ok := m.GetArg().(*Command_Tx); ok {
+		return x.Tx
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*Command) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _Command_OneofMarshaler, _Command_OneofUnmarshaler, _Command_OneofSizer, []interface{}{
+		(*Command_Empty)(nil),
+		(*Command_Tx)(nil),
+	}
+}
+
+func _Command_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*Command)
+	// arg
+	switch x := m.Arg.(type) {
+	case *Command_Empty:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Empty); err != nil {
+			return err
+		}
+	case *Command_Tx:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Tx); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("Command.Arg has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _Command_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*Command)
+	switch tag {
+	case 2: // arg.empty
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Empty)
+		err := b.DecodeMessage(msg)
+		m.Arg = &Command_Empty{msg}
+		return true, err
+	case 3: // arg.tx
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(Transaction)
+		err := b.DecodeMessage(msg)
+		m.Arg = &Command_Tx{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _Command_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*Command)
+	// arg
+	switch x := m.Arg.(type) {
+	case *Command_Empty:
+		s := proto.Size(x.Empty)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Command_Tx:
+		s := proto.Size(x.Tx)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+type Status struct {
+	UserId string `protobuf:"bytes,1,opt,name=userId,proto3" json:"userId,omitempty"`
+	Round  int64  `protobuf:"varint,2,opt,name=round,proto3" json:"round,omitempty"`
+	Period int64  `protobuf:"varint,3,opt,name=period,proto3" json:"period,omitempty"`
+	Step   int64  `protobuf:"varint,4,opt,name=step,proto3" json:"step,omitempty"`
+	// Blocks in the chain, including genesis
+	ChainLength   int64  `protobuf:"varint,5,opt,name=chainLength,proto3" json:"chainLength,omitempty"`
+	LastBlockHash string `protobuf:"bytes,6,opt,name=lastBlockHash,proto3" json:"lastBlockHash,omitempty"`
+	// Protocol version the current round runs
+	Protocol string `protobuf:"bytes,7,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// True if the node stopped taking part in agreement
+	Halted        bool  `protobuf:"varint,8,opt,name=halted,proto3" json:"halted,omitempty"`
+	MempoolSize   int64 `protobuf:"varint,9,opt,name=mempoolSize,proto3" json:"mempoolSize,omitempty"`
+	RequiredVotes int64 `protobuf:"varint,10,opt,name=requiredVotes,proto3" json:"requiredVotes,omitempty"`
+	// Every block up to this round is final, see Finality
+	FinalRound           int64    `protobuf:"varint,11,opt,name=finalRound,proto3" json:"finalRound,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Status) Reset()         { *m = Status{} }
+func (m *Status) String() string { return proto.CompactTextString(m) }
+func (*Status) ProtoMessage()    {}
+func (*Status) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{34}
+}
+
+func (m *Status) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Status.Unmarshal(m, b)
+}
+func (m *Status) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Status.Marshal(b, m, deterministic)
+}
+func (m *Status) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Status.Merge(m, src)
+}
+func (m *Status) XXX_Size() int {
+	return xxx_messageInfo_Status.Size(m)
+}
+func (m *Status) XXX_DiscardUnknown() {
+	xxx_messageInfo_Status.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Status proto.InternalMessageInfo
+
+func (m *Status) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *Status) GetRound() int64 {
+	if m != nil {
+		return m.Round
+	}
+	return 0
+}
+
+func (m *Status) GetPeriod() int64 {
+	if m != nil {
+		return m.Period
+	}
+	return 0
+}
+
+func (m *Status) GetStep() int64 {
+	if m != nil {
+		return m.Step
+	}
+	return 0
+}
+
+func (m *Status) GetChainLength() int64 {
+	if m != nil {
+		return m.ChainLength
+	}
+	return 0
+}
+
+func (m *Status) GetLastBlockHash() string {
+	if m != nil {
+		return m.LastBlockHash
+	}
+	return ""
+}
+
+func (m *Status) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *Status) GetHalted() bool {
+	if m != nil {
+		return m.Halted
+	}
+	return false
+}
+
+func (m *Status) GetMempoolSize() int64 {
+	if m != nil {
+		return m.MempoolSize
+	}
+	return 0
+}
+
+func (m *Status) GetRequiredVotes() int64 {
+	if m != nil {
+		return m.RequiredVotes
+	}
+	return 0
+}
+
+func (m *Status) GetFinalRound() int64 {
+	if m != nil {
+		return m.FinalRound
+	}
+	return 0
+}
+
+type PeerInfo struct {
+	Address string `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	UserId  string `protobuf:"bytes,2,opt,name=userId,proto3" json:"userId,omitempty"`
+	Stake   int64  `protobuf:"varint,3,opt,name=stake,proto3" json:"stake,omitempty"`
+	// State of the gRPC connection, e.g. READY or TRANSIENT_FAILURE
+	State string `protobuf:"bytes,4,opt,name=state,proto3" json:"state,omitempty"`
+	// True if the last request to the peer succeeded
+	Connected bool `protobuf:"varint,5,opt,name=connected,proto3" json:"connected,omitempty"`
+	// Unix time of the last successful request, 0 if there was none
+	LastSeen  int64  `protobuf:"varint,6,opt,name=lastSeen,proto3" json:"lastSeen,omitempty"`
+	LastError string `protobuf:"bytes,7,opt,name=lastError,proto3" json:"lastError,omitempty"`
+	// Circuit breaker: closed, open while we pause requests to a failing peer, half-open while probing it
+	Circuit string `protobuf:"bytes,8,opt,name=circuit,proto3" json:"circuit,omitempty"`
+	// Requests waiting in the peer's send queue and in flight to it
+	Queued               int64    `protobuf:"varint,9,opt,name=queued,proto3" json:"queued,omitempty"`
+	InFlight             int64    `protobuf:"varint,10,opt,name=inFlight,proto3" json:"inFlight,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PeerInfo) Reset()         { *m = PeerInfo{} }
+func (m *PeerInfo) String() string { return proto.CompactTextString(m) }
+func (*PeerInfo) ProtoMessage()    {}
+func (*PeerInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{35}
+}
+
+func (m *PeerInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PeerInfo.Unmarshal(m, b)
+}
+func (m *PeerInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PeerInfo.Marshal(b, m, deterministic)
+}
+func (m *PeerInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeerInfo.Merge(m, src)
+}
+func (m *PeerInfo) XXX_Size() int {
+	return xxx_messageInfo_PeerInfo.Size(m)
+}
+func (m *PeerInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeerInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeerInfo proto.InternalMessageInfo
+
+func (m *PeerInfo) GetAddress() string {
+	if m != nil {
+		return m.Address
+	}
+	return ""
+}
+
+func (m *PeerInfo) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
+}
+
+func (m *PeerInfo) GetStake() int64 {
+	if m != nil {
+		return m.Stake
+	}
+	return 0
+}
+
+func (m *PeerInfo) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *PeerInfo) GetConnected() bool {
+	if m != nil {
+		return m.Connected
+	}
+	return false
+}
+
+func (m *PeerInfo) GetLastSeen() int64 {
+	if m != nil {
+		return m.LastSeen
+	}
+	return 0
+}
+
+func (m *PeerInfo) GetLastError() string {
+	if m != nil {
+		return m.LastError
+	}
+	return ""
+}
+
+func (m *PeerInfo) GetCircuit() string {
+	if m != nil {
+		return m.Circuit
+	}
+	return ""
+}
+
+func (m *PeerInfo) GetQueued() int64 {
+	if m != nil {
+		return m.Queued
+	}
+	return 0
+}
+
+func (m *PeerInfo) GetInFlight() int64 {
+	if m != nil {
+		return m.InFlight
+	}
+	return 0
+}
+
+type PeerInfoList struct {
+	Peers                []*PeerInfo `protobuf:"bytes,1,rep,name=peers,proto3" json:"peers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *PeerInfoList) Reset()         { *m = PeerInfoList{} }
+func (m *PeerInfoList) String() string { return proto.CompactTextString(m) }
+func (*PeerInfoList) ProtoMessage()    {}
+func (*PeerInfoList) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{36}
+}
+
+func (m *PeerInfoList) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PeerInfoList.Unmarshal(m, b)
+}
+func (m *PeerInfoList) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PeerInfoList.Marshal(b, m, deterministic)
+}
+func (m *PeerInfoList) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeerInfoList.Merge(m, src)
+}
+func (m *PeerInfoList) XXX_Size() int {
+	return xxx_messageInfo_PeerInfoList.Size(m)
+}
+func (m *PeerInfoList) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeerInfoList.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeerInfoList proto.InternalMessageInfo
+
+func (m *PeerInfoList) GetPeers() []*PeerInfo {
+	if m != nil {
+		return m.Peers
+	}
+	return nil
+}
+
+// A proposal seen in a period
+type ProposalInfo struct {
+	// Sortition priority it was proposed with, the lowest one leads
+	Priority string `protobuf:"bytes,1,opt,name=priority,proto3" json:"priority,omitempty"`
+	Value    string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// False if we only know the value and not the block
+	HaveBlock            bool     `protobuf:"varint,3,opt,name=haveBlock,proto3" json:"haveBlock,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ProposalInfo) Reset()         { *m = ProposalInfo{} }
+func (m *ProposalInfo) String() string { return proto.CompactTextString(m) }
+func (*ProposalInfo) ProtoMessage()    {}
+func (*ProposalInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{37}
+}
+
+func (m *ProposalInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProposalInfo.Unmarshal(m, b)
+}
+func (m *ProposalInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProposalInfo.Marshal(b, m, deterministic)
+}
+func (m *ProposalInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProposalInfo.Merge(m, src)
+}
+func (m *ProposalInfo) XXX_Size() int {
+	return xxx_messageInfo_ProposalInfo.Size(m)
+}
+func (m *ProposalInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProposalInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProposalInfo proto.InternalMessageInfo
+
+func (m *ProposalInfo) GetPriority() string {
+	if m != nil {
+		return m.Priority
+	}
+	return ""
+}
+
+func (m *ProposalInfo) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *ProposalInfo) GetHaveBlock() bool {
+	if m != nil {
+		return m.HaveBlock
+	}
+	return false
+}
+
+// Number of votes for a value of one type in one period
+type VoteTally struct {
+	Period               int64    `protobuf:"varint,1,opt,name=period,proto3" json:"period,omitempty"`
+	Type                 string   `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Value                string   `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Votes                int64    `protobuf:"varint,4,opt,name=votes,proto3" json:"votes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *VoteTally) Reset()         { *m = VoteTally{} }
+func (m *VoteTally) String() string { return proto.CompactTextString(m) }
+func (*VoteTally) ProtoMessage()    {}
+func (*VoteTally) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{38}
+}
+
+func (m *VoteTally) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VoteTally.Unmarshal(m, b)
+}
+func (m *VoteTally) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VoteTally.Marshal(b, m, deterministic)
+}
+func (m *VoteTally) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VoteTally.Merge(m, src)
+}
+func (m *VoteTally) XXX_Size() int {
+	return xxx_messageInfo_VoteTally.Size(m)
+}
+func (m *VoteTally) XXX_DiscardUnknown() {
+	xxx_messageInfo_VoteTally.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VoteTally proto.InternalMessageInfo
+
+func (m *VoteTally) GetPeriod() int64 {
+	if m != nil {
+		return m.Period
+	}
+	return 0
+}
+
+func (m *VoteTally) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *VoteTally) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *VoteTally) GetVotes() int64 {
+	if m != nil {
+		return m.Votes
+	}
+	return 0
+}
+
+type PeriodStateInfo struct {
+	Round         int64           `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Period        int64           `protobuf:"varint,2,opt,name=period,proto3" json:"period,omitempty"`
+	Step          int64           `protobuf:"varint,3,opt,name=step,proto3" json:"step,omitempty"`
+	StartingValue string          `protobuf:"bytes,4,opt,name=startingValue,proto3" json:"startingValue,omitempty"`
+	MyCertVote    string          `protobuf:"bytes,5,opt,name=myCertVote,proto3" json:"myCertVote,omitempty"`
+	RequiredVotes int64           `protobuf:"varint,6,opt,name=requiredVotes,proto3" json:"requiredVotes,omitempty"`
+	Proposals     []*ProposalInfo `protobuf:"bytes,7,rep,name=proposals,proto3" json:"proposals,omitempty"`
+	// Tallies of the current and the previous period
+	Tallies              []*VoteTally `protobuf:"bytes,8,rep,name=tallies,proto3" json:"tallies,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *PeriodStateInfo) Reset()         { *m = PeriodStateInfo{} }
+func (m *PeriodStateInfo) String() string { return proto.CompactTextString(m) }
+func (*PeriodStateInfo) ProtoMessage()    {}
+func (*PeriodStateInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{39}
+}
+
+func (m *PeriodStateInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PeriodStateInfo.Unmarshal(m, b)
+}
+func (m *PeriodStateInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PeriodStateInfo.Marshal(b, m, deterministic)
+}
+func (m *PeriodStateInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeriodStateInfo.Merge(m, src)
+}
+func (m *PeriodStateInfo) XXX_Size() int {
+	return xxx_messageInfo_PeriodStateInfo.Size(m)
+}
+func (m *PeriodStateInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeriodStateInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeriodStateInfo proto.InternalMessageInfo
+
+func (m *PeriodStateInfo) GetRound() int64 {
+	if m != nil {
+		return m.Round
+	}
+	return 0
+}
+
+func (m *PeriodStateInfo) GetPeriod() int64 {
+	if m != nil {
+		return m.Period
+	}
+	return 0
+}
+
+func (m *PeriodStateInfo) GetStep() int64 {
+	if m != nil {
+		return m.Step
+	}
+	return 0
+}
+
+func (m *PeriodStateInfo) GetStartingValue() string {
+	if m != nil {
+		return m.StartingValue
+	}
+	return ""
+}
+
+func (m *PeriodStateInfo) GetMyCertVote() string {
+	if m != nil {
+		return m.MyCertVote
+	}
+	return ""
+}
+
+func (m *PeriodStateInfo) GetRequiredVotes() int64 {
+	if m != nil {
+		return m.RequiredVotes
+	}
+	return 0
+}
+
+func (m *PeriodStateInfo) GetProposals() []*ProposalInfo {
+	if m != nil {
+		return m.Proposals
+	}
+	return nil
+}
+
+func (m *PeriodStateInfo) GetTallies() []*VoteTally {
+	if m != nil {
+		return m.Tallies
+	}
+	return nil
+}
+
+type Mempool struct {
+	Tx                   []*Transaction `protobuf:"bytes,1,rep,name=tx,proto3" json:"tx,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *Mempool) Reset()         { *m = Mempool{} }
+func (m *Mempool) String() string { return proto.CompactTextString(m) }
+func (*Mempool) ProtoMessage()    {}
+func (*Mempool) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{40}
+}
+
+func (m *Mempool) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Mempool.Unmarshal(m, b)
+}
+func (m *Mempool) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Mempool.Marshal(b, m, deterministic)
+}
+func (m *Mempool) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Mempool.Merge(m, src)
+}
+func (m *Mempool) XXX_Size() int {
+	return xxx_messageInfo_Mempool.Size(m)
+}
+func (m *Mempool) XXX_DiscardUnknown() {
+	xxx_messageInfo_Mempool.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Mempool proto.InternalMessageInfo
+
+func (m *Mempool) GetTx() []*Transaction {
+	if m != nil {
+		return m.Tx
+	}
+	return nil
+}
+
+// The agreement configuration a journal was recorded with
+type JournalConfig struct {
+	UserId string `protobuf:"bytes,1,opt,name=userId,proto3" json:"userId,omitempty"`
+	// no longer written, nodes have no such key
+	PrivateKey    int64    `protobuf:"varint,2,opt,name=privateKey,proto3" json:"privateKey,omitempty"`
+	Candidates    []string `protobuf:"bytes,3,rep,name=candidates,proto3" json:"candidates,omitempty"`
+	K             int64    `protobuf:"varint,4,opt,name=k,proto3" json:"k,omitempty"`
+	RequiredVotes int64    `protobuf:"varint,5,opt,name=requiredVotes,proto3" json:"requiredVotes,omitempty"`
+	// Timing parameters in nanoseconds
+	Lambda         int64 `protobuf:"varint,6,opt,name=lambda,proto3" json:"lambda,omitempty"`
+	BigLambda      int64 `protobuf:"varint,7,opt,name=bigLambda,proto3" json:"bigLambda,omitempty"`
+	RoundInterval  int64 `protobuf:"varint,8,opt,name=roundInterval,proto3" json:"roundInterval,omitempty"`
+	FutureRounds   int64 `protobuf:"varint,9,opt,name=futureRounds,proto3" json:"futureRounds,omitempty"`
+	FutureMessages int64 `protobuf:"varint,10,opt,name=futureMessages,proto3" json:"futureMessages,omitempty"`
+	// The VRF public key of every user
+	VrfKeys []*JournalKey `protobuf:"bytes,11,rep,name=vrfKeys,proto3" json:"vrfKeys,omitempty"`
+	// Seed of the genesis block
+	Seed                 string   `protobuf:"bytes,12,opt,name=seed,proto3" json:"seed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JournalConfig) Reset()         { *m = JournalConfig{} }
+func (m *JournalConfig) String() string { return proto.CompactTextString(m) }
+func (*JournalConfig) ProtoMessage()    {}
+func (*JournalConfig) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{41}
+}
+
+func (m *JournalConfig) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JournalConfig.Unmarshal(m, b)
+}
+func (m *JournalConfig) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JournalConfig.Marshal(b, m, deterministic)
+}
+func (m *JournalConfig) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JournalConfig.Merge(m, src)
+}
+func (m *JournalConfig) XXX_Size() int {
+	return xxx_messageInfo_JournalConfig.Size(m)
+}
+func (m *JournalConfig) XXX_DiscardUnknown() {
+	xxx_messageInfo_JournalConfig.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JournalConfig proto.InternalMessageInfo
+
+func (m *JournalConfig) GetUserId() string {
+	if m != nil {
+		return m.UserId
+	}
+	return ""
 }
 
-func (m *RequestBlockChainArgs) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_RequestBlockChainArgs.Unmarshal(m, b)
+func (m *JournalConfig) GetPrivateKey() int64 {
+	if m != nil {
+		return m.PrivateKey
+	}
+	return 0
 }
-func (m *RequestBlockChainArgs) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_RequestBlockChainArgs.Marshal(b, m, deterministic)
+
+func (m *JournalConfig) GetCandidates() []string {
+	if m != nil {
+		return m.Candidates
+	}
+	return nil
 }
-func (m *RequestBlockChainArgs) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_RequestBlockChainArgs.Merge(m, src)
+
+func (m *JournalConfig) GetK() int64 {
+	if m != nil {
+		return m.K
+	}
+	return 0
 }
-func (m *RequestBlockChainArgs) XXX_Size() int {
-	return xxx_messageInfo_RequestBlockChainArgs.Size(m)
+
+func (m *JournalConfig) GetRequiredVotes() int64 {
+	if m != nil {
+		return m.RequiredVotes
+	}
+	return 0
 }
-func (m *RequestBlockChainArgs) XXX_DiscardUnknown() {
-	xxx_messageInfo_RequestBlockChainArgs.DiscardUnknown(m)
+
+func (m *JournalConfig) GetLambda() int64 {
+	if m != nil {
+		return m.Lambda
+	}
+	return 0
 }
 
-var xxx_messageInfo_RequestBlockChainArgs proto.InternalMessageInfo
+func (m *JournalConfig) GetBigLambda() int64 {
+	if m != nil {
+		return m.BigLambda
+	}
+	return 0
+}
 
-func (m *RequestBlockChainArgs) GetPeer() string {
+func (m *JournalConfig) GetRoundInterval() int64 {
 	if m != nil {
-		return m.Peer
+		return m.RoundInterval
+	}
+	return 0
+}
+
+func (m *JournalConfig) GetFutureRounds() int64 {
+	if m != nil {
+		return m.FutureRounds
+	}
+	return 0
+}
+
+func (m *JournalConfig) GetFutureMessages() int64 {
+	if m != nil {
+		return m.FutureMessages
+	}
+	return 0
+}
+
+func (m *JournalConfig) GetVrfKeys() []*JournalKey {
+	if m != nil {
+		return m.VrfKeys
+	}
+	return nil
+}
+
+func (m *JournalConfig) GetSeed() string {
+	if m != nil {
+		return m.Seed
 	}
 	return ""
 }
 
-type RequestBlockChainRet struct {
-	Peer                 string   `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"`
-	Blockchain           []*Block `protobuf:"bytes,2,rep,name=blockchain,proto3" json:"blockchain,omitempty"`
+type JournalKey struct {
+	UserId string `protobuf:"bytes,1,opt,name=userId,proto3" json:"userId,omitempty"`
+	// Hex encoded
+	Key                  string   `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *RequestBlockChainRet) Reset()         { *m = RequestBlockChainRet{} }
-func (m *RequestBlockChainRet) String() string { return proto.CompactTextString(m) }
-func (*RequestBlockChainRet) ProtoMessage()    {}
-func (*RequestBlockChainRet) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{15}
+func (m *JournalKey) Reset()         { *m = JournalKey{} }
+func (m *JournalKey) String() string { return proto.CompactTextString(m) }
+func (*JournalKey) ProtoMessage()    {}
+func (*JournalKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{42}
 }
 
-func (m *RequestBlockChainRet) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_RequestBlockChainRet.Unmarshal(m, b)
+func (m *JournalKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JournalKey.Unmarshal(m, b)
 }
-func (m *RequestBlockChainRet) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_RequestBlockChainRet.Marshal(b, m, deterministic)
+func (m *JournalKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JournalKey.Marshal(b, m, deterministic)
 }
-func (m *RequestBlockChainRet) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_RequestBlockChainRet.Merge(m, src)
+func (m *JournalKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JournalKey.Merge(m, src)
 }
-func (m *RequestBlockChainRet) XXX_Size() int {
-	return xxx_messageInfo_RequestBlockChainRet.Size(m)
+func (m *JournalKey) XXX_Size() int {
+	return xxx_messageInfo_JournalKey.Size(m)
 }
-func (m *RequestBlockChainRet) XXX_DiscardUnknown() {
-	xxx_messageInfo_RequestBlockChainRet.DiscardUnknown(m)
+func (m *JournalKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_JournalKey.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_RequestBlockChainRet proto.InternalMessageInfo
+var xxx_messageInfo_JournalKey proto.InternalMessageInfo
 
-func (m *RequestBlockChainRet) GetPeer() string {
+func (m *JournalKey) GetUserId() string {
 	if m != nil {
-		return m.Peer
+		return m.UserId
 	}
 	return ""
 }
 
-func (m *RequestBlockChainRet) GetBlockchain() []*Block {
+func (m *JournalKey) GetKey() string {
 	if m != nil {
-		return m.Blockchain
+		return m.Key
+	}
+	return ""
+}
+
+type JournalTimeout struct {
+	Timer                int64    `protobuf:"varint,1,opt,name=timer,proto3" json:"timer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JournalTimeout) Reset()         { *m = JournalTimeout{} }
+func (m *JournalTimeout) String() string { return proto.CompactTextString(m) }
+func (*JournalTimeout) ProtoMessage()    {}
+func (*JournalTimeout) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{43}
+}
+
+func (m *JournalTimeout) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JournalTimeout.Unmarshal(m, b)
+}
+func (m *JournalTimeout) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JournalTimeout.Marshal(b, m, deterministic)
+}
+func (m *JournalTimeout) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JournalTimeout.Merge(m, src)
+}
+func (m *JournalTimeout) XXX_Size() int {
+	return xxx_messageInfo_JournalTimeout.Size(m)
+}
+func (m *JournalTimeout) XXX_DiscardUnknown() {
+	xxx_messageInfo_JournalTimeout.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JournalTimeout proto.InternalMessageInfo
+
+func (m *JournalTimeout) GetTimer() int64 {
+	if m != nil {
+		return m.Timer
+	}
+	return 0
+}
+
+type JournalBlockAssembled struct {
+	Round  int64  `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Period int64  `protobuf:"varint,2,opt,name=period,proto3" json:"period,omitempty"`
+	Block  *Block `protobuf:"bytes,3,opt,name=block,proto3" json:"block,omitempty"`
+	Value  string `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	// Hex encoded VRF proof over the period's sortition seed
+	Proof                string   `protobuf:"bytes,5,opt,name=proof,proto3" json:"proof,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JournalBlockAssembled) Reset()         { *m = JournalBlockAssembled{} }
+func (m *JournalBlockAssembled) String() string { return proto.CompactTextString(m) }
+func (*JournalBlockAssembled) ProtoMessage()    {}
+func (*JournalBlockAssembled) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{44}
+}
+
+func (m *JournalBlockAssembled) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JournalBlockAssembled.Unmarshal(m, b)
+}
+func (m *JournalBlockAssembled) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JournalBlockAssembled.Marshal(b, m, deterministic)
+}
+func (m *JournalBlockAssembled) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JournalBlockAssembled.Merge(m, src)
+}
+func (m *JournalBlockAssembled) XXX_Size() int {
+	return xxx_messageInfo_JournalBlockAssembled.Size(m)
+}
+func (m *JournalBlockAssembled) XXX_DiscardUnknown() {
+	xxx_messageInfo_JournalBlockAssembled.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JournalBlockAssembled proto.InternalMessageInfo
+
+func (m *JournalBlockAssembled) GetRound() int64 {
+	if m != nil {
+		return m.Round
+	}
+	return 0
+}
+
+func (m *JournalBlockAssembled) GetPeriod() int64 {
+	if m != nil {
+		return m.Period
+	}
+	return 0
+}
+
+func (m *JournalBlockAssembled) GetBlock() *Block {
+	if m != nil {
+		return m.Block
 	}
 	return nil
 }
 
-type Blockchain struct {
-	Blocks               []*Block `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+func (m *JournalBlockAssembled) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *JournalBlockAssembled) GetProof() string {
+	if m != nil {
+		return m.Proof
+	}
+	return ""
+}
+
+type JournalSynced struct {
+	Round int64 `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	// Seed of the block before round
+	Seed                 string   `protobuf:"bytes,2,opt,name=seed,proto3" json:"seed,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Blockchain) Reset()         { *m = Blockchain{} }
-func (m *Blockchain) String() string { return proto.CompactTextString(m) }
-func (*Blockchain) ProtoMessage()    {}
-func (*Blockchain) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{16}
+func (m *JournalSynced) Reset()         { *m = JournalSynced{} }
+func (m *JournalSynced) String() string { return proto.CompactTextString(m) }
+func (*JournalSynced) ProtoMessage()    {}
+func (*JournalSynced) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{45}
 }
 
-func (m *Blockchain) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Blockchain.Unmarshal(m, b)
+func (m *JournalSynced) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JournalSynced.Unmarshal(m, b)
 }
-func (m *Blockchain) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Blockchain.Marshal(b, m, deterministic)
+func (m *JournalSynced) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JournalSynced.Marshal(b, m, deterministic)
 }
-func (m *Blockchain) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Blockchain.Merge(m, src)
+func (m *JournalSynced) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JournalSynced.Merge(m, src)
 }
-func (m *Blockchain) XXX_Size() int {
-	return xxx_messageInfo_Blockchain.Size(m)
+func (m *JournalSynced) XXX_Size() int {
+	return xxx_messageInfo_JournalSynced.Size(m)
 }
-func (m *Blockchain) XXX_DiscardUnknown() {
-	xxx_messageInfo_Blockchain.DiscardUnknown(m)
+func (m *JournalSynced) XXX_DiscardUnknown() {
+	xxx_messageInfo_JournalSynced.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Blockchain proto.InternalMessageInfo
+var xxx_messageInfo_JournalSynced proto.InternalMessageInfo
 
-func (m *Blockchain) GetBlocks() []*Block {
+func (m *JournalSynced) GetRound() int64 {
 	if m != nil {
-		return m.Blocks
+		return m.Round
+	}
+	return 0
+}
+
+func (m *JournalSynced) GetSeed() string {
+	if m != nil {
+		return m.Seed
+	}
+	return ""
+}
+
+type JournalBlockFetched struct {
+	Round                int64    `protobuf:"varint,1,opt,name=round,proto3" json:"round,omitempty"`
+	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Block                *Block   `protobuf:"bytes,3,opt,name=block,proto3" json:"block,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JournalBlockFetched) Reset()         { *m = JournalBlockFetched{} }
+func (m *JournalBlockFetched) String() string { return proto.CompactTextString(m) }
+func (*JournalBlockFetched) ProtoMessage()    {}
+func (*JournalBlockFetched) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{46}
+}
+
+func (m *JournalBlockFetched) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JournalBlockFetched.Unmarshal(m, b)
+}
+func (m *JournalBlockFetched) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JournalBlockFetched.Marshal(b, m, deterministic)
+}
+func (m *JournalBlockFetched) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JournalBlockFetched.Merge(m, src)
+}
+func (m *JournalBlockFetched) XXX_Size() int {
+	return xxx_messageInfo_JournalBlockFetched.Size(m)
+}
+func (m *JournalBlockFetched) XXX_DiscardUnknown() {
+	xxx_messageInfo_JournalBlockFetched.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JournalBlockFetched proto.InternalMessageInfo
+
+func (m *JournalBlockFetched) GetRound() int64 {
+	if m != nil {
+		return m.Round
+	}
+	return 0
+}
+
+func (m *JournalBlockFetched) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *JournalBlockFetched) GetBlock() *Block {
+	if m != nil {
+		return m.Block
 	}
 	return nil
 }
 
-type Result struct {
-	// Types that are valid to be assigned to Result:
-	//	*Result_Bc
-	//	*Result_S
-	Result               isResult_Result `protobuf_oneof:"result"`
-	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
-	XXX_unrecognized     []byte          `json:"-"`
-	XXX_sizecache        int32           `json:"-"`
+// One input to the agreement state machine and what it decided
+type JournalRecord struct {
+	// Unix time in nanoseconds
+	Time int64 `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	// Types that are valid to be assigned to Event:
+	//	*JournalRecord_Start
+	//	*JournalRecord_Timeout
+	//	*JournalRecord_Proposal
+	//	*JournalRecord_Vote
+	//	*JournalRecord_Assembled
+	//	*JournalRecord_Synced
+	//	*JournalRecord_Fetched
+	//	*JournalRecord_Priority
+	Event isJournalRecord_Event `protobuf_oneof:"event"`
+	// The actions the machine returned, one line each
+	Actions []string `protobuf:"bytes,8,rep,name=actions,proto3" json:"actions,omitempty"`
+	// Why the input was rejected, if it was
+	Error string `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	// Where the machine was after handling the input
+	Round                int64    `protobuf:"varint,10,opt,name=round,proto3" json:"round,omitempty"`
+	Period               int64    `protobuf:"varint,11,opt,name=period,proto3" json:"period,omitempty"`
+	Step                 int64    `protobuf:"varint,12,opt,name=step,proto3" json:"step,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Result) Reset()         { *m = Result{} }
-func (m *Result) String() string { return proto.CompactTextString(m) }
-func (*Result) ProtoMessage()    {}
-func (*Result) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{17}
+func (m *JournalRecord) Reset()         { *m = JournalRecord{} }
+func (m *JournalRecord) String() string { return proto.CompactTextString(m) }
+func (*JournalRecord) ProtoMessage()    {}
+func (*JournalRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{47}
+}
+
+func (m *JournalRecord) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JournalRecord.Unmarshal(m, b)
+}
+func (m *JournalRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JournalRecord.Marshal(b, m, deterministic)
+}
+func (m *JournalRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JournalRecord.Merge(m, src)
+}
+func (m *JournalRecord) XXX_Size() int {
+	return xxx_messageInfo_JournalRecord.Size(m)
+}
+func (m *JournalRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_JournalRecord.DiscardUnknown(m)
 }
 
-func (m *Result) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Result.Unmarshal(m, b)
+var xxx_messageInfo_JournalRecord proto.InternalMessageInfo
+
+func (m *JournalRecord) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+type isJournalRecord_Event interface {
+	isJournalRecord_Event()
+}
+
+type JournalRecord_Start struct {
+	Start *JournalConfig `protobuf:"bytes,2,opt,name=start,proto3,oneof"`
+}
+
+type JournalRecord_Timeout struct {
+	Timeout *JournalTimeout `protobuf:"bytes,3,opt,name=timeout,proto3,oneof"`
+}
+
+type JournalRecord_Proposal struct {
+	Proposal *ProposeBlockArgs `protobuf:"bytes,4,opt,name=proposal,proto3,oneof"`
+}
+
+type JournalRecord_Vote struct {
+	Vote *VoteArgs `protobuf:"bytes,5,opt,name=vote,proto3,oneof"`
+}
+
+type JournalRecord_Assembled struct {
+	Assembled *JournalBlockAssembled `protobuf:"bytes,6,opt,name=assembled,proto3,oneof"`
+}
+
+type JournalRecord_Synced struct {
+	Synced *JournalSynced `protobuf:"bytes,7,opt,name=synced,proto3,oneof"`
+}
+
+type JournalRecord_Fetched struct {
+	Fetched *JournalBlockFetched `protobuf:"bytes,13,opt,name=fetched,proto3,oneof"`
+}
+
+type JournalRecord_Priority struct {
+	Priority *ProposePriorityArgs `protobuf:"bytes,14,opt,name=priority,proto3,oneof"`
+}
+
+func (*JournalRecord_Start) isJournalRecord_Event() {}
+
+func (*JournalRecord_Timeout) isJournalRecord_Event() {}
+
+func (*JournalRecord_Proposal) isJournalRecord_Event() {}
+
+func (*JournalRecord_Vote) isJournalRecord_Event() {}
+
+func (*JournalRecord_Assembled) isJournalRecord_Event() {}
+
+func (*JournalRecord_Synced) isJournalRecord_Event() {}
+
+func (*JournalRecord_Fetched) isJournalRecord_Event() {}
+
+func (*JournalRecord_Priority) isJournalRecord_Event() {}
+
+func (m *JournalRecord) GetEvent() isJournalRecord_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
 }
-func (m *Result) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Result.Marshal(b, m, deterministic)
+
+func (m *JournalRecord) GetStart() *JournalConfig {
+	if x, ok := m.GetEvent().(*JournalRecord_Start); ok {
+		return x.Start
+	}
+	return nil
 }
-func (m *Result) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Result.Merge(m, src)
+
+func (m *JournalRecord) GetTimeout() *JournalTimeout {
+	if x, ok := m.GetEvent().(*JournalRecord_Timeout); ok {
+		return x.Timeout
+	}
+	return nil
 }
-func (m *Result) XXX_Size() int {
-	return xxx_messageInfo_Result.Size(m)
+
+func (m *JournalRecord) GetProposal() *ProposeBlockArgs {
+	if x, ok := m.GetEvent().(*JournalRecord_Proposal); ok {
+		return x.Proposal
+	}
+	return nil
 }
-func (m *Result) XXX_DiscardUnknown() {
-	xxx_messageInfo_Result.DiscardUnknown(m)
+
+func (m *JournalRecord) GetVote() *VoteArgs {
+	if x, ok := m.GetEvent().(*JournalRecord_Vote); ok {
+		return x.Vote
+	}
+	return nil
 }
 
-var xxx_messageInfo_Result proto.InternalMessageInfo
+func (m *JournalRecord) GetAssembled() *JournalBlockAssembled {
+	if x, ok := m.GetEvent().(*JournalRecord_Assembled); ok {
+		return x.Assembled
+	}
+	return nil
+}
 
-type isResult_Result interface {
-	isResult_Result()
+func (m *JournalRecord) GetSynced() *JournalSynced {
+	if x, ok := m.GetEvent().(*JournalRecord_Synced); ok {
+		return x.Synced
+	}
+	return nil
 }
 
-type Result_Bc struct {
-	Bc *Blockchain `protobuf:"bytes,1,opt,name=bc,proto3,oneof"`
+func (m *JournalRecord) GetFetched() *JournalBlockFetched {
+	if x, ok := m.GetEvent().(*JournalRecord_Fetched); ok {
+		return x.Fetched
+	}
+	return nil
 }
 
-type Result_S struct {
-	S *Success `protobuf:"bytes,2,opt,name=s,proto3,oneof"`
+func (m *JournalRecord) GetPriority() *ProposePriorityArgs {
+	if x, ok := m.GetEvent().(*JournalRecord_Priority); ok {
+		return x.Priority
+	}
+	return nil
 }
 
-func (*Result_Bc) isResult_Result() {}
+func (m *JournalRecord) GetActions() []string {
+	if m != nil {
+		return m.Actions
+	}
+	return nil
+}
 
-func (*Result_S) isResult_Result() {}
+func (m *JournalRecord) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
 
-func (m *Result) GetResult() isResult_Result {
+func (m *JournalRecord) GetRound() int64 {
 	if m != nil {
-		return m.Result
+		return m.Round
 	}
-	return nil
+	return 0
 }
 
-func (m *Result) GetBc() *Blockchain {
-	if x, ok := m.GetResult().(*Result_Bc); ok {
-		return x.Bc
+func (m *JournalRecord) GetPeriod() int64 {
+	if m != nil {
+		return m.Period
 	}
-	return nil
+	return 0
 }
 
-func (m *Result) GetS() *Success {
-	if x, ok := m.GetResult().(*Result_S); ok {
-		return x.S
+func (m *JournalRecord) GetStep() int64 {
+	if m != nil {
+		return m.Step
 	}
-	return nil
+	return 0
 }
 
 // XXX_OneofFuncs is for the internal use of the proto package.
-func (*Result) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
-	return _Result_OneofMarshaler, _Result_OneofUnmarshaler, _Result_OneofSizer, []interface{}{
-		(*Result_Bc)(nil),
-		(*Result_S)(nil),
+func (*JournalRecord) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _JournalRecord_OneofMarshaler, _JournalRecord_OneofUnmarshaler, _JournalRecord_OneofSizer, []interface{}{
+		(*JournalRecord_Start)(nil),
+		(*JournalRecord_Timeout)(nil),
+		(*JournalRecord_Proposal)(nil),
+		(*JournalRecord_Vote)(nil),
+		(*JournalRecord_Assembled)(nil),
+		(*JournalRecord_Synced)(nil),
+		(*JournalRecord_Fetched)(nil),
+		(*JournalRecord_Priority)(nil),
 	}
 }
 
-func _Result_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
-	m := msg.(*Result)
-	// result
-	switch x := m.Result.(type) {
-	case *Result_Bc:
-		b.EncodeVarint(1<<3 | proto.WireBytes)
-		if err := b.EncodeMessage(x.Bc); err != nil {
+func _JournalRecord_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*JournalRecord)
+	// event
+	switch x := m.Event.(type) {
+	case *JournalRecord_Start:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Start); err != nil {
 			return err
 		}
-	case *Result_S:
-		b.EncodeVarint(2<<3 | proto.WireBytes)
-		if err := b.EncodeMessage(x.S); err != nil {
+	case *JournalRecord_Timeout:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Timeout); err != nil {
+			return err
+		}
+	case *JournalRecord_Proposal:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Proposal); err != nil {
+			return err
+		}
+	case *JournalRecord_Vote:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Vote); err != nil {
+			return err
+		}
+	case *JournalRecord_Assembled:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Assembled); err != nil {
+			return err
+		}
+	case *JournalRecord_Synced:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Synced); err != nil {
+			return err
+		}
+	case *JournalRecord_Fetched:
+		b.EncodeVarint(13<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Fetched); err != nil {
+			return err
+		}
+	case *JournalRecord_Priority:
+		b.EncodeVarint(14<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Priority); err != nil {
 			return err
 		}
 	case nil:
 	default:
-		return fmt.Errorf("Result.Result has unexpected type %T", x)
+		return fmt.Errorf("JournalRecord.Event has unexpected type %T", x)
 	}
 	return nil
 }
 
-func _Result_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
-	m := msg.(*Result)
+func _JournalRecord_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*JournalRecord)
 	switch tag {
-	case 1: // result.bc
+	case 2: // event.start
 		if wire != proto.WireBytes {
 			return true, proto.ErrInternalBadWireType
 		}
-		msg := new(Blockchain)
+		msg := new(JournalConfig)
 		err := b.DecodeMessage(msg)
-		m.Result = &Result_Bc{msg}
+		m.Event = &JournalRecord_Start{msg}
 		return true, err
-	case 2: // result.s
+	case 3: // event.timeout
 		if wire != proto.WireBytes {
 			return true, proto.ErrInternalBadWireType
 		}
-		msg := new(Success)
+		msg := new(JournalTimeout)
 		err := b.DecodeMessage(msg)
-		m.Result = &Result_S{msg}
+		m.Event = &JournalRecord_Timeout{msg}
+		return true, err
+	case 4: // event.proposal
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ProposeBlockArgs)
+		err := b.DecodeMessage(msg)
+		m.Event = &JournalRecord_Proposal{msg}
+		return true, err
+	case 5: // event.vote
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(VoteArgs)
+		err := b.DecodeMessage(msg)
+		m.Event = &JournalRecord_Vote{msg}
+		return true, err
+	case 6: // event.assembled
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(JournalBlockAssembled)
+		err := b.DecodeMessage(msg)
+		m.Event = &JournalRecord_Assembled{msg}
+		return true, err
+	case 7: // event.synced
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(JournalSynced)
+		err := b.DecodeMessage(msg)
+		m.Event = &JournalRecord_Synced{msg}
+		return true, err
+	case 13: // event.fetched
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(JournalBlockFetched)
+		err := b.DecodeMessage(msg)
+		m.Event = &JournalRecord_Fetched{msg}
+		return true, err
+	case 14: // event.priority
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(ProposePriorityArgs)
+		err := b.DecodeMessage(msg)
+		m.Event = &JournalRecord_Priority{msg}
 		return true, err
 	default:
 		return false, nil
 	}
 }
 
-func _Result_OneofSizer(msg proto.Message) (n int) {
-	m := msg.(*Result)
-	// result
-	switch x := m.Result.(type) {
-	case *Result_Bc:
-		s := proto.Size(x.Bc)
+func _JournalRecord_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*JournalRecord)
+	// event
+	switch x := m.Event.(type) {
+	case *JournalRecord_Start:
+		s := proto.Size(x.Start)
 		n += 1 // tag and wire
 		n += proto.SizeVarint(uint64(s))
 		n += s
-	case *Result_S:
-		s := proto.Size(x.S)
+	case *JournalRecord_Timeout:
+		s := proto.Size(x.Timeout)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *JournalRecord_Proposal:
+		s := proto.Size(x.Proposal)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *JournalRecord_Vote:
+		s := proto.Size(x.Vote)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *JournalRecord_Assembled:
+		s := proto.Size(x.Assembled)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *JournalRecord_Synced:
+		s := proto.Size(x.Synced)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *JournalRecord_Fetched:
+		s := proto.Size(x.Fetched)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *JournalRecord_Priority:
+		s := proto.Size(x.Priority)
 		n += 1 // tag and wire
 		n += proto.SizeVarint(uint64(s))
 		n += s
@@ -972,159 +3582,170 @@ func _Result_OneofSizer(msg proto.Message) (n int) {
 	return n
 }
 
-// A type for arguments across all operations
-type Command struct {
-	Operation Op `protobuf:"varint,1,opt,name=operation,proto3,enum=pb.Op" json:"operation,omitempty"`
-	// Types that are valid to be assigned to Arg:
-	//	*Command_Empty
-	//	*Command_Tx
-	Arg                  isCommand_Arg `protobuf_oneof:"arg"`
-	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
-	XXX_unrecognized     []byte        `json:"-"`
-	XXX_sizecache        int32         `json:"-"`
+// An encrypted keystore, see package keystore.
+type Keystore struct {
+	// scrypt parameters and hex encoded salt the passphrase is stretched with
+	ScryptN int64  `protobuf:"varint,1,opt,name=scryptN,proto3" json:"scryptN,omitempty"`
+	ScryptR int64  `protobuf:"varint,2,opt,name=scryptR,proto3" json:"scryptR,omitempty"`
+	ScryptP int64  `protobuf:"varint,3,opt,name=scryptP,proto3" json:"scryptP,omitempty"`
+	Salt    string `protobuf:"bytes,4,opt,name=salt,proto3" json:"salt,omitempty"`
+	// hex encoded nonce and sealed empty text, proves the passphrase
+	Check                string           `protobuf:"bytes,5,opt,name=check,proto3" json:"check,omitempty"`
+	Entries              []*KeystoreEntry `protobuf:"bytes,6,rep,name=entries,proto3" json:"entries,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
 }
 
-func (m *Command) Reset()         { *m = Command{} }
-func (m *Command) String() string { return proto.CompactTextString(m) }
-func (*Command) ProtoMessage()    {}
-func (*Command) Descriptor() ([]byte, []int) {
-	return fileDescriptor_99e2a20f8b284799, []int{18}
+func (m *Keystore) Reset()         { *m = Keystore{} }
+func (m *Keystore) String() string { return proto.CompactTextString(m) }
+func (*Keystore) ProtoMessage()    {}
+func (*Keystore) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{48}
 }
 
-func (m *Command) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Command.Unmarshal(m, b)
+func (m *Keystore) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Keystore.Unmarshal(m, b)
 }
-func (m *Command) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Command.Marshal(b, m, deterministic)
+func (m *Keystore) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Keystore.Marshal(b, m, deterministic)
 }
-func (m *Command) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Command.Merge(m, src)
+func (m *Keystore) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Keystore.Merge(m, src)
 }
-func (m *Command) XXX_Size() int {
-	return xxx_messageInfo_Command.Size(m)
+func (m *Keystore) XXX_Size() int {
+	return xxx_messageInfo_Keystore.Size(m)
 }
-func (m *Command) XXX_DiscardUnknown() {
-	xxx_messageInfo_Command.DiscardUnknown(m)
+func (m *Keystore) XXX_DiscardUnknown() {
+	xxx_messageInfo_Keystore.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Command proto.InternalMessageInfo
+var xxx_messageInfo_Keystore proto.InternalMessageInfo
 
-func (m *Command) GetOperation() Op {
+func (m *Keystore) GetScryptN() int64 {
 	if m != nil {
-		return m.Operation
+		return m.ScryptN
 	}
-	return Op_GET
+	return 0
 }
 
-type isCommand_Arg interface {
-	isCommand_Arg()
+func (m *Keystore) GetScryptR() int64 {
+	if m != nil {
+		return m.ScryptR
+	}
+	return 0
 }
 
-type Command_Empty struct {
-	Empty *Empty `protobuf:"bytes,2,opt,name=empty,proto3,oneof"`
+func (m *Keystore) GetScryptP() int64 {
+	if m != nil {
+		return m.ScryptP
+	}
+	return 0
 }
 
-type Command_Tx struct {
-	Tx *Transaction `protobuf:"bytes,3,opt,name=tx,proto3,oneof"`
+func (m *Keystore) GetSalt() string {
+	if m != nil {
+		return m.Salt
+	}
+	return ""
 }
 
-func (*Command_Empty) isCommand_Arg() {}
-
-func (*Command_Tx) isCommand_Arg() {}
-
-func (m *Command) GetArg() isCommand_Arg {
+func (m *Keystore) GetCheck() string {
 	if m != nil {
-		return m.Arg
+		return m.Check
 	}
-	return nil
+	return ""
 }
 
-func (m *Command) GetEmpty() *Empty {
-	if x, ok := m.GetArg().(*Command_Empty); ok {
-		return x.Empty
+func (m *Keystore) GetEntries() []*KeystoreEntry {
+	if m != nil {
+		return m.Entries
 	}
 	return nil
 }
 
-func (m *Command) GetTx() *Transaction {
-	if x, ok := m.GetArg().(*Command_Tx); ok {
-		return x.Tx
-	}
-	return nil
+type KeystoreEntry struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// account, participation or vrf
+	Kind string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	// hex encoded ed25519 public key, the root of participation keys
+	Public string `protobuf:"bytes,3,opt,name=public,proto3" json:"public,omitempty"`
+	// rounds of participation keys
+	FirstRound int64 `protobuf:"varint,4,opt,name=firstRound,proto3" json:"firstRound,omitempty"`
+	LastRound  int64 `protobuf:"varint,5,opt,name=lastRound,proto3" json:"lastRound,omitempty"`
+	// hex encoded nonce and AES-256-GCM sealed secret, the fields above
+	// are authenticated with it
+	Sealed               string   `protobuf:"bytes,6,opt,name=sealed,proto3" json:"sealed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-// XXX_OneofFuncs is for the internal use of the proto package.
-func (*Command) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
-	return _Command_OneofMarshaler, _Command_OneofUnmarshaler, _Command_OneofSizer, []interface{}{
-		(*Command_Empty)(nil),
-		(*Command_Tx)(nil),
-	}
+func (m *KeystoreEntry) Reset()         { *m = KeystoreEntry{} }
+func (m *KeystoreEntry) String() string { return proto.CompactTextString(m) }
+func (*KeystoreEntry) ProtoMessage()    {}
+func (*KeystoreEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_99e2a20f8b284799, []int{49}
 }
 
-func _Command_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
-	m := msg.(*Command)
-	// arg
-	switch x := m.Arg.(type) {
-	case *Command_Empty:
-		b.EncodeVarint(2<<3 | proto.WireBytes)
-		if err := b.EncodeMessage(x.Empty); err != nil {
-			return err
-		}
-	case *Command_Tx:
-		b.EncodeVarint(3<<3 | proto.WireBytes)
-		if err := b.EncodeMessage(x.Tx); err != nil {
-			return err
-		}
-	case nil:
-	default:
-		return fmt.Errorf("Command.Arg has unexpected type %T", x)
+func (m *KeystoreEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_KeystoreEntry.Unmarshal(m, b)
+}
+func (m *KeystoreEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_KeystoreEntry.Marshal(b, m, deterministic)
+}
+func (m *KeystoreEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_KeystoreEntry.Merge(m, src)
+}
+func (m *KeystoreEntry) XXX_Size() int {
+	return xxx_messageInfo_KeystoreEntry.Size(m)
+}
+func (m *KeystoreEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_KeystoreEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_KeystoreEntry proto.InternalMessageInfo
+
+func (m *KeystoreEntry) GetName() string {
+	if m != nil {
+		return m.Name
 	}
-	return nil
+	return ""
 }
 
-func _Command_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
-	m := msg.(*Command)
-	switch tag {
-	case 2: // arg.empty
-		if wire != proto.WireBytes {
-			return true, proto.ErrInternalBadWireType
-		}
-		msg := new(Empty)
-		err := b.DecodeMessage(msg)
-		m.Arg = &Command_Empty{msg}
-		return true, err
-	case 3: // arg.tx
-		if wire != proto.WireBytes {
-			return true, proto.ErrInternalBadWireType
-		}
-		msg := new(Transaction)
-		err := b.DecodeMessage(msg)
-		m.Arg = &Command_Tx{msg}
-		return true, err
-	default:
-		return false, nil
+func (m *KeystoreEntry) GetKind() string {
+	if m != nil {
+		return m.Kind
 	}
+	return ""
 }
 
-func _Command_OneofSizer(msg proto.Message) (n int) {
-	m := msg.(*Command)
-	// arg
-	switch x := m.Arg.(type) {
-	case *Command_Empty:
-		s := proto.Size(x.Empty)
-		n += 1 // tag and wire
-		n += proto.SizeVarint(uint64(s))
-		n += s
-	case *Command_Tx:
-		s := proto.Size(x.Tx)
-		n += 1 // tag and wire
-		n += proto.SizeVarint(uint64(s))
-		n += s
-	case nil:
-	default:
-		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+func (m *KeystoreEntry) GetPublic() string {
+	if m != nil {
+		return m.Public
 	}
-	return n
+	return ""
+}
+
+func (m *KeystoreEntry) GetFirstRound() int64 {
+	if m != nil {
+		return m.FirstRound
+	}
+	return 0
+}
+
+func (m *KeystoreEntry) GetLastRound() int64 {
+	if m != nil {
+		return m.LastRound
+	}
+	return 0
+}
+
+func (m *KeystoreEntry) GetSealed() string {
+	if m != nil {
+		return m.Sealed
+	}
+	return ""
 }
 
 func init() {
@@ -1133,6 +3754,7 @@ func init() {
 	proto.RegisterType((*Success)(nil), "pb.Success")
 	proto.RegisterType((*Error)(nil), "pb.Error")
 	proto.RegisterType((*Transaction)(nil), "pb.Transaction")
+	proto.RegisterType((*Participation)(nil), "pb.Participation")
 	proto.RegisterType((*Block)(nil), "pb.Block")
 	proto.RegisterType((*AppendBlockArgs)(nil), "pb.AppendBlockArgs")
 	proto.RegisterType((*AppendBlockRet)(nil), "pb.AppendBlockRet")
@@ -1140,68 +3762,207 @@ func init() {
 	proto.RegisterType((*AppendTransactionRet)(nil), "pb.AppendTransactionRet")
 	proto.RegisterType((*ProposeBlockArgs)(nil), "pb.ProposeBlockArgs")
 	proto.RegisterType((*ProposeBlockRet)(nil), "pb.ProposeBlockRet")
+	proto.RegisterType((*Priority)(nil), "pb.Priority")
+	proto.RegisterType((*ProposePriorityArgs)(nil), "pb.ProposePriorityArgs")
+	proto.RegisterType((*ProposePriorityRet)(nil), "pb.ProposePriorityRet")
 	proto.RegisterType((*VoteArgs)(nil), "pb.VoteArgs")
 	proto.RegisterType((*VoteRet)(nil), "pb.VoteRet")
 	proto.RegisterType((*SIGRet)(nil), "pb.SIGRet")
+	proto.RegisterType((*EphemeralKey)(nil), "pb.EphemeralKey")
+	proto.RegisterType((*ParticipationKeyFile)(nil), "pb.ParticipationKeyFile")
+	proto.RegisterType((*ParticipationBatch)(nil), "pb.ParticipationBatch")
+	proto.RegisterType((*ParticipationRoundKey)(nil), "pb.ParticipationRoundKey")
+	proto.RegisterType((*Evidence)(nil), "pb.Evidence")
+	proto.RegisterType((*EvidenceList)(nil), "pb.EvidenceList")
+	proto.RegisterType((*Certificate)(nil), "pb.Certificate")
+	proto.RegisterType((*BlockStatus)(nil), "pb.BlockStatus")
+	proto.RegisterType((*Finality)(nil), "pb.Finality")
+	proto.RegisterType((*GetProposalArgs)(nil), "pb.GetProposalArgs")
+	proto.RegisterType((*GetProposalRet)(nil), "pb.GetProposalRet")
 	proto.RegisterType((*RequestBlockChainArgs)(nil), "pb.RequestBlockChainArgs")
 	proto.RegisterType((*RequestBlockChainRet)(nil), "pb.RequestBlockChainRet")
 	proto.RegisterType((*Blockchain)(nil), "pb.Blockchain")
 	proto.RegisterType((*Result)(nil), "pb.Result")
 	proto.RegisterType((*Command)(nil), "pb.Command")
+	proto.RegisterType((*Status)(nil), "pb.Status")
+	proto.RegisterType((*PeerInfo)(nil), "pb.PeerInfo")
+	proto.RegisterType((*PeerInfoList)(nil), "pb.PeerInfoList")
+	proto.RegisterType((*ProposalInfo)(nil), "pb.ProposalInfo")
+	proto.RegisterType((*VoteTally)(nil), "pb.VoteTally")
+	proto.RegisterType((*PeriodStateInfo)(nil), "pb.PeriodStateInfo")
+	proto.RegisterType((*Mempool)(nil), "pb.Mempool")
+	proto.RegisterType((*JournalConfig)(nil), "pb.JournalConfig")
+	proto.RegisterType((*JournalKey)(nil), "pb.JournalKey")
+	proto.RegisterType((*JournalTimeout)(nil), "pb.JournalTimeout")
+	proto.RegisterType((*JournalBlockAssembled)(nil), "pb.JournalBlockAssembled")
+	proto.RegisterType((*JournalSynced)(nil), "pb.JournalSynced")
+	proto.RegisterType((*JournalBlockFetched)(nil), "pb.JournalBlockFetched")
+	proto.RegisterType((*JournalRecord)(nil), "pb.JournalRecord")
+	proto.RegisterType((*Keystore)(nil), "pb.Keystore")
+	proto.RegisterType((*KeystoreEntry)(nil), "pb.KeystoreEntry")
 }
 
 func init() { proto.RegisterFile("bc.proto", fileDescriptor_99e2a20f8b284799) }
 
 var fileDescriptor_99e2a20f8b284799 = []byte{
-	// 758 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0x41, 0x6f, 0xeb, 0x44,
-	0x10, 0x8e, 0xed, 0xd8, 0x4e, 0x26, 0x25, 0x0d, 0xd3, 0x14, 0xdc, 0x14, 0xd1, 0x76, 0x5b, 0xa4,
-	0xd2, 0x4a, 0x05, 0x85, 0x0b, 0x12, 0xa7, 0xa6, 0x44, 0x4d, 0x11, 0xd0, 0xb2, 0x29, 0x1c, 0x38,
-	0xe1, 0xd8, 0xab, 0x34, 0x22, 0xb1, 0xcd, 0xee, 0x26, 0x2a, 0x12, 0xbf, 0x84, 0xcb, 0xfb, 0x27,
-	0xef, 0xb7, 0x3d, 0xed, 0xae, 0x13, 0xbb, 0x49, 0xda, 0xcb, 0xbb, 0x79, 0xe6, 0x9b, 0x99, 0xfd,
-	0x66, 0xe7, 0x9b, 0x35, 0xd4, 0x46, 0xd1, 0x55, 0xc6, 0x53, 0x99, 0xa2, 0x9d, 0x8d, 0x88, 0x0f,
-	0x6e, 0x7f, 0x96, 0xc9, 0x7f, 0x49, 0x1d, 0xfc, 0xe1, 0x3c, 0x8a, 0x98, 0x10, 0xe4, 0x00, 0xdc,
-	0x3e, 0xe7, 0x29, 0xc7, 0x16, 0x38, 0x33, 0x31, 0x0e, 0xac, 0x63, 0xeb, 0xbc, 0x4e, 0xd5, 0x27,
-	0x39, 0x84, 0xc6, 0x23, 0x0f, 0x13, 0x11, 0x46, 0x72, 0x92, 0x26, 0xb8, 0x03, 0xd6, 0x22, 0x87,
-	0xad, 0x05, 0xf9, 0xdf, 0x02, 0xb7, 0x37, 0x4d, 0xa3, 0xbf, 0xb1, 0x09, 0xf6, 0x24, 0xd6, 0x80,
-	0x43, 0xed, 0x49, 0x8c, 0x5f, 0x40, 0x5d, 0x4e, 0x66, 0x4c, 0xc8, 0x70, 0x96, 0x05, 0xb6, 0x8e,
-	0x2f, 0x1c, 0xd8, 0x81, 0x5a, 0xc6, 0xd9, 0x62, 0x10, 0x8a, 0xa7, 0xc0, 0xd1, 0xe0, 0xca, 0x46,
-	0x84, 0xea, 0x93, 0xf2, 0x57, 0xb5, 0x5f, 0x7f, 0xe3, 0x11, 0xd8, 0xf2, 0x39, 0x70, 0x8f, 0x9d,
-	0xf3, 0x46, 0x77, 0xf7, 0x2a, 0x1b, 0x5d, 0x95, 0x28, 0x51, 0x5b, 0x3e, 0xab, 0x24, 0xc1, 0x58,
-	0x1c, 0x78, 0x26, 0x49, 0x7d, 0x93, 0x07, 0xd8, 0xbd, 0xce, 0x32, 0x96, 0xc4, 0x9a, 0xe1, 0x35,
-	0x1f, 0x0b, 0x15, 0x96, 0x31, 0xc6, 0xf3, 0x06, 0xf4, 0x37, 0x7e, 0x0d, 0x30, 0x52, 0x01, 0xd1,
-	0x53, 0x38, 0x49, 0x02, 0x5b, 0x9f, 0x51, 0x57, 0x67, 0xe8, 0x34, 0x5a, 0x02, 0xc9, 0x05, 0x34,
-	0x4b, 0x15, 0x29, 0x93, 0x18, 0x80, 0x2f, 0xcc, 0x1d, 0xea, 0x9a, 0x35, 0xba, 0x34, 0xc9, 0xcf,
-	0xb0, 0x6f, 0x62, 0x4b, 0x54, 0x5f, 0xe5, 0x60, 0xfa, 0x53, 0xd7, 0xb4, 0xbd, 0x3f, 0xf2, 0x2d,
-	0xb4, 0x37, 0xaa, 0xbd, 0x7d, 0xfe, 0x3b, 0x0b, 0x5a, 0x0f, 0x3c, 0xcd, 0x52, 0xc1, 0x8a, 0xfe,
-	0x2f, 0x00, 0x22, 0xce, 0x62, 0x96, 0xc8, 0x49, 0x38, 0xd5, 0x19, 0x8d, 0x2e, 0xa8, 0xf3, 0x86,
-	0x77, 0xb7, 0x94, 0x49, 0x5a, 0x42, 0xf1, 0x08, 0x5c, 0xdd, 0x7a, 0x4e, 0xab, 0x74, 0x25, 0xc6,
-	0x8f, 0x6d, 0x70, 0x17, 0xe1, 0x74, 0xce, 0xf2, 0x09, 0x1a, 0x43, 0x79, 0x79, 0x3a, 0x4f, 0x62,
-	0x3d, 0x3f, 0x87, 0x1a, 0x63, 0xd5, 0xb4, 0x5b, 0x34, 0x4d, 0x2e, 0x61, 0xb7, 0x4c, 0xf0, 0xed,
-	0x76, 0xfe, 0x84, 0xda, 0x1f, 0xa9, 0x64, 0xba, 0x8b, 0x33, 0xf0, 0x67, 0x4c, 0x88, 0x70, 0xcc,
-	0xb6, 0xb4, 0xb0, 0x84, 0x0a, 0x22, 0xf6, 0x36, 0x22, 0x4e, 0x89, 0xc8, 0x29, 0xf8, 0xaa, 0xf6,
-	0xdb, 0x04, 0xfe, 0x02, 0xcf, 0x9c, 0x80, 0x9f, 0x81, 0x37, 0x17, 0x8c, 0xdf, 0xc5, 0xf9, 0x08,
-	0x73, 0x4b, 0xe5, 0x2e, 0x69, 0x29, 0x15, 0xd5, 0x0b, 0x2a, 0x67, 0xf0, 0x89, 0x98, 0x8c, 0x13,
-	0x16, 0xff, 0x92, 0xe3, 0xe6, 0xf4, 0x97, 0x4e, 0x72, 0x09, 0xfb, 0x94, 0xfd, 0x33, 0x67, 0x42,
-	0xea, 0xfb, 0xb8, 0x51, 0x92, 0x7b, 0x4d, 0x31, 0xe4, 0x77, 0x68, 0x6f, 0x04, 0x2b, 0x72, 0x1f,
-	0xa9, 0xf0, 0x6f, 0x00, 0x7a, 0x2b, 0x0b, 0x4f, 0xc0, 0xd3, 0x98, 0xba, 0x8c, 0xb5, 0xa4, 0x1c,
-	0x20, 0xbf, 0x81, 0x47, 0x99, 0x98, 0x4f, 0x25, 0x1e, 0x83, 0x3d, 0x8a, 0xf2, 0x81, 0x34, 0x57,
-	0x81, 0xba, 0xd0, 0xa0, 0x42, 0xed, 0x51, 0x84, 0x87, 0x60, 0x89, 0x5c, 0x4d, 0x0d, 0x3d, 0x31,
-	0x73, 0xb5, 0x83, 0x0a, 0xb5, 0x44, 0xaf, 0x06, 0x1e, 0xd7, 0x85, 0xc8, 0x7f, 0xe0, 0xdf, 0xa4,
-	0xb3, 0x59, 0x98, 0xc4, 0x78, 0x06, 0xf5, 0x34, 0x63, 0x3c, 0x54, 0x72, 0xd7, 0xa5, 0x9b, 0x5d,
-	0x4f, 0x65, 0xde, 0x67, 0xb4, 0x00, 0xf0, 0x04, 0x5c, 0xa6, 0x5e, 0xb4, 0xb2, 0x52, 0xf5, 0x13,
-	0x37, 0xa8, 0x50, 0x83, 0xe0, 0x89, 0x5e, 0x30, 0x67, 0xeb, 0x82, 0x29, 0x76, 0xf2, 0xb9, 0xe7,
-	0x82, 0x13, 0xf2, 0xf1, 0xc5, 0xe7, 0x60, 0xdf, 0x67, 0xe8, 0x83, 0x73, 0xdb, 0x7f, 0x6c, 0x55,
-	0xb0, 0x06, 0xd5, 0x61, 0xff, 0xd7, 0x1f, 0x5b, 0x56, 0xf7, 0xbd, 0x0d, 0xb5, 0xeb, 0xe9, 0x38,
-	0xe5, 0x8a, 0xd8, 0xf7, 0xd0, 0x28, 0xbd, 0x04, 0xb8, 0xa7, 0x4a, 0xae, 0x3d, 0x36, 0x1d, 0x5c,
-	0x73, 0x52, 0x26, 0x49, 0x05, 0x7f, 0x82, 0x4f, 0x37, 0x36, 0x19, 0x0f, 0x8a, 0xd0, 0xb5, 0xe7,
-	0xa2, 0x13, 0x6c, 0x85, 0x4c, 0xad, 0x1f, 0x60, 0xa7, 0xbc, 0x41, 0xd8, 0x56, 0xb1, 0xeb, 0x4b,
-	0xdf, 0xd9, 0x5b, 0xf7, 0x9a, 0xe4, 0x53, 0xa8, 0x2a, 0xd5, 0xe3, 0x8e, 0x82, 0x97, 0xbb, 0xd5,
-	0x69, 0x2c, 0xad, 0x15, 0xdb, 0x0d, 0x99, 0x19, 0xb6, 0x5b, 0xa5, 0x6a, 0xd8, 0x6e, 0x13, 0x26,
-	0xa9, 0x74, 0x1f, 0xc0, 0xef, 0xdd, 0x0c, 0x65, 0xca, 0x19, 0x7e, 0x09, 0xce, 0x2d, 0x93, 0x58,
-	0x4c, 0xaa, 0x03, 0x26, 0x51, 0x0b, 0xa0, 0x82, 0x5f, 0x41, 0x75, 0xc8, 0x92, 0x18, 0xd7, 0x47,
-	0xf5, 0x32, 0x6c, 0xe4, 0xe9, 0xbf, 0xda, 0x77, 0x1f, 0x02, 0x00, 0x00, 0xff, 0xff, 0x8c, 0xcd,
-	0x0b, 0x19, 0xe1, 0x06, 0x00, 0x00,
+	// 2506 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x18, 0x4b, 0x8f, 0xe4, 0x46,
+	0xb9, 0x6d, 0xf7, 0xf3, 0xeb, 0x9e, 0x47, 0x6a, 0x67, 0x07, 0xef, 0x10, 0x25, 0x9d, 0x4a, 0x48,
+	0x26, 0xbb, 0x68, 0x08, 0xbd, 0x02, 0x12, 0x71, 0xda, 0x99, 0x9d, 0x9d, 0x99, 0xec, 0x23, 0x23,
+	0xf7, 0x6a, 0x25, 0x10, 0x42, 0x72, 0xbb, 0x6b, 0x7a, 0xac, 0x71, 0xdb, 0x5e, 0xbb, 0xba, 0xd9,
+	0x46, 0x5c, 0x40, 0xe2, 0xc2, 0x81, 0x43, 0x4e, 0x39, 0x22, 0xa1, 0x5c, 0x90, 0x80, 0x23, 0x37,
+	0x4e, 0x08, 0x89, 0x9f, 0xc1, 0x3f, 0x41, 0x5f, 0x3d, 0xec, 0x6a, 0x4f, 0xf7, 0x2c, 0x2b, 0x05,
+	0x71, 0xf3, 0xf7, 0x70, 0xd5, 0xf7, 0x7e, 0x14, 0xb4, 0x47, 0xc1, 0x41, 0x9a, 0x25, 0x3c, 0x21,
+	0x76, 0x3a, 0xa2, 0x2d, 0x68, 0x1c, 0x4f, 0x53, 0xbe, 0xa0, 0x1d, 0x68, 0x0d, 0x67, 0x41, 0xc0,
+	0xf2, 0x9c, 0xde, 0x81, 0xc6, 0x71, 0x96, 0x25, 0x19, 0xd9, 0x06, 0x67, 0x9a, 0x4f, 0x5c, 0xab,
+	0x6f, 0xed, 0x77, 0x3c, 0xfc, 0xa4, 0xff, 0xb0, 0xa0, 0xfb, 0x3c, 0xf3, 0xe3, 0xdc, 0x0f, 0x78,
+	0x98, 0xc4, 0xa4, 0x07, 0xd6, 0x5c, 0xd1, 0xad, 0x39, 0x21, 0x50, 0xbf, 0xc8, 0x92, 0xa9, 0x6b,
+	0x0b, 0x84, 0xf8, 0x26, 0x9b, 0x60, 0xf3, 0xc4, 0x75, 0x04, 0xc6, 0xe6, 0x09, 0xd9, 0x85, 0xa6,
+	0x3f, 0x4d, 0x66, 0x31, 0x77, 0xeb, 0x7d, 0x6b, 0xdf, 0xf1, 0x14, 0x44, 0x76, 0xa0, 0x11, 0x27,
+	0x71, 0xc0, 0xdc, 0x86, 0x40, 0x4b, 0x80, 0xbc, 0x0d, 0x9d, 0x3c, 0x9c, 0xc4, 0x3e, 0x9f, 0x65,
+	0xcc, 0x6d, 0x8a, 0x43, 0x4a, 0x04, 0xf9, 0x11, 0x6c, 0xa4, 0x7e, 0xc6, 0xc3, 0x20, 0x4c, 0x7d,
+	0x14, 0xc7, 0x6d, 0xf5, 0xad, 0xfd, 0xee, 0xe0, 0xad, 0x83, 0x74, 0x74, 0x70, 0x6e, 0x12, 0xbc,
+	0x65, 0x3e, 0xfa, 0x0b, 0xd8, 0x58, 0xa2, 0xa3, 0x54, 0xb3, 0x9c, 0x65, 0x67, 0x63, 0xa5, 0x8c,
+	0x82, 0xd0, 0x02, 0x57, 0x6c, 0xa1, 0x14, 0xc2, 0x4f, 0xf2, 0x0e, 0xc0, 0x45, 0x98, 0xe5, 0xdc,
+	0x4b, 0x66, 0xf1, 0x58, 0xe8, 0xe5, 0x78, 0x06, 0x06, 0x25, 0x8e, 0x7c, 0x4d, 0x96, 0x2a, 0x96,
+	0x08, 0xfa, 0xa5, 0x0d, 0x8d, 0xc3, 0x28, 0x09, 0xae, 0xd0, 0x2e, 0xa1, 0xbc, 0xcd, 0xf1, 0xec,
+	0x50, 0xfc, 0xc7, 0xc3, 0x29, 0xcb, 0xb9, 0x3f, 0x4d, 0xd5, 0x7d, 0x25, 0x82, 0xec, 0x41, 0x3b,
+	0xcd, 0xd8, 0xfc, 0xd4, 0xcf, 0x2f, 0x95, 0x2d, 0x0b, 0x18, 0xad, 0x7e, 0x89, 0xf8, 0xba, 0xb4,
+	0x3a, 0x7e, 0x93, 0x77, 0xc1, 0xe6, 0xaf, 0xdc, 0x46, 0xdf, 0xd9, 0xef, 0x0e, 0xb6, 0xd0, 0x1c,
+	0x86, 0xd3, 0x3c, 0x9b, 0xbf, 0xc2, 0x9f, 0x72, 0xc6, 0xc6, 0xca, 0xa6, 0xe2, 0x5b, 0x5e, 0x92,
+	0xf0, 0x24, 0x48, 0x22, 0xb7, 0xa5, 0x2f, 0x91, 0x30, 0xe9, 0x43, 0x77, 0x96, 0x4e, 0x32, 0x7f,
+	0xcc, 0x5e, 0x24, 0x9c, 0xb9, 0x6d, 0x41, 0x36, 0x51, 0xea, 0xef, 0x34, 0xc9, 0x59, 0xe6, 0x76,
+	0x8a, 0xbf, 0x05, 0x2c, 0xdc, 0xc8, 0xd8, 0xf8, 0x3c, 0x4b, 0x92, 0x0b, 0x17, 0x94, 0x1b, 0x35,
+	0x82, 0xfe, 0xd6, 0x82, 0xad, 0x07, 0x69, 0xca, 0xe2, 0xb1, 0x30, 0xcd, 0x83, 0x6c, 0x92, 0xa3,
+	0x7c, 0x29, 0x63, 0x99, 0x72, 0x87, 0xf8, 0x26, 0x1f, 0x03, 0x8c, 0x90, 0x21, 0xb8, 0xf4, 0xc3,
+	0xd8, 0xb5, 0x85, 0x72, 0x1d, 0x54, 0x4e, 0xfc, 0xe6, 0x19, 0x44, 0x72, 0x1f, 0x7a, 0x01, 0xcb,
+	0x78, 0x78, 0x11, 0x06, 0x3e, 0x67, 0xb9, 0xeb, 0x94, 0x96, 0x38, 0x2a, 0xf1, 0xde, 0x12, 0x13,
+	0xbd, 0x0b, 0x9b, 0x86, 0x18, 0x1e, 0xe3, 0xc4, 0x85, 0x56, 0x2e, 0x93, 0x42, 0x08, 0xd2, 0xf6,
+	0x34, 0x48, 0x9f, 0xc0, 0x6d, 0xc9, 0x6b, 0x18, 0x76, 0xad, 0xe0, 0xd2, 0x1b, 0x76, 0xdf, 0xd2,
+	0x32, 0x54, 0xbc, 0x41, 0x3f, 0x81, 0x9d, 0x6b, 0xa7, 0xdd, 0x7c, 0xff, 0xbf, 0x2c, 0xd8, 0x3e,
+	0x97, 0xe6, 0x2d, 0x8d, 0x76, 0x17, 0x20, 0xc8, 0xd8, 0x98, 0xc5, 0x3c, 0xf4, 0x23, 0xf1, 0x47,
+	0x77, 0x00, 0x78, 0xdf, 0xf0, 0xec, 0xc4, 0x63, 0xdc, 0x33, 0xa8, 0xe4, 0x5d, 0x68, 0x08, 0x7b,
+	0x29, 0xb1, 0x0c, 0x3b, 0x4a, 0x3c, 0x26, 0xe4, 0xdc, 0x8f, 0x66, 0x4c, 0xc5, 0x9b, 0x04, 0x10,
+	0x9b, 0x19, 0xa1, 0x2d, 0x81, 0x42, 0xe9, 0x86, 0xa1, 0xf4, 0x3e, 0xc6, 0x43, 0x98, 0x64, 0x21,
+	0x5f, 0x88, 0x28, 0xeb, 0x0e, 0x7a, 0x22, 0x2f, 0x15, 0xce, 0x2b, 0xa8, 0xf4, 0x1e, 0x6c, 0x99,
+	0xaa, 0xdc, 0xac, 0xf8, 0x57, 0x16, 0xb4, 0xf5, 0x19, 0x6b, 0xd3, 0xb6, 0x90, 0xd2, 0x36, 0xa5,
+	0xdc, 0x85, 0x66, 0xca, 0xb2, 0x30, 0xd1, 0x69, 0xab, 0xa0, 0x52, 0xd3, 0x7a, 0x45, 0xd3, 0x54,
+	0xc4, 0xab, 0x54, 0x4a, 0x02, 0x32, 0xca, 0x0d, 0xad, 0x3a, 0x86, 0x1e, 0x43, 0xb8, 0xa5, 0xf4,
+	0xd0, 0x02, 0x0a, 0xaf, 0x98, 0x86, 0xb0, 0x6e, 0x32, 0x44, 0x61, 0x46, 0xbb, 0x34, 0x23, 0x3d,
+	0x00, 0x52, 0x39, 0xf4, 0x66, 0xfb, 0xfc, 0x14, 0xda, 0x98, 0x8e, 0xe2, 0xe6, 0x0f, 0xa0, 0x35,
+	0x65, 0x79, 0xee, 0x4f, 0xd8, 0x8a, 0x60, 0xd0, 0xa4, 0x35, 0xc6, 0xd2, 0xb2, 0x38, 0x86, 0x2c,
+	0xef, 0x43, 0x0b, 0xcf, 0xbe, 0x59, 0x80, 0xbf, 0x58, 0xd0, 0x94, 0x57, 0xac, 0x75, 0x8f, 0x5b,
+	0xca, 0x85, 0x59, 0xdc, 0x29, 0x65, 0xf9, 0x00, 0x36, 0xb0, 0xbc, 0xb3, 0xf1, 0x53, 0x45, 0x97,
+	0xd7, 0x2f, 0x23, 0x97, 0xbb, 0x42, 0xbd, 0xda, 0x15, 0x0e, 0xa0, 0xc3, 0xd2, 0x4b, 0x36, 0x65,
+	0x99, 0x1f, 0x09, 0xe7, 0x75, 0x07, 0xdb, 0xa8, 0xf7, 0xb1, 0x46, 0x3e, 0x66, 0x0b, 0xaf, 0x64,
+	0xa1, 0xbf, 0xb1, 0xa0, 0x67, 0xd2, 0x74, 0xd1, 0xb7, 0xca, 0xa2, 0xbf, 0x07, 0xed, 0x91, 0xcf,
+	0x83, 0xcb, 0xc7, 0x45, 0x2f, 0x28, 0x60, 0xf2, 0x21, 0x6c, 0x8a, 0xef, 0x61, 0x21, 0x91, 0x94,
+	0xb9, 0x82, 0xbd, 0x59, 0x68, 0xfa, 0x6f, 0x0b, 0x76, 0x96, 0x5a, 0xd2, 0x63, 0xb6, 0x78, 0x14,
+	0x46, 0x0c, 0xfd, 0x90, 0x25, 0x09, 0xd7, 0xf5, 0x04, 0xbf, 0x2b, 0x3d, 0xc8, 0xbe, 0xb9, 0x07,
+	0x39, 0x95, 0x1e, 0x84, 0x27, 0xc6, 0xec, 0x95, 0xee, 0xbf, 0xe2, 0x9b, 0x7c, 0x02, 0x2d, 0x21,
+	0x2e, 0xcb, 0x55, 0xd3, 0xd8, 0xbd, 0xd6, 0x43, 0x0f, 0x91, 0xee, 0x69, 0x36, 0xf2, 0x7d, 0x68,
+	0x8a, 0x40, 0xc9, 0xdd, 0xa6, 0xf8, 0xe1, 0xce, 0xb5, 0x1f, 0xc4, 0x6d, 0x68, 0x6b, 0xc5, 0x48,
+	0x7f, 0x06, 0xe4, 0xfa, 0x89, 0x18, 0x7e, 0xe2, 0x4c, 0xd5, 0x0b, 0x25, 0x50, 0xf4, 0x27, 0xdb,
+	0xe8, 0x4f, 0x4b, 0x16, 0x74, 0xaa, 0x16, 0xfc, 0xa3, 0x05, 0xb7, 0x57, 0xde, 0x5f, 0x06, 0xb8,
+	0x55, 0x09, 0xf0, 0x37, 0xbb, 0x61, 0x29, 0x0a, 0xea, 0xaf, 0x8d, 0x82, 0xc6, 0xaa, 0x28, 0xa0,
+	0x7f, 0xb2, 0xa0, 0x7d, 0x3c, 0x0f, 0xc7, 0x0c, 0xa7, 0x9b, 0x6f, 0xa6, 0x7c, 0xa1, 0x22, 0x9c,
+	0xa5, 0xda, 0x9f, 0xf8, 0x4d, 0xfa, 0xd0, 0x10, 0xf1, 0xe0, 0x36, 0xae, 0xe5, 0xbd, 0x24, 0x10,
+	0x0a, 0xcd, 0x9c, 0x05, 0x49, 0x3c, 0x76, 0x9b, 0xd7, 0x58, 0x14, 0x85, 0x7e, 0x0a, 0x3d, 0x2d,
+	0xeb, 0x93, 0x30, 0xe7, 0x58, 0xc9, 0x98, 0x82, 0x5d, 0xab, 0xef, 0xe8, 0x4a, 0xa6, 0x79, 0xbc,
+	0x82, 0x4a, 0x73, 0xe8, 0x1a, 0x7d, 0x76, 0x8d, 0x07, 0x4a, 0x85, 0xec, 0xd5, 0xf5, 0x78, 0xa9,
+	0xf3, 0xf4, 0xa1, 0x31, 0x4f, 0xb0, 0x97, 0xd7, 0xfb, 0x4e, 0x45, 0x5e, 0x49, 0xa0, 0x4f, 0xa1,
+	0x2b, 0x1a, 0xc8, 0x90, 0xfb, 0x7c, 0x96, 0xaf, 0x77, 0xbb, 0x98, 0x96, 0x6c, 0x63, 0x5a, 0xda,
+	0x41, 0x6b, 0xc5, 0x7e, 0x24, 0x2e, 0x6c, 0x7b, 0x12, 0xa0, 0x43, 0x68, 0x3f, 0xc2, 0x0f, 0xac,
+	0xcc, 0x22, 0xe3, 0x62, 0x3f, 0xf2, 0x8c, 0x03, 0x0d, 0x0c, 0xf9, 0x08, 0x9a, 0xa2, 0x6b, 0xe6,
+	0x6a, 0x2c, 0xd9, 0x2a, 0xda, 0xa9, 0x14, 0xc6, 0x53, 0x64, 0x1a, 0xc2, 0xd6, 0x09, 0xe3, 0xb2,
+	0xa2, 0xfb, 0x91, 0xa8, 0xd2, 0xdf, 0x84, 0x71, 0x74, 0xb5, 0xae, 0x1b, 0xd5, 0xfa, 0x04, 0x36,
+	0x8d, 0xab, 0xb0, 0x1e, 0xa3, 0x9e, 0xc5, 0x4d, 0x6d, 0x4f, 0x02, 0xaf, 0x9d, 0x04, 0xe8, 0x3d,
+	0xb8, 0xed, 0xb1, 0x97, 0x33, 0x96, 0x73, 0x81, 0x3e, 0xc2, 0x09, 0x6b, 0xdd, 0xac, 0x43, 0x7f,
+	0x67, 0xc1, 0xce, 0x35, 0x6e, 0xbc, 0xfc, 0xff, 0x31, 0xd1, 0x7d, 0x0f, 0xe0, 0xb0, 0x3c, 0xe2,
+	0xbd, 0xc2, 0x49, 0x56, 0xf5, 0x26, 0xed, 0x9e, 0x3f, 0x5b, 0xd0, 0xf4, 0x58, 0x3e, 0x8b, 0x38,
+	0xe9, 0x83, 0x3d, 0x0a, 0x54, 0xdf, 0xdc, 0x2c, 0x38, 0xc5, 0x49, 0xa7, 0x35, 0xcf, 0x1e, 0x05,
+	0xe4, 0xdb, 0x60, 0xe5, 0xca, 0x68, 0x5d, 0x11, 0x8d, 0xb2, 0x03, 0x9e, 0xd6, 0x3c, 0x2b, 0x27,
+	0x07, 0x46, 0xae, 0x38, 0x46, 0x13, 0x32, 0xf2, 0xe9, 0xb4, 0x56, 0x66, 0x0c, 0xb9, 0x0b, 0xed,
+	0x0b, 0x15, 0x6d, 0xc2, 0x8b, 0x2a, 0xb7, 0x74, 0x04, 0x22, 0xaf, 0xa6, 0x1f, 0xb6, 0xa1, 0x99,
+	0x09, 0x21, 0xe9, 0xaf, 0xa0, 0x75, 0x94, 0x4c, 0xa7, 0x7e, 0x3c, 0x26, 0x1f, 0x40, 0x27, 0x49,
+	0x59, 0x26, 0x17, 0x21, 0x14, 0x7b, 0x73, 0xd0, 0xc4, 0x13, 0xbe, 0x48, 0xbd, 0x92, 0x40, 0xde,
+	0x83, 0x06, 0xc3, 0x7d, 0xcf, 0x74, 0xb6, 0x58, 0x00, 0x4f, 0x6b, 0x9e, 0xa4, 0x90, 0xf7, 0xc4,
+	0xb4, 0xea, 0xac, 0x9c, 0x56, 0x51, 0x73, 0xfe, 0xea, 0xb0, 0x01, 0x8e, 0x9f, 0x4d, 0xe8, 0x3f,
+	0x6d, 0x68, 0xaa, 0x64, 0xfb, 0x5f, 0x96, 0xb2, 0xae, 0x30, 0xfa, 0x13, 0x16, 0x4f, 0xf8, 0xa5,
+	0x5a, 0x0f, 0x4d, 0x14, 0x0e, 0x0d, 0xd8, 0xdd, 0x84, 0x7f, 0xc4, 0x86, 0x24, 0x07, 0xb3, 0x65,
+	0xe4, 0x8d, 0xdb, 0xcd, 0x2e, 0x34, 0x2f, 0xfd, 0x88, 0xb3, 0xb1, 0x58, 0x6c, 0xda, 0x9e, 0x82,
+	0xf0, 0xee, 0x29, 0x9b, 0xa6, 0x49, 0x12, 0x0d, 0xc3, 0x5f, 0x32, 0xb1, 0xd6, 0x38, 0x9e, 0x89,
+	0xc2, 0xbb, 0x33, 0xf6, 0x72, 0x16, 0x66, 0x6c, 0xfc, 0x42, 0x54, 0x27, 0x10, 0x3c, 0xcb, 0xc8,
+	0x4a, 0xf9, 0xe8, 0x56, 0xcb, 0x07, 0xfd, 0xbd, 0x0d, 0xed, 0x73, 0xc6, 0xb2, 0xb3, 0xf8, 0x22,
+	0xc1, 0xe9, 0xc8, 0x1f, 0x8f, 0x33, 0x3d, 0x5a, 0x75, 0x3c, 0x0d, 0x1a, 0x46, 0xb6, 0xab, 0x46,
+	0xce, 0xb9, 0x7f, 0xc5, 0x94, 0x35, 0x25, 0xa0, 0xb0, 0xbc, 0x18, 0x6b, 0x05, 0x80, 0x2d, 0x2e,
+	0x48, 0xe2, 0x98, 0x05, 0xa8, 0x6d, 0x43, 0x68, 0x5b, 0x22, 0xd0, 0x48, 0x68, 0xb5, 0x21, 0x63,
+	0xb1, 0xb0, 0xa2, 0xe3, 0x15, 0xb0, 0x9e, 0x2a, 0xc4, 0xd3, 0x80, 0xb2, 0x60, 0x89, 0x40, 0xa9,
+	0x83, 0x30, 0x0b, 0x66, 0x21, 0x57, 0xcb, 0xa1, 0x06, 0x51, 0xea, 0x97, 0x33, 0x36, 0x63, 0x63,
+	0x65, 0x3f, 0x05, 0xe1, 0x5d, 0x61, 0xfc, 0x28, 0x0a, 0x27, 0x97, 0x5c, 0x59, 0xad, 0x80, 0xe9,
+	0x00, 0x7a, 0xda, 0x1e, 0xa2, 0xf3, 0x50, 0x68, 0x60, 0xc1, 0xc8, 0xcd, 0xb6, 0xa3, 0x19, 0x3c,
+	0x49, 0xa2, 0x3f, 0x87, 0x9e, 0x2e, 0x76, 0xc2, 0x8e, 0x7b, 0x95, 0xb9, 0xdb, 0x18, 0xd5, 0xcb,
+	0x2a, 0x6a, 0x9b, 0x55, 0xf4, 0x6d, 0xe8, 0x5c, 0xfa, 0x73, 0xb9, 0x85, 0xa8, 0x5e, 0x50, 0x22,
+	0x68, 0x00, 0x1d, 0xf4, 0xe6, 0x73, 0x3f, 0x8a, 0x16, 0x46, 0x04, 0x5b, 0xd5, 0x08, 0xe6, 0x8b,
+	0x54, 0x9f, 0x2b, 0xbe, 0xd7, 0x6f, 0x52, 0xba, 0x9f, 0x09, 0xa7, 0x09, 0x80, 0x7e, 0x65, 0xc3,
+	0xd6, 0xb9, 0x38, 0x0a, 0x13, 0x8b, 0x09, 0x45, 0xde, 0xac, 0x41, 0xe8, 0x1c, 0x72, 0x8c, 0x1c,
+	0xc2, 0xb1, 0x9a, 0xe3, 0x6c, 0x14, 0x4f, 0x5e, 0x18, 0x9b, 0xce, 0x32, 0x12, 0xa3, 0x74, 0xba,
+	0xc0, 0x62, 0x2a, 0x56, 0x7c, 0x39, 0xbf, 0x18, 0x98, 0xeb, 0xb1, 0xde, 0x5c, 0x15, 0xeb, 0x07,
+	0xd0, 0x49, 0x95, 0x1b, 0x72, 0xb7, 0xd5, 0x77, 0x74, 0xe5, 0x33, 0x7d, 0xe3, 0x95, 0x2c, 0xe4,
+	0x23, 0x68, 0x71, 0x3f, 0x8a, 0x42, 0x96, 0xbb, 0x6d, 0xc1, 0xbd, 0x81, 0xdc, 0x85, 0xa5, 0x3d,
+	0x4d, 0xa5, 0x77, 0xa1, 0xf5, 0x54, 0x66, 0x9e, 0x5a, 0xa8, 0xad, 0xb5, 0xcf, 0x1b, 0xf4, 0xd7,
+	0x0e, 0x6c, 0x7c, 0x9e, 0xcc, 0xb2, 0xd8, 0x8f, 0x8e, 0x92, 0xf8, 0x22, 0x9c, 0xac, 0x2d, 0x50,
+	0xef, 0x00, 0xa4, 0x59, 0x38, 0xf7, 0x39, 0xd3, 0xc3, 0xbd, 0xe3, 0x19, 0x18, 0xa4, 0x07, 0x7e,
+	0x3c, 0x0e, 0xc7, 0x45, 0xd7, 0xe9, 0x78, 0x06, 0x06, 0x5f, 0xc0, 0xae, 0x94, 0x0b, 0xad, 0xab,
+	0xeb, 0x26, 0x6a, 0xac, 0x32, 0xd1, 0x2e, 0x34, 0x23, 0x7f, 0x3a, 0x1a, 0xfb, 0xca, 0x82, 0x0a,
+	0xc2, 0xf8, 0x1b, 0x85, 0x93, 0x27, 0x92, 0xd4, 0x12, 0xa4, 0x12, 0x21, 0xce, 0x46, 0xcf, 0x9f,
+	0xc5, 0x9c, 0x65, 0x73, 0x3f, 0x72, 0xdb, 0xea, 0x6c, 0x13, 0x49, 0x28, 0xf4, 0x2e, 0x66, 0x38,
+	0x6a, 0x7a, 0x72, 0x3a, 0x97, 0x39, 0xb7, 0x84, 0xc3, 0x61, 0x55, 0xc2, 0x6a, 0xa1, 0xd2, 0x55,
+	0xab, 0x82, 0x25, 0xfb, 0xd0, 0x9a, 0x67, 0x17, 0x8f, 0xd9, 0x22, 0x77, 0xbb, 0x7d, 0x47, 0xf7,
+	0x41, 0x65, 0x57, 0x9c, 0xec, 0x35, 0xb9, 0x18, 0xa6, 0x7b, 0xe5, 0x30, 0x4d, 0x7f, 0x08, 0x50,
+	0xb2, 0xfe, 0xf7, 0x2f, 0x6c, 0xf4, 0x43, 0xd8, 0x54, 0xff, 0x3d, 0x0f, 0xa7, 0x2c, 0x99, 0x89,
+	0xb9, 0x05, 0x9f, 0xc2, 0x32, 0x9d, 0x00, 0x02, 0xa0, 0x5f, 0x5a, 0x70, 0x5b, 0x31, 0xca, 0x27,
+	0x90, 0x3c, 0x67, 0xd3, 0x51, 0xc4, 0xc6, 0x6f, 0x98, 0x30, 0xc5, 0xfc, 0xe3, 0xbc, 0xee, 0x25,
+	0xe4, 0xf5, 0xef, 0x03, 0xf4, 0xb3, 0x22, 0xee, 0x86, 0x8b, 0x38, 0x58, 0x2b, 0xcb, 0x8a, 0xe5,
+	0x83, 0x8e, 0xe0, 0x96, 0xa9, 0xce, 0x23, 0x86, 0x8b, 0xd6, 0xba, 0x03, 0x56, 0x17, 0xb0, 0xd7,
+	0xa9, 0x42, 0xbf, 0xae, 0x17, 0xf2, 0x79, 0x2c, 0x48, 0x32, 0x59, 0xb0, 0xc2, 0x29, 0x53, 0xa7,
+	0x8b, 0x6f, 0xf2, 0xb1, 0xe8, 0x1c, 0x19, 0x77, 0xed, 0xf2, 0x3d, 0x75, 0x29, 0x9b, 0x70, 0x58,
+	0x10, 0x1c, 0xe4, 0x00, 0x5a, 0x5c, 0x7a, 0x49, 0xdd, 0x49, 0x0c, 0x66, 0xe5, 0xbf, 0xd3, 0x9a,
+	0xa7, 0x99, 0xc8, 0x40, 0xbf, 0x12, 0xfa, 0x91, 0x1a, 0x73, 0x76, 0xca, 0xe2, 0x50, 0x3e, 0x65,
+	0xe1, 0xb8, 0xa3, 0xf9, 0x08, 0x85, 0xfa, 0x5c, 0x57, 0x24, 0x55, 0xfb, 0xf5, 0x13, 0xc7, 0x69,
+	0xcd, 0x13, 0x34, 0xf2, 0x19, 0x74, 0x7c, 0xed, 0x7f, 0xb5, 0xd1, 0xdc, 0x31, 0x24, 0x59, 0x0e,
+	0x90, 0xd3, 0x9a, 0x57, 0x72, 0x93, 0x7b, 0xd0, 0xcc, 0x85, 0xaf, 0xcc, 0xe7, 0xe3, 0x25, 0x27,
+	0x9e, 0xd6, 0x3c, 0xc5, 0x42, 0xee, 0x43, 0xeb, 0x42, 0x3a, 0xc6, 0xdd, 0x10, 0xdc, 0xdf, 0xaa,
+	0xde, 0xa2, 0xfc, 0x86, 0x4a, 0x2b, 0x4e, 0xf2, 0x03, 0xa3, 0x13, 0x6d, 0x96, 0x7f, 0xad, 0x78,
+	0x2c, 0x92, 0x7a, 0x4b, 0x58, 0x0c, 0x02, 0xa2, 0xa4, 0xc9, 0xca, 0xd8, 0xf1, 0x34, 0x88, 0xde,
+	0x67, 0xa2, 0x0d, 0xcb, 0x87, 0x56, 0x09, 0x94, 0x91, 0x02, 0xab, 0xc3, 0xbe, 0xbb, 0xb2, 0x4f,
+	0xf4, 0xca, 0x3e, 0x71, 0xd8, 0x82, 0x06, 0x9b, 0xb3, 0x98, 0xd3, 0xbf, 0x5a, 0xd0, 0xc6, 0xc4,
+	0xe6, 0x49, 0xc6, 0x50, 0x8e, 0x3c, 0xc8, 0x16, 0x29, 0x7f, 0xa6, 0xa2, 0x44, 0x83, 0x25, 0xc5,
+	0x53, 0x39, 0xa5, 0xc1, 0x92, 0x72, 0xae, 0x1a, 0x91, 0x06, 0xc5, 0xbd, 0x7e, 0xc4, 0xf5, 0xaa,
+	0x82, 0xdf, 0x28, 0x79, 0x70, 0xc9, 0x82, 0x2b, 0x9d, 0x4b, 0x02, 0x20, 0xf7, 0xa0, 0xc5, 0x62,
+	0x9e, 0x85, 0x4c, 0xbf, 0x31, 0x08, 0xcf, 0x68, 0xb1, 0x8e, 0x63, 0x9e, 0x2d, 0x3c, 0xcd, 0x41,
+	0xbf, 0xb6, 0x60, 0x63, 0x89, 0x84, 0x17, 0xc5, 0xbe, 0x8a, 0xec, 0x8e, 0x27, 0xbe, 0x11, 0x77,
+	0x15, 0xc6, 0x45, 0xde, 0x5d, 0x85, 0xca, 0x40, 0xb3, 0x51, 0x14, 0x06, 0xaa, 0x3f, 0x2b, 0xa8,
+	0xf2, 0xca, 0x52, 0xbf, 0xf9, 0x95, 0xa5, 0x51, 0x7d, 0x65, 0xd9, 0xc5, 0xfd, 0xda, 0x8f, 0x8a,
+	0x27, 0x76, 0x05, 0xdd, 0xbd, 0x0f, 0xf6, 0x17, 0x29, 0x69, 0x81, 0x73, 0x72, 0xfc, 0x7c, 0xbb,
+	0x46, 0xda, 0x50, 0x1f, 0x1e, 0x3f, 0x7b, 0xb8, 0x6d, 0x91, 0x1e, 0xb4, 0x8f, 0x5f, 0x9c, 0x3d,
+	0x3c, 0x7e, 0x76, 0x74, 0xbc, 0x6d, 0x23, 0xf4, 0xe8, 0xec, 0xd9, 0x83, 0x27, 0x67, 0xcf, 0x7f,
+	0xb2, 0xed, 0x0c, 0xfe, 0xee, 0x40, 0xfb, 0x41, 0x34, 0x49, 0x32, 0x1c, 0xf4, 0x3f, 0x85, 0xae,
+	0xf1, 0x4c, 0x4d, 0x6e, 0xa1, 0x51, 0x2a, 0xcf, 0xe7, 0x7b, 0xa4, 0x82, 0xf4, 0x18, 0xa7, 0x35,
+	0xf2, 0x39, 0xbc, 0x75, 0xed, 0x99, 0x99, 0xdc, 0x29, 0x59, 0x2b, 0x6f, 0xd9, 0x7b, 0xee, 0x4a,
+	0x92, 0x3c, 0xeb, 0xc7, 0xd0, 0x33, 0x93, 0x96, 0xac, 0x4c, 0xe3, 0xbd, 0x5b, 0x55, 0xac, 0xfc,
+	0xf9, 0x21, 0x6c, 0x55, 0x82, 0x9f, 0xac, 0xcb, 0x88, 0xbd, 0xdd, 0x15, 0x04, 0x79, 0xca, 0xfb,
+	0x50, 0x17, 0x73, 0xc9, 0x52, 0x45, 0xd8, 0xeb, 0x6a, 0xa8, 0xd0, 0xf9, 0xda, 0x3a, 0x2a, 0x75,
+	0x5e, 0xb9, 0xd3, 0xee, 0xb9, 0x2b, 0x49, 0xf2, 0xac, 0x4f, 0xa1, 0x6b, 0x6c, 0xd4, 0xd2, 0xf2,
+	0x95, 0x6d, 0x7e, 0x8f, 0x54, 0x90, 0xe2, 0xcf, 0xc1, 0x1f, 0x2c, 0x68, 0x1d, 0x1e, 0x0d, 0x45,
+	0x3a, 0xbd, 0x03, 0xce, 0x09, 0xe3, 0xa4, 0x5c, 0xbd, 0xf6, 0x40, 0xde, 0x29, 0x36, 0xba, 0x1a,
+	0xf9, 0x0e, 0xd4, 0x87, 0x2c, 0x1e, 0x93, 0xea, 0x60, 0x53, 0x61, 0xfb, 0x50, 0x08, 0x53, 0xbc,
+	0x25, 0xad, 0x3d, 0x4e, 0xf2, 0x15, 0x2f, 0x19, 0xeb, 0xf8, 0x06, 0x7f, 0xb3, 0xa0, 0xf1, 0x60,
+	0x3c, 0x0d, 0x63, 0xdc, 0x24, 0x4f, 0x18, 0x57, 0x8b, 0x5d, 0x95, 0x5f, 0xa2, 0x69, 0x8d, 0x7c,
+	0x57, 0x1a, 0x43, 0x6f, 0x2d, 0x06, 0xdf, 0xb6, 0x39, 0x9d, 0xe3, 0xf8, 0x4e, 0x6b, 0x64, 0x20,
+	0x1f, 0x23, 0xca, 0xc9, 0xd6, 0xfc, 0x41, 0x46, 0xc9, 0xf2, 0xd4, 0x2b, 0x24, 0x87, 0x13, 0xc6,
+	0xf5, 0xcc, 0x67, 0xf0, 0x0b, 0x17, 0x2b, 0x3c, 0xad, 0x8d, 0x9a, 0x62, 0x8f, 0xbb, 0xff, 0x9f,
+	0x01, 0x00, 0x7e, 0x6d, 0xd4, 0xac, 0xda, 0x1c, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1219,8 +3980,10 @@ type AlgorandClient interface {
 	AppendBlock(ctx context.Context, in *AppendBlockArgs, opts ...grpc.CallOption) (*AppendBlockRet, error)
 	AppendTransaction(ctx context.Context, in *AppendTransactionArgs, opts ...grpc.CallOption) (*AppendTransactionRet, error)
 	ProposeBlock(ctx context.Context, in *ProposeBlockArgs, opts ...grpc.CallOption) (*ProposeBlockRet, error)
+	ProposePriority(ctx context.Context, in *ProposePriorityArgs, opts ...grpc.CallOption) (*ProposePriorityRet, error)
 	Vote(ctx context.Context, in *VoteArgs, opts ...grpc.CallOption) (*VoteRet, error)
 	RequestBlockChain(ctx context.Context, in *RequestBlockChainArgs, opts ...grpc.CallOption) (*RequestBlockChainRet, error)
+	GetProposal(ctx context.Context, in *GetProposalArgs, opts ...grpc.CallOption) (*GetProposalRet, error)
 }
 
 type algorandClient struct {
@@ -1258,6 +4021,15 @@ func (c *algorandClient) ProposeBlock(ctx context.Context, in *ProposeBlockArgs,
 	return out, nil
 }
 
+func (c *algorandClient) ProposePriority(ctx context.Context, in *ProposePriorityArgs, opts ...grpc.CallOption) (*ProposePriorityRet, error) {
+	out := new(ProposePriorityRet)
+	err := c.cc.Invoke(ctx, "/pb.Algorand/ProposePriority", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *algorandClient) Vote(ctx context.Context, in *VoteArgs, opts ...grpc.CallOption) (*VoteRet, error) {
 	out := new(VoteRet)
 	err := c.cc.Invoke(ctx, "/pb.Algorand/Vote", in, out, opts...)
@@ -1276,13 +4048,24 @@ func (c *algorandClient) RequestBlockChain(ctx context.Context, in *RequestBlock
 	return out, nil
 }
 
+func (c *algorandClient) GetProposal(ctx context.Context, in *GetProposalArgs, opts ...grpc.CallOption) (*GetProposalRet, error) {
+	out := new(GetProposalRet)
+	err := c.cc.Invoke(ctx, "/pb.Algorand/GetProposal", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AlgorandServer is the server API for Algorand service.
 type AlgorandServer interface {
 	AppendBlock(context.Context, *AppendBlockArgs) (*AppendBlockRet, error)
 	AppendTransaction(context.Context, *AppendTransactionArgs) (*AppendTransactionRet, error)
 	ProposeBlock(context.Context, *ProposeBlockArgs) (*ProposeBlockRet, error)
+	ProposePriority(context.Context, *ProposePriorityArgs) (*ProposePriorityRet, error)
 	Vote(context.Context, *VoteArgs) (*VoteRet, error)
 	RequestBlockChain(context.Context, *RequestBlockChainArgs) (*RequestBlockChainRet, error)
+	GetProposal(context.Context, *GetProposalArgs) (*GetProposalRet, error)
 }
 
 func RegisterAlgorandServer(s *grpc.Server, srv AlgorandServer) {
@@ -1343,6 +4126,24 @@ func _Algorand_ProposeBlock_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Algorand_ProposePriority_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProposePriorityArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlgorandServer).ProposePriority(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Algorand/ProposePriority",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlgorandServer).ProposePriority(ctx, req.(*ProposePriorityArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Algorand_Vote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(VoteArgs)
 	if err := dec(in); err != nil {
@@ -1379,6 +4180,24 @@ func _Algorand_RequestBlockChain_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Algorand_GetProposal_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProposalArgs)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AlgorandServer).GetProposal(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Algorand/GetProposal",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AlgorandServer).GetProposal(ctx, req.(*GetProposalArgs))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Algorand_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.Algorand",
 	HandlerType: (*AlgorandServer)(nil),
@@ -1395,6 +4214,10 @@ var _Algorand_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ProposeBlock",
 			Handler:    _Algorand_ProposeBlock_Handler,
 		},
+		{
+			MethodName: "ProposePriority",
+			Handler:    _Algorand_ProposePriority_Handler,
+		},
 		{
 			MethodName: "Vote",
 			Handler:    _Algorand_Vote_Handler,
@@ -1403,6 +4226,10 @@ var _Algorand_serviceDesc = grpc.ServiceDesc{
 			MethodName: "RequestBlockChain",
 			Handler:    _Algorand_RequestBlockChain_Handler,
 		},
+		{
+			MethodName: "GetProposal",
+			Handler:    _Algorand_GetProposal_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "bc.proto",
@@ -1414,6 +4241,10 @@ var _Algorand_serviceDesc = grpc.ServiceDesc{
 type BCStoreClient interface {
 	Get(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Result, error)
 	Send(ctx context.Context, in *Transaction, opts ...grpc.CallOption) (*Result, error)
+	// Equivocation evidence this node collected so far
+	GetEvidence(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Result, error)
+	// Which blocks are final and which only tentative
+	GetFinality(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Result, error)
 }
 
 type bCStoreClient struct {
@@ -1442,10 +4273,32 @@ func (c *bCStoreClient) Send(ctx context.Context, in *Transaction, opts ...grpc.
 	return out, nil
 }
 
+func (c *bCStoreClient) GetEvidence(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := c.cc.Invoke(ctx, "/pb.BCStore/GetEvidence", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bCStoreClient) GetFinality(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := c.cc.Invoke(ctx, "/pb.BCStore/GetFinality", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BCStoreServer is the server API for BCStore service.
 type BCStoreServer interface {
 	Get(context.Context, *Empty) (*Result, error)
 	Send(context.Context, *Transaction) (*Result, error)
+	// Equivocation evidence this node collected so far
+	GetEvidence(context.Context, *Empty) (*Result, error)
+	// Which blocks are final and which only tentative
+	GetFinality(context.Context, *Empty) (*Result, error)
 }
 
 func RegisterBCStoreServer(s *grpc.Server, srv BCStoreServer) {
@@ -1488,6 +4341,42 @@ func _BCStore_Send_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BCStore_GetEvidence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BCStoreServer).GetEvidence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.BCStore/GetEvidence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BCStoreServer).GetEvidence(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BCStore_GetFinality_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BCStoreServer).GetFinality(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.BCStore/GetFinality",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BCStoreServer).GetFinality(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _BCStore_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.BCStore",
 	HandlerType: (*BCStoreServer)(nil),
@@ -1500,6 +4389,181 @@ var _BCStore_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Send",
 			Handler:    _BCStore_Send_Handler,
 		},
+		{
+			MethodName: "GetEvidence",
+			Handler:    _BCStore_GetEvidence_Handler,
+		},
+		{
+			MethodName: "GetFinality",
+			Handler:    _BCStore_GetFinality_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "bc.proto",
+}
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AdminClient interface {
+	GetStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Status, error)
+	GetPeerInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PeerInfoList, error)
+	// Tallies and proposals of the current and the previous period
+	GetPeriodState(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PeriodStateInfo, error)
+	// Transactions waiting to go into a block
+	GetMempool(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Mempool, error)
+}
+
+type adminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminClient(cc *grpc.ClientConn) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) GetStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/pb.Admin/GetStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetPeerInfo(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PeerInfoList, error) {
+	out := new(PeerInfoList)
+	err := c.cc.Invoke(ctx, "/pb.Admin/GetPeerInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetPeriodState(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*PeriodStateInfo, error) {
+	out := new(PeriodStateInfo)
+	err := c.cc.Invoke(ctx, "/pb.Admin/GetPeriodState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetMempool(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Mempool, error) {
+	out := new(Mempool)
+	err := c.cc.Invoke(ctx, "/pb.Admin/GetMempool", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+type AdminServer interface {
+	GetStatus(context.Context, *Empty) (*Status, error)
+	GetPeerInfo(context.Context, *Empty) (*PeerInfoList, error)
+	// Tallies and proposals of the current and the previous period
+	GetPeriodState(context.Context, *Empty) (*PeriodStateInfo, error)
+	// Transactions waiting to go into a block
+	GetMempool(context.Context, *Empty) (*Mempool, error)
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Admin/GetStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetStatus(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetPeerInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetPeerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Admin/GetPeerInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetPeerInfo(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetPeriodState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetPeriodState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Admin/GetPeriodState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetPeriodState(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetMempool_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetMempool(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Admin/GetMempool",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetMempool(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _Admin_GetStatus_Handler,
+		},
+		{
+			MethodName: "GetPeerInfo",
+			Handler:    _Admin_GetPeerInfo_Handler,
+		},
+		{
+			MethodName: "GetPeriodState",
+			Handler:    _Admin_GetPeriodState_Handler,
+		},
+		{
+			MethodName: "GetMempool",
+			Handler:    _Admin_GetMempool_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "bc.proto",
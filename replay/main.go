@@ -0,0 +1,86 @@
+// Command replay feeds a consensus journal, recorded by the server with
+// -journal or by the simulator, through the agreement logic offline and
+// checks that every decision comes out the same.
+//
+//	replay -v node.journal
+//	replay -round 12 node.journal
+//
+// It exits 1 at the first decision that differs from the recorded one.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/journal"
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+)
+
+func usage() {
+	fmt.Printf("Usage %s [flags] <journal>\n", os.Args[0])
+	flag.PrintDefaults()
+}
+
+func printStep(s *journal.Step) {
+	fmt.Printf("#%-6v %v  round %v period %v step %v  %v\n", s.Index, s.Time.Format("15:04:05.000"), s.Round, s.Period, s.Step, journal.DescribeEvent(s.Record))
+	if s.Error != "" {
+		fmt.Printf("        rejected: %v\n", s.Error)
+	}
+	for _, a := range s.Actions {
+		fmt.Printf("        -> %v\n", a)
+	}
+}
+
+func main() {
+	flag.Usage = usage
+	var verbose bool
+	var round int64
+	flag.BoolVar(&verbose, "v", false, "Print every input and the decisions it led to")
+	flag.Int64Var(&round, "round", 0, "Only print the inputs handled in this round, implies -v")
+	var showLog bool
+	flag.BoolVar(&showLog, "log", false, "Also print the agreement log as the inputs are replayed")
+	flag.Parse()
+
+	if !showLog {
+		logging.SetOutput(ioutil.Discard)
+	}
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	r, err := journal.Open(flag.Args()[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	defer r.Close()
+
+	steps := 0
+	diverged, err := journal.Replay(r, func(s *journal.Step) {
+		steps++
+		if (verbose && round == 0) || (round != 0 && s.Round == round) {
+			printStep(s)
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+
+	if diverged != nil {
+		rec := diverged.Record
+		fmt.Printf("\nDIVERGED at record %v\n\nreplayed:\n", diverged.Index)
+		printStep(diverged)
+		fmt.Printf("\nrecorded: round %v period %v step %v\n", rec.Round, rec.Period, rec.Step)
+		if rec.Error != "" {
+			fmt.Printf("        rejected: %v\n", rec.Error)
+		}
+		fmt.Printf("        -> %v\n", strings.Join(rec.Actions, "\n        -> "))
+		os.Exit(1)
+	}
+	fmt.Printf("%v records replayed, every decision reproduced\n", steps)
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net"
+	"sort"
+	"strings"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+type GetStatusInput struct {
+	arg      *pb.Empty
+	response chan pb.Status
+}
+
+type GetPeerInfoInput struct {
+	arg      *pb.Empty
+	response chan pb.PeerInfoList
+}
+
+type GetPeriodStateInput struct {
+	arg      *pb.Empty
+	response chan pb.PeriodStateInfo
+}
+
+type GetMempoolInput struct {
+	arg      *pb.Empty
+	response chan pb.Mempool
+}
+
+// Admin answers operator requests from the serve loop, like Algorand does for peers.
+type Admin struct {
+	GetStatusChan      chan GetStatusInput
+	GetPeerInfoChan    chan GetPeerInfoInput
+	GetPeriodStateChan chan GetPeriodStateInput
+	GetMempoolChan     chan GetMempoolInput
+	// closed once the serve loop stops taking requests
+	done <-chan struct{}
+}
+
+func NewAdmin(done <-chan struct{}, queue int) *Admin {
+	return &Admin{
+		GetStatusChan:      make(chan GetStatusInput, queue),
+		GetPeerInfoChan:    make(chan GetPeerInfoInput, queue),
+		GetPeriodStateChan: make(chan GetPeriodStateInput, queue),
+		GetMempoolChan:     make(chan GetMempoolInput, queue),
+		done:               done,
+	}
+}
+
+func (a *Admin) GetStatus(ctx context.Context, arg *pb.Empty) (*pb.Status, error) {
+	c := make(chan pb.Status, 1)
+	select {
+	case a.GetStatusChan <- GetStatusInput{arg: arg, response: c}:
+	case <-a.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(adminQueue)
+	}
+	select {
+	case result := <-c:
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, adminQueue)
+	case <-a.done:
+		return nil, errShuttingDown
+	}
+}
+
+func (a *Admin) GetPeerInfo(ctx context.Context, arg *pb.Empty) (*pb.PeerInfoList, error) {
+	c := make(chan pb.PeerInfoList, 1)
+	select {
+	case a.GetPeerInfoChan <- GetPeerInfoInput{arg: arg, response: c}:
+	case <-a.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(adminQueue)
+	}
+	select {
+	case result := <-c:
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, adminQueue)
+	case <-a.done:
+		return nil, errShuttingDown
+	}
+}
+
+func (a *Admin) GetPeriodState(ctx context.Context, arg *pb.Empty) (*pb.PeriodStateInfo, error) {
+	c := make(chan pb.PeriodStateInfo, 1)
+	select {
+	case a.GetPeriodStateChan <- GetPeriodStateInput{arg: arg, response: c}:
+	case <-a.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(adminQueue)
+	}
+	select {
+	case result := <-c:
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, adminQueue)
+	case <-a.done:
+		return nil, errShuttingDown
+	}
+}
+
+func (a *Admin) GetMempool(ctx context.Context, arg *pb.Empty) (*pb.Mempool, error) {
+	c := make(chan pb.Mempool, 1)
+	select {
+	case a.GetMempoolChan <- GetMempoolInput{arg: arg, response: c}:
+	case <-a.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(adminQueue)
+	}
+	select {
+	case result := <-c:
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, adminQueue)
+	case <-a.done:
+		return nil, errShuttingDown
+	}
+}
+
+// periodStateInfo flattens a snapshot into tallies sorted by period, type and value.
+func periodStateInfo(snapshot agreement.Snapshot, requiredVotes int64) pb.PeriodStateInfo {
+	info := pb.PeriodStateInfo{
+		Round:         snapshot.Round,
+		Period:        snapshot.Period,
+		Step:          snapshot.Step,
+		StartingValue: snapshot.Current.StartingValue,
+		MyCertVote:    snapshot.Current.MyCertVote,
+		RequiredVotes: requiredVotes,
+	}
+
+	for priority, value := range snapshot.Current.Proposals {
+		info.Proposals = append(info.Proposals, &pb.ProposalInfo{Priority: priority, Value: value, HaveBlock: snapshot.Current.Blocks[value]})
+	}
+	sort.Slice(info.Proposals, func(i, j int) bool { return info.Proposals[i].Priority < info.Proposals[j].Priority })
+
+	for _, ps := range []agreement.PeriodSnapshot{snapshot.Last, snapshot.Current} {
+		if ps.Period == 0 {
+			continue
+		}
+		for _, tally := range []struct {
+			voteType string
+			votes    map[string]int64
+		}{{"soft", ps.SoftVotes}, {"cert", ps.CertVotes}, {"next", ps.NextVotes}} {
+			values := []string{}
+			for value := range tally.votes {
+				values = append(values, value)
+			}
+			sort.Strings(values)
+			for _, value := range values {
+				info.Tallies = append(info.Tallies, &pb.VoteTally{Period: ps.Period, Type: tally.voteType, Value: value, Votes: tally.votes[value]})
+			}
+		}
+	}
+	return info
+}
+
+// requireToken rejects calls that don't carry "authorization: Bearer <token>".
+func requireToken(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		presented := ""
+		if values := md.Get("authorization"); len(values) > 0 {
+			presented = strings.TrimPrefix(values[0], "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			logging.API.WithField("method", info.FullMethod).Warnf("Rejected admin call with a bad token")
+			return nil, status.Error(codes.Unauthenticated, "invalid admin token")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Launch the admin GRPC service on addr, only callers presenting token get through.
+// It runs until ctx is done.
+func RunAdminServer(ctx context.Context, admin *Admin, addr string, token string, opts []grpc.ServerOption) {
+	c, err := net.Listen("tcp", addr)
+	if err != nil {
+		logging.API.Fatalf("Could not create admin listening socket %v", err)
+	}
+	s := grpc.NewServer(append(opts, grpc.UnaryInterceptor(requireToken(token)))...)
+
+	pb.RegisterAdminServer(s, admin)
+	logging.API.Infof("Admin service listening on %v", addr)
+
+	go func() {
+		<-ctx.Done()
+		// requests are answered right away once the serve loop is gone
+		s.Stop()
+	}()
+
+	if err := s.Serve(c); err != nil {
+		logging.API.Fatalf("Failed to serve admin %v", err)
+	}
+}
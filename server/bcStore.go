@@ -1,10 +1,11 @@
 package main
 
 import (
-	"log"
+	"fmt"
 
 	context "golang.org/x/net/context"
 
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
 	"github.com/nyu-distributed-systems-fa18/algorand/pb"
 )
 
@@ -18,32 +19,110 @@ type InputChannelType struct {
 type BCStore struct {
 	C          chan InputChannelType
 	blockchain []*pb.Block
+	// the certificate of every block agreement decided, by round. nil for the
+	// genesis block and for blocks we adopted from a peer without one
+	certificates []*pb.Certificate
+	// proof of every equivocating voter we caught, see addEvidence
+	evidence []*pb.Evidence
+	// closed once the serve loop stops taking requests
+	done <-chan struct{}
 }
 
 func (bcs *BCStore) Get(ctx context.Context, in *pb.Empty) (*pb.Result, error) {
 	// Create a channel
-	c := make(chan pb.Result)
+	c := make(chan pb.Result, 1)
 	// Create a request
 	r := pb.Command{Operation: pb.Op_GET, Arg: &pb.Command_Empty{Empty: in}}
 	// Send request over the channel
-	bcs.C <- InputChannelType{command: r, response: c}
-	log.Printf("Waiting for get response")
-	result := <-c
-	// The bit below works because Go maps return the 0 value for non existent keys, which is empty in this case.
-	return &result, nil
+	select {
+	case bcs.C <- InputChannelType{command: r, response: c}:
+	case <-bcs.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(clientQueue)
+	}
+	logging.API.Debugf("Waiting for get response")
+	select {
+	case result := <-c:
+		// The bit below works because Go maps return the 0 value for non existent keys, which is empty in this case.
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, clientQueue)
+	case <-bcs.done:
+		return nil, errShuttingDown
+	}
 }
 
 func (bcs *BCStore) Send(ctx context.Context, in *pb.Transaction) (*pb.Result, error) {
 	// Create a channel
-	c := make(chan pb.Result)
+	c := make(chan pb.Result, 1)
 	// Create a request
 	r := pb.Command{Operation: pb.Op_SEND, Arg: &pb.Command_Tx{Tx: in}}
 	// Send request over the channel
-	bcs.C <- InputChannelType{command: r, response: c}
-	log.Printf("Waiting for send response")
-	result := <-c
+	select {
+	case bcs.C <- InputChannelType{command: r, response: c}:
+	case <-bcs.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(clientQueue)
+	}
+	logging.API.WithField("txid", txid(in)).Debugf("Waiting for send response")
+	select {
+	case result := <-c:
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, clientQueue)
+	case <-bcs.done:
+		return nil, errShuttingDown
+	}
+}
 
-	return &result, nil
+func (bcs *BCStore) GetEvidence(ctx context.Context, in *pb.Empty) (*pb.Result, error) {
+	// Create a channel
+	c := make(chan pb.Result, 1)
+	// Create a request
+	r := pb.Command{Operation: pb.Op_EVIDENCE, Arg: &pb.Command_Empty{Empty: in}}
+	// Send request over the channel
+	select {
+	case bcs.C <- InputChannelType{command: r, response: c}:
+	case <-bcs.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(clientQueue)
+	}
+	logging.API.Debugf("Waiting for evidence response")
+	select {
+	case result := <-c:
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, clientQueue)
+	case <-bcs.done:
+		return nil, errShuttingDown
+	}
+}
+
+func (bcs *BCStore) GetFinality(ctx context.Context, in *pb.Empty) (*pb.Result, error) {
+	// Create a channel
+	c := make(chan pb.Result, 1)
+	// Create a request
+	r := pb.Command{Operation: pb.Op_FINALITY, Arg: &pb.Command_Empty{Empty: in}}
+	// Send request over the channel
+	select {
+	case bcs.C <- InputChannelType{command: r, response: c}:
+	case <-bcs.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(clientQueue)
+	}
+	logging.API.Debugf("Waiting for finality response")
+	select {
+	case result := <-c:
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, clientQueue)
+	case <-bcs.done:
+		return nil, errShuttingDown
+	}
 }
 
 func (bcs *BCStore) GetResponse(arg *pb.Empty) pb.Result {
@@ -54,6 +133,119 @@ func (bcs *BCStore) SendResponse(arg *pb.Transaction) pb.Result {
 	return pb.Result{Result: &pb.Result_Bc{Bc: &pb.Blockchain{Blocks: bcs.blockchain}}}
 }
 
+// maxEvidence caps the evidence we keep, so equivocators can't fill our memory.
+const maxEvidence = 1000
+
+// addEvidence keeps e unless we hold evidence against its voter already, one
+// piece is proof enough, or are full. It reports whether it kept e.
+func (bcs *BCStore) addEvidence(e *pb.Evidence) bool {
+	if len(bcs.evidence) >= maxEvidence {
+		return false
+	}
+	for _, other := range bcs.evidence {
+		if other.UserId == e.UserId {
+			return false
+		}
+	}
+	bcs.evidence = append(bcs.evidence, e)
+	return true
+}
+
+func (bcs *BCStore) EvidenceResponse(arg *pb.Empty) pb.Result {
+	return pb.Result{Result: &pb.Result_Evidence{Evidence: &pb.EvidenceList{Evidence: bcs.evidence}}}
+}
+
+func (bcs *BCStore) FinalityResponse(arg *pb.Empty) pb.Result {
+	finality := &pb.Finality{FinalRound: bcs.finalRound()}
+	for _, block := range bcs.blockchain {
+		finality.Blocks = append(finality.Blocks, &pb.BlockStatus{Round: block.Id, Hash: block.Hash, Final: block.Id <= finality.FinalRound})
+	}
+	return pb.Result{Result: &pb.Result_Finality{Finality: finality}}
+}
+
+// finalRound is the last round we hold a certificate for. The blocks before
+// it are final too, the certified block pins their hashes.
+func (bcs *BCStore) finalRound() int64 {
+	for i := len(bcs.certificates) - 1; i > 0; i-- {
+		if bcs.certificates[i] != nil {
+			return int64(i)
+		}
+	}
+	return 0
+}
+
+// certified lists the certificates we hold, in round order.
+func (bcs *BCStore) certified() []*pb.Certificate {
+	certificates := []*pb.Certificate{}
+	for _, c := range bcs.certificates {
+		if c != nil {
+			certificates = append(certificates, c)
+		}
+	}
+	return certificates
+}
+
+// commit appends the block agreement decided along with its certificate. It
+// refuses a block for any round but the one after our last.
+func (bcs *BCStore) commit(block *pb.Block, certificate *pb.Certificate) error {
+	if block.Id != int64(len(bcs.blockchain)) {
+		return fmt.Errorf("block %v doesn't follow our chain, which ends at round %v", block.Id, len(bcs.blockchain)-1)
+	}
+	bcs.blockchain = append(bcs.blockchain, block)
+	bcs.certificates = append(bcs.certificates, certificate)
+	return nil
+}
+
+// adopt switches to a chain from a peer, certificates lined up with its
+// rounds, see checkCertificates. We keep ours for the blocks both share.
+func (bcs *BCStore) adopt(blockchain []*pb.Block, certificates []*pb.Certificate) {
+	for i := 1; i < len(bcs.blockchain) && i < len(blockchain); i++ {
+		if certificates[i] == nil && bcs.blockchain[i].Hash == blockchain[i].Hash {
+			certificates[i] = bcs.certificates[i]
+		}
+	}
+	bcs.blockchain = blockchain
+	bcs.certificates = certificates
+}
+
+// certify takes the certificates a peer has for blocks we adopted without
+// one, lined up with the rounds of the peer's chain. It returns how many of
+// our blocks turned final.
+func (bcs *BCStore) certify(blockchain []*pb.Block, certificates []*pb.Certificate) int64 {
+	before := bcs.finalRound()
+	for i := 1; i < len(bcs.blockchain) && i < len(blockchain); i++ {
+		if bcs.certificates[i] == nil && certificates[i] != nil && bcs.blockchain[i].Hash == blockchain[i].Hash {
+			bcs.certificates[i] = certificates[i]
+		}
+	}
+	return bcs.finalRound() - before
+}
+
+// overruledBy reports whether a peer's chain, with certificates lined up
+// with its rounds, shows that a block we only hold tentatively is not the
+// one that was decided.
+func (bcs *BCStore) overruledBy(blockchain []*pb.Block, certificates []*pb.Certificate) bool {
+	if len(blockchain) < len(bcs.blockchain) {
+		// switching would take us back a round, it has to grow first
+		return false
+	}
+	for i := len(bcs.blockchain) - 1; i > int(bcs.finalRound()); i-- {
+		if certificates[i] != nil && bcs.blockchain[i].Hash != blockchain[i].Hash {
+			return true
+		}
+	}
+	return false
+}
+
+// keepsFinal checks that switching to blockchain doesn't undo a final block.
+func (bcs *BCStore) keepsFinal(blockchain []*pb.Block) error {
+	final := bcs.finalRound()
+	if int64(len(blockchain)) <= final || blockchain[final].Hash != bcs.blockchain[final].Hash {
+		return fmt.Errorf("chain replaces our final block of round %v", final)
+	}
+	return nil
+}
+
 func (bcs *BCStore) HandleCommand(op InputChannelType) {
 	switch c := op.command; c.Operation {
 	case pb.Op_GET:
@@ -64,9 +256,17 @@ func (bcs *BCStore) HandleCommand(op InputChannelType) {
 		arg := c.GetTx()
 		result := bcs.SendResponse(arg)
 		op.response <- result
+	case pb.Op_EVIDENCE:
+		arg := c.GetEmpty()
+		result := bcs.EvidenceResponse(arg)
+		op.response <- result
+	case pb.Op_FINALITY:
+		arg := c.GetEmpty()
+		result := bcs.FinalityResponse(arg)
+		op.response <- result
 	default:
 		// Sending a blank response to just free things up, but we don't know how to make progress here.
 		op.response <- pb.Result{}
-		log.Fatalf("Unrecognized operation %v", c)
+		logging.API.Fatalf("Unrecognized operation %v", c)
 	}
 }
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+// Only the block for the round after our last one is committed.
+func TestCommitFollowsChain(t *testing.T) {
+	bcs := &BCStore{}
+	if err := bcs.commit(&pb.Block{Id: 0}, nil); err != nil {
+		t.Fatalf("genesis: %v", err)
+	}
+	for _, id := range []int64{0, 2} {
+		if err := bcs.commit(&pb.Block{Id: id}, nil); err == nil {
+			t.Errorf("committed block %v after round 0", id)
+		}
+	}
+	if err := bcs.commit(&pb.Block{Id: 1}, &pb.Certificate{Round: 1}); err != nil {
+		t.Errorf("block 1: %v", err)
+	}
+	if len(bcs.blockchain) != 2 || len(bcs.certificates) != 2 {
+		t.Errorf("chain has %v blocks and %v certificates, want 2", len(bcs.blockchain), len(bcs.certificates))
+	}
+}
@@ -0,0 +1,593 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/byzantine"
+	"github.com/nyu-distributed-systems-fa18/algorand/keystore"
+	"github.com/nyu-distributed-systems-fa18/algorand/ledger"
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+	"github.com/nyu-distributed-systems-fa18/algorand/participation"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+// Config is everything a node is started with. It is read from a TOML file,
+// then ALGORAND_* environment variables and finally command line flags
+// override single settings:
+//
+//	data_dir = "/var/lib/algorand"
+//	peers = ["peer1:3001", "peer2:3001", "peer3:3001"]
+//
+//	[listen]
+//	client = ":3000"
+//	algorand = ":3001"
+//
+//	[protocol]
+//	lambda = "2s"
+//	big_lambda = "10s"
+//
+//	[genesis.accounts]
+//	"<hex ed25519 public key>" = 1000
+//
+//	[genesis.vrf_keys]
+//	"3001" = "<hex ed25519 public key>"
+//
+//	[genesis.participants.3001]
+//	account = "<hex ed25519 public key>"
+//	key = "<hex ed25519 public key>"
+//	first_round = 1
+//	last_round = 100000
+type Config struct {
+	// where the node keeps its files, relative paths below are resolved against it
+	DataDir string `toml:"data_dir"`
+
+	Listen ListenConfig `toml:"listen"`
+	// the other nodes' Algorand addresses
+	Peers []string `toml:"peers"`
+
+	Keys     KeysConfig     `toml:"keys"`
+	TLS      TLSConfig      `toml:"tls"`
+	Log      LogConfig      `toml:"log"`
+	Metrics  MetricsConfig  `toml:"metrics"`
+	Admin    AdminConfig    `toml:"admin"`
+	Queues   QueueConfig    `toml:"queues"`
+	Network  NetworkConfig  `toml:"network"`
+	Protocol ProtocolConfig `toml:"protocol"`
+	Genesis  GenesisConfig  `toml:"genesis"`
+
+	// file to record every agreement input and decision to, empty for none
+	Journal string `toml:"journal"`
+	// how long shutting down may wait for requests and messages in flight
+	ShutdownTimeout time.Duration `toml:"shutdown_timeout"`
+	// testing only: comma separated misbehaviours, see byzantine.ParseModes
+	Byzantine string `toml:"byzantine"`
+
+	// keys.keystore once it is open
+	keystore *keystore.Keystore
+}
+
+type ListenConfig struct {
+	// address clients send transactions to
+	Client string `toml:"client"`
+	// address peers send proposals and votes to, its port is this node's user id
+	Algorand string `toml:"algorand"`
+}
+
+type KeysConfig struct {
+	// encrypted file with the keys the node uses, see package keystore: the
+	// VRF key we prove sortition with, its public key is ours in
+	// genesis.vrf_keys, and the participation keys we vote with, the one
+	// registered for the current rounds and any registered ahead of time.
+	// The account that spends our funds stays off the node.
+	Keystore string `toml:"keystore"`
+	// file holding the keystore passphrase
+	PassphraseFile string `toml:"passphrase_file"`
+}
+
+// TLSConfig turns on TLS for every listener and peer connection when CertFile
+// and KeyFile are set. With CAFile, peers have to present a certificate it signed.
+type TLSConfig struct {
+	CertFile string `toml:"cert_file"`
+	KeyFile  string `toml:"key_file"`
+	CAFile   string `toml:"ca_file"`
+}
+
+type LogConfig struct {
+	// text or json
+	Format string `toml:"format"`
+	// default level optionally followed by per subsystem ones, e.g. info,agreement=debug
+	Level string `toml:"level"`
+}
+
+type MetricsConfig struct {
+	// address to serve Prometheus metrics on, empty to disable
+	Listen string `toml:"listen"`
+}
+
+type AdminConfig struct {
+	// address to serve the admin service on, empty to disable
+	Listen string `toml:"listen"`
+	// token admin callers have to present
+	Token string `toml:"token"`
+}
+
+// QueueConfig bounds how many requests wait for the serve loop, beyond that
+// callers get ResourceExhausted. Consensus requests are served first.
+type QueueConfig struct {
+	// votes and proposals from peers, each
+	Consensus int `toml:"consensus"`
+	// client, admin, transaction gossip and sync requests, each
+	Client int `toml:"client"`
+	// votes and proposals for rounds up to future_rounds ahead are kept
+	// until we get there, at most future_messages of them
+	FutureRounds   int64 `toml:"future_rounds"`
+	FutureMessages int   `toml:"future_messages"`
+}
+
+// NetworkConfig shapes what we send to peers, see PeerManager.
+type NetworkConfig struct {
+	// requests waiting to go out to each peer
+	SendQueue int `toml:"send_queue"`
+	// requests to each peer that may be in flight at once
+	MaxInFlight int `toml:"max_in_flight"`
+	// deadline of every request
+	Timeout time.Duration `toml:"timeout"`
+	// retries wait BackoffMin, doubling up to BackoffMax
+	BackoffMin time.Duration `toml:"backoff_min"`
+	BackoffMax time.Duration `toml:"backoff_max"`
+	// a peer failing this many requests in a row gets none for OpenFor
+	FailuresToOpen int           `toml:"failures_to_open"`
+	OpenFor        time.Duration `toml:"open_for"`
+}
+
+// ProtocolConfig holds the agreement parameters, see agreement.Params. Every
+// node has to use the same ones.
+type ProtocolConfig struct {
+	Lambda        time.Duration `toml:"lambda"`
+	BigLambda     time.Duration `toml:"big_lambda"`
+	RoundInterval time.Duration `toml:"round_interval"`
+	K             int64         `toml:"k"`
+}
+
+// GenesisConfig is what the chain starts with. Every node has to use the same,
+// or their genesis blocks differ and none of their blocks link up.
+type GenesisConfig struct {
+	// balance of every account at round 0, by address
+	Accounts map[string]int64 `toml:"accounts"`
+	// every user's hex encoded VRF public key, by user id
+	VRFKeys map[string]string `toml:"vrf_keys"`
+	// the account that owns every user and its first participation key, by user id
+	Participants map[string]ParticipantConfig `toml:"participants"`
+}
+
+// ParticipantConfig makes Account the owner of a user, the only one that may
+// register the user's participation keys, and registers its first one.
+type ParticipantConfig struct {
+	Account    string `toml:"account"`
+	Key        string `toml:"key"`
+	FirstRound int64  `toml:"first_round"`
+	LastRound  int64  `toml:"last_round"`
+}
+
+func (p ProtocolConfig) Params() agreement.Params {
+	return agreement.Params{Lambda: p.Lambda, BigLambda: p.BigLambda, RoundInterval: p.RoundInterval, K: p.K}
+}
+
+// defaultConfig is what a node runs with when nothing overrides it.
+func defaultConfig() Config {
+	params := supportedProtocols[genesisProtocol].Agreement
+	return Config{
+		DataDir:         ".",
+		ShutdownTimeout: 10 * time.Second,
+		Listen:          ListenConfig{Client: ":3000", Algorand: ":3001"},
+		Log:             LogConfig{Format: "text", Level: "info"},
+		Queues: QueueConfig{
+			Consensus:      1024,
+			Client:         256,
+			FutureRounds:   agreement.DefaultFutureRounds,
+			FutureMessages: agreement.DefaultFutureMessages,
+		},
+		Network: NetworkConfig{
+			SendQueue:      1024,
+			MaxInFlight:    16,
+			Timeout:        5 * time.Second,
+			BackoffMin:     100 * time.Millisecond,
+			BackoffMax:     10 * time.Second,
+			FailuresToOpen: 5,
+			OpenFor:        30 * time.Second,
+		},
+		Protocol: ProtocolConfig{
+			Lambda:        params.Lambda,
+			BigLambda:     params.BigLambda,
+			RoundInterval: params.RoundInterval,
+			K:             params.K,
+		},
+	}
+}
+
+// loadConfig reads path over the defaults, if path isn't empty, and applies
+// the environment overrides.
+func loadConfig(path string) (Config, error) {
+	cfg := defaultConfig()
+	if path != "" {
+		md, err := toml.DecodeFile(path, &cfg)
+		if err != nil {
+			return cfg, err
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return cfg, fmt.Errorf("%v: unknown setting %v", path, undecoded[0])
+		}
+	}
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func setString(into *string) func(string) error {
+	return func(v string) error {
+		*into = v
+		return nil
+	}
+}
+
+func setDuration(into *time.Duration) func(string) error {
+	return func(v string) (err error) {
+		*into, err = time.ParseDuration(v)
+		return err
+	}
+}
+
+func setCount(into *int) func(string) error {
+	return func(v string) (err error) {
+		*into, err = strconv.Atoi(v)
+		return err
+	}
+}
+
+func setInt(into *int64) func(string) error {
+	return func(v string) (err error) {
+		*into, err = strconv.ParseInt(v, 10, 64)
+		return err
+	}
+}
+
+// envOverrides maps every environment variable a node reads to the setting it overrides.
+func (cfg *Config) envOverrides() map[string]func(string) error {
+	return map[string]func(string) error{
+		"ALGORAND_DATA_DIR":        setString(&cfg.DataDir),
+		"ALGORAND_LISTEN_CLIENT":   setString(&cfg.Listen.Client),
+		"ALGORAND_LISTEN_ALGORAND": setString(&cfg.Listen.Algorand),
+		"ALGORAND_PEERS": func(v string) error {
+			cfg.Peers = nil
+			for _, peer := range strings.Split(v, ",") {
+				if peer = strings.TrimSpace(peer); peer != "" {
+					cfg.Peers = append(cfg.Peers, peer)
+				}
+			}
+			return nil
+		},
+		"ALGORAND_KEYSTORE":                 setString(&cfg.Keys.Keystore),
+		"ALGORAND_KEYSTORE_PASSPHRASE_FILE": setString(&cfg.Keys.PassphraseFile),
+		"ALGORAND_TLS_CERT_FILE":            setString(&cfg.TLS.CertFile),
+		"ALGORAND_TLS_KEY_FILE":             setString(&cfg.TLS.KeyFile),
+		"ALGORAND_TLS_CA_FILE":              setString(&cfg.TLS.CAFile),
+		"ALGORAND_LOG_FORMAT":               setString(&cfg.Log.Format),
+		"ALGORAND_LOG_LEVEL":                setString(&cfg.Log.Level),
+		"ALGORAND_METRICS_LISTEN":           setString(&cfg.Metrics.Listen),
+		"ALGORAND_ADMIN_LISTEN":             setString(&cfg.Admin.Listen),
+		"ALGORAND_ADMIN_TOKEN":              setString(&cfg.Admin.Token),
+		"ALGORAND_CONSENSUS_QUEUE":          setCount(&cfg.Queues.Consensus),
+		"ALGORAND_CLIENT_QUEUE":             setCount(&cfg.Queues.Client),
+		"ALGORAND_FUTURE_ROUNDS":            setInt(&cfg.Queues.FutureRounds),
+		"ALGORAND_FUTURE_MESSAGES":          setCount(&cfg.Queues.FutureMessages),
+		"ALGORAND_SEND_QUEUE":               setCount(&cfg.Network.SendQueue),
+		"ALGORAND_MAX_IN_FLIGHT":            setCount(&cfg.Network.MaxInFlight),
+		"ALGORAND_PEER_TIMEOUT":             setDuration(&cfg.Network.Timeout),
+		"ALGORAND_BACKOFF_MIN":              setDuration(&cfg.Network.BackoffMin),
+		"ALGORAND_BACKOFF_MAX":              setDuration(&cfg.Network.BackoffMax),
+		"ALGORAND_FAILURES_TO_OPEN":         setCount(&cfg.Network.FailuresToOpen),
+		"ALGORAND_OPEN_FOR":                 setDuration(&cfg.Network.OpenFor),
+		"ALGORAND_LAMBDA":                   setDuration(&cfg.Protocol.Lambda),
+		"ALGORAND_BIG_LAMBDA":               setDuration(&cfg.Protocol.BigLambda),
+		"ALGORAND_ROUND_INTERVAL":           setDuration(&cfg.Protocol.RoundInterval),
+		"ALGORAND_K":                        setInt(&cfg.Protocol.K),
+		"ALGORAND_JOURNAL":                  setString(&cfg.Journal),
+		"ALGORAND_SHUTDOWN_TIMEOUT":         setDuration(&cfg.ShutdownTimeout),
+		"ALGORAND_BYZANTINE":                setString(&cfg.Byzantine),
+	}
+}
+
+func (cfg *Config) applyEnv(lookup func(string) (string, bool)) error {
+	for name, set := range cfg.envOverrides() {
+		if v, ok := lookup(name); ok {
+			if err := set(v); err != nil {
+				return fmt.Errorf("%v: %v", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Path resolves a configured file name against the data directory.
+func (cfg *Config) Path(name string) string {
+	if name == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(cfg.DataDir, name)
+}
+
+// UserId is this node's id, the port it serves Algorand requests on.
+func (cfg *Config) UserId() string {
+	_, port, _ := net.SplitHostPort(cfg.Listen.Algorand)
+	return port
+}
+
+// users are the user ids of this node and its peers.
+func (cfg *Config) users() []string {
+	users := []string{cfg.UserId()}
+	for _, peer := range cfg.Peers {
+		if _, port, err := net.SplitHostPort(peer); err == nil {
+			users = append(users, port)
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// Validate returns every problem with the configuration, nil if there are none.
+func (cfg *Config) Validate() []error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+	checkAddr := func(setting string, addr string) {
+		if _, port, err := net.SplitHostPort(addr); err != nil {
+			fail("%v: %v", setting, err)
+		} else if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			fail("%v: port %q isn't a number", setting, port)
+		}
+	}
+	checkFile := func(setting string, name string) {
+		if _, err := os.Stat(cfg.Path(name)); err != nil {
+			fail("%v: %v", setting, err)
+		}
+	}
+
+	if info, err := os.Stat(cfg.DataDir); err == nil && !info.IsDir() {
+		fail("data_dir: %v is not a directory", cfg.DataDir)
+	}
+
+	checkAddr("listen.client", cfg.Listen.Client)
+	checkAddr("listen.algorand", cfg.Listen.Algorand)
+
+	if len(cfg.Peers) < 3 {
+		fail("peers: need at least 3 peers, 4 nodes, to achieve Byzantine fault tolerance, have %v", len(cfg.Peers))
+	}
+	seen := map[string]bool{}
+	for _, peer := range cfg.Peers {
+		checkAddr("peers", peer)
+		if seen[peer] {
+			fail("peers: %v is listed twice", peer)
+		}
+		seen[peer] = true
+	}
+
+	if (cfg.TLS.CertFile == "") != (cfg.TLS.KeyFile == "") {
+		fail("tls: cert_file and key_file have to be set together")
+	}
+	if cfg.TLS.CAFile != "" && cfg.TLS.CertFile == "" {
+		fail("tls: ca_file needs cert_file and key_file")
+	}
+	for setting, name := range map[string]string{"tls.cert_file": cfg.TLS.CertFile, "tls.key_file": cfg.TLS.KeyFile, "tls.ca_file": cfg.TLS.CAFile} {
+		if name != "" {
+			checkFile(setting, name)
+		}
+	}
+
+	if err := logging.Check(cfg.Log.Format, cfg.Log.Level); err != nil {
+		fail("log: %v", err)
+	}
+
+	if cfg.Metrics.Listen != "" {
+		checkAddr("metrics.listen", cfg.Metrics.Listen)
+	}
+	if cfg.Admin.Listen != "" {
+		checkAddr("admin.listen", cfg.Admin.Listen)
+		if cfg.Admin.Token == "" {
+			fail("admin.token: the admin service needs a token")
+		}
+	}
+
+	if cfg.Queues.Consensus < 1 || cfg.Queues.Client < 1 {
+		fail("queues: consensus and client have to be at least 1")
+	}
+	if cfg.Queues.FutureRounds < 0 || cfg.Queues.FutureMessages < 0 {
+		fail("queues: future_rounds and future_messages can't be negative")
+	}
+	if n := cfg.Network; n.SendQueue < 1 || n.MaxInFlight < 1 || n.FailuresToOpen < 1 {
+		fail("network: send_queue, max_in_flight and failures_to_open have to be at least 1")
+	}
+	if n := cfg.Network; n.Timeout <= 0 || n.BackoffMin <= 0 || n.OpenFor <= 0 {
+		fail("network: timeout, backoff_min and open_for have to be positive")
+	}
+	if cfg.Network.BackoffMax < cfg.Network.BackoffMin {
+		fail("network: backoff_max %v is shorter than backoff_min %v", cfg.Network.BackoffMax, cfg.Network.BackoffMin)
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		fail("shutdown_timeout: has to be positive")
+	}
+	if err := cfg.Protocol.Params().Validate(); err != nil {
+		fail("protocol: %v", err)
+	}
+	if participants, err := cfg.participants(cfg.users()); err != nil {
+		fail("%v", err)
+	} else if _, err := ledger.Genesis(createGenesisBlock(cfg.Genesis.Accounts, participants)); err != nil {
+		fail("genesis: %v", err)
+	}
+	if _, err := cfg.participationKeys(); err != nil {
+		fail("%v", err)
+	}
+	if _, _, err := cfg.vrfKeys(cfg.users()); err != nil {
+		fail("%v", err)
+	}
+	if _, err := byzantine.ParseModes(cfg.Byzantine); err != nil {
+		fail("byzantine: %v", err)
+	}
+	return errs
+}
+
+// vrfKeys returns the key we prove sortition with and the public key of every
+// one of users. Without genesis.vrf_keys every user's key is derived from
+// its id, anyone can compute those and win sortition at will, so that is
+// only good for testing.
+func (cfg *Config) vrfKeys(users []string) (ed25519.PrivateKey, map[string]ed25519.PublicKey, error) {
+	keys := make(map[string]ed25519.PublicKey, len(users))
+	if len(cfg.Genesis.VRFKeys) == 0 {
+		for _, user := range users {
+			keys[user] = insecureVRFKey(user).Public().(ed25519.PublicKey)
+		}
+		return insecureVRFKey(cfg.UserId()), keys, nil
+	}
+
+	ks, err := cfg.openKeystore()
+	if err != nil {
+		return nil, nil, err
+	}
+	entries := ks.Entries(keystore.VRF)
+	if len(entries) != 1 {
+		return nil, nil, fmt.Errorf("keys.keystore: has to hold one vrf key, it holds %v", len(entries))
+	}
+	seed, err := ks.Secret(entries[0].Name)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, nil, fmt.Errorf("keys.keystore: vrf key %v is corrupt", entries[0].Name)
+	}
+	key := ed25519.NewKeyFromSeed(seed)
+	for _, user := range users {
+		pub, err := hex.DecodeString(cfg.Genesis.VRFKeys[user])
+		if err != nil || len(pub) != ed25519.PublicKeySize {
+			return nil, nil, fmt.Errorf("genesis.vrf_keys: no valid key for user %v", user)
+		}
+		keys[user] = pub
+	}
+	if !bytes.Equal(keys[cfg.UserId()], key.Public().(ed25519.PublicKey)) {
+		return nil, nil, fmt.Errorf("keys.keystore: vrf key %v isn't the key of user %v in genesis.vrf_keys", entries[0].Name, cfg.UserId())
+	}
+	return key, keys, nil
+}
+
+func insecureVRFKey(user string) ed25519.PrivateKey {
+	seed := sha256.Sum256([]byte("insecure vrf key " + user))
+	return ed25519.NewKeyFromSeed(seed[:])
+}
+
+// participants returns the registrations the genesis block starts with, one
+// for each of users, see ledger.Genesis. Without keys.keystore and
+// genesis.participants every user votes with a key derived from its id that
+// no account may replace. Anyone can compute those and vote in any user's
+// name, so that is only good for testing.
+func (cfg *Config) participants(users []string) ([]*pb.Transaction, error) {
+	var registrations []*pb.Transaction
+	if cfg.Keys.Keystore == "" && len(cfg.Genesis.Participants) == 0 {
+		for _, user := range users {
+			registrations = append(registrations, ledger.Register(user, insecureParticipationKey(user).Public().(ed25519.PublicKey), 1, math.MaxInt64))
+		}
+		return registrations, nil
+	}
+
+	for _, user := range users {
+		p, ok := cfg.Genesis.Participants[user]
+		if !ok {
+			return nil, fmt.Errorf("genesis.participants: no entry for user %v", user)
+		}
+		key, err := hex.DecodeString(p.Key)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("genesis.participants: no valid key for user %v", user)
+		}
+		tx := ledger.Register(user, key, p.FirstRound, p.LastRound)
+		tx.To = p.Account
+		registrations = append(registrations, tx)
+	}
+	return registrations, nil
+}
+
+// participationKeys loads the keys we vote with, see participants.
+func (cfg *Config) participationKeys() ([]*participation.Key, error) {
+	if cfg.Keys.Keystore == "" && len(cfg.Genesis.Participants) == 0 {
+		return []*participation.Key{participation.Insecure(insecureParticipationKey(cfg.UserId()), 1, math.MaxInt64)}, nil
+	}
+
+	ks, err := cfg.openKeystore()
+	if err != nil {
+		return nil, err
+	}
+	var keys []*participation.Key
+	for _, entry := range ks.Entries(keystore.Participation) {
+		secret, err := ks.Secret(entry.Name)
+		if err != nil {
+			return nil, fmt.Errorf("keys.keystore: participation key %v: %v", entry.Name, err)
+		}
+		key, err := participation.Unmarshal(secret, ks.Slot(entry.Name))
+		if err != nil {
+			return nil, fmt.Errorf("keys.keystore: participation key %v: %v", entry.Name, err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("keys.keystore: holds no participation key to vote with")
+	}
+	return keys, nil
+}
+
+// openKeystore opens keys.keystore, once.
+func (cfg *Config) openKeystore() (*keystore.Keystore, error) {
+	if cfg.keystore != nil {
+		return cfg.keystore, nil
+	}
+	if cfg.Keys.Keystore == "" {
+		return nil, fmt.Errorf("keys.keystore: we need a keystore with our keys")
+	}
+	passphrase, err := cfg.passphrase()
+	if err != nil {
+		return nil, err
+	}
+	ks, err := keystore.Open(cfg.Path(cfg.Keys.Keystore), passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("keys.keystore: %v", err)
+	}
+	cfg.keystore = ks
+	return ks, nil
+}
+
+// passphrase returns the keystore passphrase from keys.passphrase_file.
+func (cfg *Config) passphrase() ([]byte, error) {
+	if cfg.Keys.PassphraseFile == "" {
+		return nil, fmt.Errorf("keys.passphrase_file: we need the keystore passphrase")
+	}
+	b, err := ioutil.ReadFile(cfg.Path(cfg.Keys.PassphraseFile))
+	if err != nil {
+		return nil, fmt.Errorf("keys.passphrase_file: %v", err)
+	}
+	return bytes.TrimRight(b, "\r\n"), nil
+}
+
+func insecureParticipationKey(user string) ed25519.PrivateKey {
+	seed := sha256.Sum256([]byte("insecure participation key " + user))
+	return ed25519.NewKeyFromSeed(seed[:])
+}
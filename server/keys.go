@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/keystore"
+	"github.com/nyu-distributed-systems-fa18/algorand/participation"
+)
+
+const keyUsage = `Usage: %[1]s key generate -name name -kind account|participation|vrf [-first round -last round] [flags]
+       %[1]s key import -name name -kind account|vrf -seed-file file [flags]
+       %[1]s key export-public -name name [flags]
+       %[1]s key list [flags]
+
+Keys are kept in keys.keystore of the -config file, encrypted with the
+passphrase from keys.passphrase_file.
+generate and import create the keystore if there is none yet.
+`
+
+// Manage the keys in a keystore, see package keystore. Private keys never
+// leave it, the commands only print public keys.
+func runKeyCommand(args []string) {
+	if len(args) < 1 || (args[0] != "generate" && args[0] != "import" && args[0] != "export-public" && args[0] != "list") {
+		fmt.Fprintf(os.Stderr, keyUsage, os.Args[0])
+		os.Exit(2)
+	}
+	command := args[0]
+	flags := flag.NewFlagSet("key "+command, flag.ExitOnError)
+	configPath := flags.String("config", os.Getenv("ALGORAND_CONFIG"), "Config file naming the keystore, defaults to $ALGORAND_CONFIG")
+	keystorePath := flags.String("keystore", "", "Keystore file, replaces keys.keystore")
+	name := flags.String("name", "", "Name of the key in the keystore")
+	kind := flags.String("kind", keystore.Account, "Kind of key: account, participation or vrf")
+	first := flags.Int64("first", 1, "First round a participation key votes in")
+	last := flags.Int64("last", 100000, "Last round a participation key votes in")
+	seedFile := flags.String("seed-file", "", "File holding the hex encoded ed25519 seed to import, - for stdin")
+	flags.Parse(args[1:])
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		keyFail(command, err)
+	}
+	if *keystorePath != "" {
+		cfg.Keys.Keystore = *keystorePath
+	}
+	if cfg.Keys.Keystore == "" {
+		keyFail(command, fmt.Errorf("no keystore, set keys.keystore or -keystore"))
+	}
+	if command != "list" && *name == "" {
+		keyFail(command, fmt.Errorf("-name is required"))
+	}
+
+	switch command {
+	case "generate":
+		ks, err := cfg.openOrCreateKeystore()
+		if err != nil {
+			keyFail(command, err)
+		}
+		pub, err := generateKey(ks, *name, *kind, *first, *last)
+		if err != nil {
+			keyFail(command, err)
+		}
+		fmt.Printf("%x\n", []byte(pub))
+
+	case "import":
+		if *kind != keystore.Account && *kind != keystore.VRF {
+			keyFail(command, fmt.Errorf("only account and vrf keys can be imported"))
+		}
+		seed, err := readSeed(*seedFile)
+		if err != nil {
+			keyFail(command, err)
+		}
+		ks, err := cfg.openOrCreateKeystore()
+		if err != nil {
+			keyFail(command, err)
+		}
+		pub := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+		if err := ks.Add(keystore.Entry{Name: *name, Kind: *kind, Public: pub}, seed); err != nil {
+			keyFail(command, err)
+		}
+		fmt.Printf("%x\n", []byte(pub))
+
+	case "export-public":
+		entries, err := keystore.List(cfg.Path(cfg.Keys.Keystore))
+		if err != nil {
+			keyFail(command, err)
+		}
+		for _, e := range entries {
+			if e.Name == *name {
+				fmt.Printf("%x\n", []byte(e.Public))
+				return
+			}
+		}
+		keyFail(command, fmt.Errorf("%v: %v", *name, keystore.ErrNotFound))
+
+	case "list":
+		entries, err := keystore.List(cfg.Path(cfg.Keys.Keystore))
+		if err != nil {
+			keyFail(command, err)
+		}
+		for _, e := range entries {
+			if e.Kind == keystore.Participation {
+				fmt.Printf("%v\t%v\t%x\trounds %v to %v\n", e.Name, e.Kind, []byte(e.Public), e.First, e.Last)
+			} else {
+				fmt.Printf("%v\t%v\t%x\n", e.Name, e.Kind, []byte(e.Public))
+			}
+		}
+	}
+}
+
+func keyFail(command string, err error) {
+	fmt.Fprintf(os.Stderr, "key %v: %v\n", command, err)
+	os.Exit(1)
+}
+
+// generateKey makes a new key of kind in ks and returns its public key, the
+// root for participation keys.
+func generateKey(ks *keystore.Keystore, name, kind string, first, last int64) (ed25519.PublicKey, error) {
+	if kind == keystore.Participation {
+		key, err := participation.Generate(first, last)
+		if err != nil {
+			return nil, err
+		}
+		secret, err := key.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		if err := ks.Add(keystore.Entry{Name: name, Kind: kind, Public: key.Root, First: key.First, Last: key.Last}, secret); err != nil {
+			return nil, err
+		}
+		return key.Root, nil
+	}
+
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := ks.Add(keystore.Entry{Name: name, Kind: kind, Public: pub}, key.Seed()); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+// readSeed reads a hex encoded ed25519 seed from path, stdin for "-".
+func readSeed(path string) ([]byte, error) {
+	var b []byte
+	var err error
+	switch path {
+	case "":
+		return nil, fmt.Errorf("-seed-file is required")
+	case "-":
+		b, err = ioutil.ReadAll(os.Stdin)
+	default:
+		b, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(string(bytes.TrimSpace(b)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%v: has to hold %v hex encoded bytes", path, ed25519.SeedSize)
+	}
+	return seed, nil
+}
+
+// openOrCreateKeystore opens keys.keystore, or creates it if there is no
+// such file yet.
+func (cfg *Config) openOrCreateKeystore() (*keystore.Keystore, error) {
+	path := cfg.Path(cfg.Keys.Keystore)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return cfg.openKeystore()
+	}
+	passphrase, err := cfg.passphrase()
+	if err != nil {
+		return nil, err
+	}
+	ks, err := keystore.Create(path, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("keys.keystore: %v", err)
+	}
+	cfg.keystore = ks
+	return ks, nil
+}
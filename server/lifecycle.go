@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+)
+
+// Handlers return this once the serve loop stopped taking requests.
+var errShuttingDown = status.Error(codes.Unavailable, "server is shutting down")
+
+// errShutdownTimeout is returned by serve when votes were still in flight at the deadline.
+var errShutdownTimeout = errors.New("gave up waiting for messages in flight")
+
+// onSignal cancels the returned context on the first SIGINT or SIGTERM. A
+// second one exits right away, for when shutting down hangs.
+func onSignal(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		logging.API.Infof("Received %v, shutting down", sig)
+		cancel()
+		sig = <-signals
+		logging.API.Errorf("Received %v again, exiting now", sig)
+		os.Exit(1)
+	}()
+	return ctx, cancel
+}
+
+// stopServer lets the requests s is handling finish, for at most grace, and
+// closes its listeners and connections.
+func stopServer(s *grpc.Server, grace time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(grace):
+		logging.API.Warnf("Requests still running after %v, cancelling them", grace)
+		s.Stop()
+	}
+}
@@ -7,73 +7,231 @@ import (
 	// rand "math/rand"
 	"net"
 	"os"
-	"time"
 
+	context "golang.org/x/net/context"
 	"google.golang.org/grpc"
 
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/byzantine"
+	"github.com/nyu-distributed-systems-fa18/algorand/journal"
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+	"github.com/nyu-distributed-systems-fa18/algorand/metrics"
 	"github.com/nyu-distributed-systems-fa18/algorand/pb"
 )
 
-func createGenesisBlock() *pb.Block {
-	genesisBlock := new(pb.Block)
-	genesisBlock.Id = 0
-	genesisBlock.Timestamp = time.Now().String()
-	genesisBlock.PrevHash = ""
-	genesisBlock.Hash = calculateHash(genesisBlock)
-	genesisBlock.Tx = nil
-	return genesisBlock
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n       %s config validate [-config file]\n       %s key generate|import|export-public|list [flags]\n\n", os.Args[0], os.Args[0], os.Args[0])
+	fmt.Fprintf(flag.CommandLine.Output(), "Settings come from the -config file, then ALGORAND_* environment variables, then these flags.\n")
+	flag.PrintDefaults()
+}
+
+// Check the configuration a node would start with and report every problem.
+func runConfigCommand(args []string) {
+	if len(args) < 1 || args[0] != "validate" {
+		fmt.Fprintf(os.Stderr, "Usage: %s config validate [-config file]\n", os.Args[0])
+		os.Exit(2)
+	}
+	validate := flag.NewFlagSet("config validate", flag.ExitOnError)
+	configPath := validate.String("config", os.Getenv("ALGORAND_CONFIG"), "Config file to check, defaults to $ALGORAND_CONFIG")
+	validate.Parse(args[1:])
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	if errs := cfg.Validate(); len(errs) > 0 {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+		os.Exit(1)
+	}
+	name := *configPath
+	if name == "" {
+		name = "The configuration"
+	}
+	fmt.Printf("%v is valid, node %v with %v peers\n", name, cfg.UserId(), len(cfg.Peers))
 }
 
 func main() {
-	// Argument parsing
-	var seed int64
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		runConfigCommand(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "key" {
+		runKeyCommand(os.Args[2:])
+		return
+	}
+
+	// Argument parsing, every flag overrides the setting from the config file
+	flag.Usage = usage
+	var configPath string
+	flag.StringVar(&configPath, "config", os.Getenv("ALGORAND_CONFIG"),
+		"TOML config file, defaults to $ALGORAND_CONFIG")
+	var dataDir string
+	flag.StringVar(&dataDir, "data-dir", "",
+		"Directory the node keeps its files in")
 	var peers arrayPeers
 	var clientPort int
 	var algorandPort int
-	flag.Int64Var(&seed, "seed", -1,
-		"Seed for random number generator, values less than 0 result in use of time")
 	flag.IntVar(&clientPort, "port", 3000,
 		"Port on which server should listen to client requests")
 	flag.IntVar(&algorandPort, "algorand", 3001,
 		"Port on which server should listen to Algorand requests")
-	flag.Var(&peers, "peer", "A peer for this process")
+	flag.Var(&peers, "peer", "A peer for this process, replaces the configured peers")
+	var metricsAddr string
+	flag.StringVar(&metricsAddr, "metrics", "",
+		"Address to serve Prometheus metrics on, e.g. :9000, empty to disable")
+	var byzantineFlag string
+	flag.StringVar(&byzantineFlag, "byzantine", "",
+		"Testing only: comma separated misbehaviours for this node (equivocate, double-vote, forge, withhold, invalid-block, replay or all)")
+	var adminAddr, adminToken string
+	flag.StringVar(&adminAddr, "admin", "",
+		"Address to serve the admin service on, e.g. 127.0.0.1:3100, empty to disable")
+	flag.StringVar(&adminToken, "admin-token", "",
+		"Token admin callers have to present, prefer $ALGORAND_ADMIN_TOKEN")
+	var journalPath string
+	flag.StringVar(&journalPath, "journal", "",
+		"File to record every agreement input and decision to, for the replay tool")
+	var logFormat, logLevel string
+	flag.StringVar(&logFormat, "log-format", "text",
+		"Log format, text or json")
+	flag.StringVar(&logLevel, "log-level", "info",
+		"Log level, optionally per subsystem (agreement, network, ledger, api), e.g. info,agreement=debug")
 	flag.Parse()
 
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "data-dir":
+			cfg.DataDir = dataDir
+		case "port":
+			cfg.Listen.Client = fmt.Sprintf(":%d", clientPort)
+		case "algorand":
+			cfg.Listen.Algorand = fmt.Sprintf(":%d", algorandPort)
+		case "peer":
+			cfg.Peers = peers
+		case "metrics":
+			cfg.Metrics.Listen = metricsAddr
+		case "byzantine":
+			cfg.Byzantine = byzantineFlag
+		case "admin":
+			cfg.Admin.Listen = adminAddr
+		case "admin-token":
+			cfg.Admin.Token = adminToken
+		case "journal":
+			cfg.Journal = journalPath
+		case "log-format":
+			cfg.Log.Format = logFormat
+		case "log-level":
+			cfg.Log.Level = logLevel
+		}
+	})
+	if errs := cfg.Validate(); len(errs) > 0 {
+		for _, err := range errs {
+			log.Printf("%v", err)
+		}
+		log.Fatalf("Invalid configuration")
+	}
+
+	if err := logging.Configure(cfg.Log.Format, cfg.Log.Level); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if err := os.MkdirAll(cfg.DataDir, 0700); err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	byzantineModes, _ := byzantine.ParseModes(cfg.Byzantine)
+
 	// Get hostname
 	name, err := os.Hostname()
 	if err != nil {
 		// Without a host name we can't really get an ID, so die.
-		log.Fatalf("Could not get hostname")
+		logging.API.Fatalf("Could not get hostname")
 	}
 
-	id := fmt.Sprintf("%s:%d", name, algorandPort)
-	log.Printf("Starting peer with ID %s", id)
+	id := fmt.Sprintf("%s:%s", name, cfg.UserId())
+	logging.API.Infof("Starting peer with ID %s", id)
 
-	// Convert port to a string form
-	portString := fmt.Sprintf(":%d", clientPort)
-	// Create socket that listens on the supplied port
-	c, err := net.Listen("tcp", portString)
+	// Create socket that listens on the supplied address
+	c, err := net.Listen("tcp", cfg.Listen.Client)
 	if err != nil {
 		// Note the use of Fatalf which will exit the program after reporting the error.
-		log.Fatalf("Could not create listening socket %v", err)
+		logging.API.Fatalf("Could not create listening socket %v", err)
 	}
+	// SIGTERM stops the client service first, then agreement
+	signalled, _ := onSignal(context.Background())
+	ctx, stop := context.WithCancel(context.Background())
+
 	// Create a new GRPC server
-	s := grpc.NewServer()
+	opts, err := cfg.serverOptions(false)
+	if err != nil {
+		logging.API.Fatalf("Could not load TLS certificate %v", err)
+	}
+	s := grpc.NewServer(opts...)
 
 	// Create service to handle BlockChain
-	bcs := BCStore{C: make(chan InputChannelType), blockchain: []*pb.Block{}}
+	bcs := BCStore{C: make(chan InputChannelType, cfg.Queues.Client), blockchain: []*pb.Block{}, done: ctx.Done()}
 
 	// Init with GenesisBlock
-	bcs.blockchain = append(bcs.blockchain, createGenesisBlock())
+	participants, _ := cfg.participants(cfg.users())
+	bcs.commit(createGenesisBlock(cfg.Genesis.Accounts, participants), nil)
+
+	if cfg.Metrics.Listen != "" {
+		go metrics.Serve(cfg.Metrics.Listen)
+	}
+
+	admin := NewAdmin(ctx.Done(), cfg.Queues.Client)
+	if cfg.Admin.Listen != "" {
+		go RunAdminServer(ctx, admin, cfg.Admin.Listen, cfg.Admin.Token, opts)
+	}
+
+	var consensusJournal agreement.Journal
+	var journalWriter *journal.Writer
+	if cfg.Journal != "" {
+		journalWriter, err = journal.Create(cfg.Path(cfg.Journal))
+		if err != nil {
+			logging.Agreement.Fatalf("Could not create journal %v", err)
+		}
+		logging.Agreement.Infof("Recording agreement to %v", cfg.Path(cfg.Journal))
+		consensusJournal = journalWriter
+	}
 
 	// Spin up algorand server
-	go serve(&bcs, &peers, id, algorandPort)
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(ctx, &bcs, admin, &cfg, id, byzantineModes, consensusJournal)
+	}()
 
 	pb.RegisterBCStoreServer(s, &bcs)
-	log.Printf("Going to listen on port %v", clientPort)
-	// Start serving, this will block this function and only return when done.
-	if err := s.Serve(c); err != nil {
-		log.Fatalf("Failed to serve %v", err)
+	logging.API.Infof("Going to listen on %v", cfg.Listen.Client)
+	go func() {
+		if err := s.Serve(c); err != nil {
+			logging.API.Fatalf("Failed to serve %v", err)
+		}
+	}()
+
+	<-signalled.Done()
+	// answer the client requests we already took, refuse new ones
+	stopServer(s, cfg.ShutdownTimeout)
+	logging.API.Infof("Done listening")
+
+	stop()
+	err = <-served
+	if err != nil {
+		logging.Agreement.Errorf("Shutting down: %v", err)
+	}
+	if journalWriter != nil {
+		if jerr := journalWriter.Close(); jerr != nil {
+			logging.Agreement.Errorf("Could not flush journal %v", jerr)
+			err = jerr
+		}
+	}
+	if err != nil {
+		os.Exit(1)
 	}
-	log.Printf("Done listening")
+	logging.API.Infof("Stopped")
 }
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"time"
+
+	context "golang.org/x/net/context"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+type ProposeBlockResponse struct {
+	ret  *pb.ProposeBlockRet
+	err  error
+	peer string
+	// what we sent and how many times, for retries
+	proposal *pb.ProposeBlockArgs
+	attempts int
+}
+
+type ProposePriorityResponse struct {
+	ret  *pb.ProposePriorityRet
+	err  error
+	peer string
+}
+
+type VoteResponse struct {
+	ret  *pb.VoteRet
+	err  error
+	peer string
+}
+
+// peerNetwork implements agreement.Network on top of the peer manager.
+// Responses are reported on the channels so the serve loop can react to them.
+type peerNetwork struct {
+	userId string
+	peers  *PeerManager
+
+	proposeBlockResponseChan    chan ProposeBlockResponse
+	proposePriorityResponseChan chan ProposePriorityResponse
+	voteResponseChan            chan VoteResponse
+}
+
+func (n *peerNetwork) Peers() []string {
+	return n.peers.Peers()
+}
+
+func (n *peerNetwork) BroadcastProposal(proposal *pb.ProposeBlockArgs) {
+	for _, p := range n.peers.Peers() {
+		n.SendProposal(p, proposal)
+	}
+}
+
+func (n *peerNetwork) SendProposal(p string, proposal *pb.ProposeBlockArgs) {
+	n.sendProposal(p, proposal, 1)
+}
+
+// RetryProposal sends proposal to p again after the backoff for the attempts it already took.
+func (n *peerNetwork) RetryProposal(p string, proposal *pb.ProposeBlockArgs, attempts int) {
+	n.sendProposal(p, proposal, attempts+1)
+}
+
+func (n *peerNetwork) sendProposal(p string, proposal *pb.ProposeBlockArgs, attempt int) {
+	delay := time.Duration(0)
+	if attempt > 1 {
+		delay = n.peers.Backoff(attempt - 1)
+	}
+	n.peers.SendAfter(delay, p, func(ctx context.Context, c pb.AlgorandClient) (interface{}, error) {
+		logging.Network.WithFields(logging.Fields{"peer": p, "round": proposal.Round, "attempt": attempt}).Debugf("Sent proposal")
+		return c.ProposeBlock(ctx, proposal)
+	}, func(ret interface{}, err error) {
+		r, _ := ret.(*pb.ProposeBlockRet)
+		n.proposeBlockResponseChan <- ProposeBlockResponse{ret: r, err: err, peer: p, proposal: proposal, attempts: attempt}
+	})
+}
+
+// BroadcastPriority isn't retried, the proposal that follows carries the priority too.
+func (n *peerNetwork) BroadcastPriority(priority *pb.ProposePriorityArgs) {
+	for _, p := range n.peers.Peers() {
+		p := p
+		n.peers.Send(p, func(ctx context.Context, c pb.AlgorandClient) (interface{}, error) {
+			logging.Network.WithFields(logging.Fields{"peer": p, "round": priority.Priority.Round}).Debugf("Sent priority")
+			return c.ProposePriority(ctx, priority)
+		}, func(ret interface{}, err error) {
+			r, _ := ret.(*pb.ProposePriorityRet)
+			n.proposePriorityResponseChan <- ProposePriorityResponse{ret: r, err: err, peer: p}
+		})
+	}
+}
+
+func (n *peerNetwork) BroadcastVote(vote *pb.VoteArgs) {
+	for _, p := range n.peers.Peers() {
+		n.SendVote(p, vote)
+	}
+}
+
+func (n *peerNetwork) SendVote(p string, vote *pb.VoteArgs) {
+	n.peers.Send(p, func(ctx context.Context, c pb.AlgorandClient) (interface{}, error) {
+		logging.Network.WithFields(logging.Fields{"peer": p, "round": vote.Round}).Debugf("Sent %v vote", vote.Message.Message[1])
+		return c.Vote(ctx, vote)
+	}, func(ret interface{}, err error) {
+		r, _ := ret.(*pb.VoteRet)
+		n.voteResponseChan <- VoteResponse{ret: r, err: err, peer: p}
+	})
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+	"github.com/nyu-distributed-systems-fa18/algorand/metrics"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+var (
+	errSendQueueFull = errors.New("send queue is full")
+	errPeerDown      = errors.New("peer is down, not sending until it recovers")
+	errManagerClosed = errors.New("not connected to peers anymore")
+)
+
+// An outgoing request to one peer. call makes it and done reports the
+// outcome, ret is nil when the request failed or was never made.
+type outgoing struct {
+	call func(ctx context.Context, c pb.AlgorandClient) (interface{}, error)
+	done func(ret interface{}, err error)
+}
+
+// Circuit breaker states. A peer that keeps failing is open: we stop sending to
+// it for a while, then let a single request through to see if it's back.
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half-open"
+)
+
+// PeerState is what we know about a peer from our requests to it.
+type PeerState struct {
+	Conn      *grpc.ClientConn
+	Connected bool
+	LastSeen  time.Time
+	LastError string
+	Circuit   string
+	Queued    int
+	InFlight  int
+}
+
+type peer struct {
+	addr   string
+	conn   *grpc.ClientConn
+	client pb.AlgorandClient
+	// requests are sent in the order they were queued
+	queue chan outgoing
+	// one token per request in flight
+	slots chan struct{}
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+	connected bool
+	lastSeen  time.Time
+	lastError string
+}
+
+// PeerManager owns the connections to our peers and everything we send them.
+// Each peer has its own bounded queue and a cap on requests in flight, every
+// request has a deadline, and a peer that keeps failing is given a rest
+// instead of more requests.
+type PeerManager struct {
+	cfg   NetworkConfig
+	peers map[string]*peer
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// requests queued or in flight, for draining on shutdown
+	pending sync.WaitGroup
+	mu      sync.Mutex
+	closed  bool
+}
+
+func NewPeerManager(addrs []string, cfg NetworkConfig, transport grpc.DialOption) (*PeerManager, error) {
+	m := &PeerManager{cfg: cfg, peers: make(map[string]*peer)}
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+
+	backoffConfig := grpc.DefaultBackoffConfig
+	// Reconnect quickly, the circuit breaker keeps us from hammering a dead peer.
+	backoffConfig.MaxDelay = 500 * time.Millisecond
+	for _, addr := range addrs {
+		conn, err := grpc.Dial(addr, transport, grpc.WithBackoffConfig(backoffConfig))
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		p := &peer{
+			addr:   addr,
+			conn:   conn,
+			client: pb.NewAlgorandClient(conn),
+			queue:  make(chan outgoing, cfg.SendQueue),
+			slots:  make(chan struct{}, cfg.MaxInFlight),
+		}
+		m.peers[addr] = p
+		go m.run(p)
+	}
+	return m, nil
+}
+
+// Peers returns the peer addresses, sorted.
+func (m *PeerManager) Peers() []string {
+	addrs := []string{}
+	for addr := range m.peers {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// Send queues a request to addr. It never blocks: when the queue is full or
+// the peer is down, done is called with an error from another goroutine.
+func (m *PeerManager) Send(addr string, call func(ctx context.Context, c pb.AlgorandClient) (interface{}, error), done func(ret interface{}, err error)) {
+	m.SendAfter(0, addr, call, done)
+}
+
+// SendAfter is Send once delay has passed, for retries.
+func (m *PeerManager) SendAfter(delay time.Duration, addr string, call func(ctx context.Context, c pb.AlgorandClient) (interface{}, error), done func(ret interface{}, err error)) {
+	p, ok := m.peers[addr]
+	if !ok {
+		logging.Network.WithField("peer", addr).Warnf("Not sending to unknown peer")
+		return
+	}
+	out := outgoing{call: call, done: done}
+
+	m.mu.Lock()
+	m.pending.Add(1)
+	closed := m.closed
+	m.mu.Unlock()
+	if closed {
+		go m.finish(out, nil, errManagerClosed)
+		return
+	}
+
+	enqueue := func() {
+		// under the lock, so Close either sees the request queued or keeps
+		// it from being queued
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.closed {
+			go m.finish(out, nil, errManagerClosed)
+			return
+		}
+		select {
+		case p.queue <- out:
+		default:
+			go m.finish(out, nil, errSendQueueFull)
+		}
+	}
+	if delay > 0 {
+		time.AfterFunc(delay, enqueue)
+	} else {
+		enqueue()
+	}
+}
+
+// Backoff is how long to wait before retrying a request that failed attempts
+// times: it doubles every time, up to the configured maximum, with jitter so
+// peers don't retry in lockstep.
+func (m *PeerManager) Backoff(attempts int) time.Duration {
+	d := m.cfg.BackoffMin
+	for i := 1; i < attempts && d < m.cfg.BackoffMax; i++ {
+		d *= 2
+	}
+	if d > m.cfg.BackoffMax {
+		d = m.cfg.BackoffMax
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func (m *PeerManager) finish(out outgoing, ret interface{}, err error) {
+	out.done(ret, err)
+	m.pending.Done()
+}
+
+func (m *PeerManager) run(p *peer) {
+	for {
+		var out outgoing
+		select {
+		case out = <-p.queue:
+		case <-m.ctx.Done():
+			m.drain(p)
+			return
+		}
+		if m.ctx.Err() != nil {
+			go m.finish(out, nil, errManagerClosed)
+			m.drain(p)
+			return
+		}
+
+		if err := p.allow(m.cfg); err != nil {
+			go m.finish(out, nil, err)
+			continue
+		}
+
+		select {
+		case p.slots <- struct{}{}:
+			// closing cancels the requests in flight, which frees their slots
+			if m.ctx.Err() != nil {
+				<-p.slots
+				go m.finish(out, nil, errManagerClosed)
+				m.drain(p)
+				return
+			}
+		case <-m.ctx.Done():
+			go m.finish(out, nil, errManagerClosed)
+			m.drain(p)
+			return
+		}
+		go func(out outgoing) {
+			ctx, cancel := context.WithTimeout(m.ctx, m.cfg.Timeout)
+			ret, err := out.call(ctx, p.client)
+			cancel()
+			<-p.slots
+			p.record(m.cfg, err)
+			m.finish(out, ret, err)
+		}(out)
+	}
+}
+
+// drain gives up on the requests still queued for p once we are closed.
+func (m *PeerManager) drain(p *peer) {
+	for {
+		select {
+		case out := <-p.queue:
+			go m.finish(out, nil, errManagerClosed)
+		default:
+			return
+		}
+	}
+}
+
+// allow decides whether a request may go out to p.
+func (p *peer) allow(cfg NetworkConfig) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failures < cfg.FailuresToOpen {
+		return nil
+	}
+	if time.Now().Before(p.openUntil) || p.probing {
+		return errPeerDown
+	}
+	// half-open, this request finds out whether the peer is back
+	p.probing = true
+	return nil
+}
+
+// unhealthy reports whether err says something about the peer rather than the request.
+func unhealthy(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+func (p *peer) record(cfg NetworkConfig, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	log := logging.Network.WithField("peer", p.addr)
+
+	if err != nil {
+		p.connected = false
+		p.lastError = err.Error()
+		metrics.PeerUp.WithLabelValues(p.addr).Set(0)
+	} else {
+		p.connected = true
+		p.lastSeen = time.Now()
+		metrics.PeerUp.WithLabelValues(p.addr).Set(1)
+	}
+
+	if !unhealthy(err) {
+		if p.failures >= cfg.FailuresToOpen {
+			log.Infof("Peer is back")
+		}
+		p.failures = 0
+		p.probing = false
+		return
+	}
+
+	p.failures++
+	p.probing = false
+	if p.failures >= cfg.FailuresToOpen {
+		if p.failures == cfg.FailuresToOpen {
+			log.Warnf("Peer failed %v times in a row, pausing requests to it for %v: %v", p.failures, cfg.OpenFor, err)
+		}
+		p.openUntil = time.Now().Add(cfg.OpenFor)
+	}
+}
+
+func (p *peer) state(cfg NetworkConfig) PeerState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	circuit := circuitClosed
+	if p.failures >= cfg.FailuresToOpen {
+		circuit = circuitOpen
+		if p.probing || !time.Now().Before(p.openUntil) {
+			circuit = circuitHalfOpen
+		}
+	}
+	return PeerState{
+		Conn:      p.conn,
+		Connected: p.connected,
+		LastSeen:  p.lastSeen,
+		LastError: p.lastError,
+		Circuit:   circuit,
+		Queued:    len(p.queue),
+		InFlight:  len(p.slots),
+	}
+}
+
+// State returns what we know about addr.
+func (m *PeerManager) State(addr string) PeerState {
+	return m.peers[addr].state(m.cfg)
+}
+
+// Drained is closed once every request queued so far has been answered or given up on.
+func (m *PeerManager) Drained() <-chan struct{} {
+	c := make(chan struct{})
+	go func() {
+		m.pending.Wait()
+		close(c)
+	}()
+	return c
+}
+
+// Close cancels the requests in flight, fails the queued ones and those sent
+// later with errManagerClosed, and closes every connection.
+func (m *PeerManager) Close() {
+	m.mu.Lock()
+	m.closed = true
+	m.mu.Unlock()
+
+	m.cancel()
+	for addr, p := range m.peers {
+		p.conn.Close()
+		logging.Network.WithField("peer", addr).Debugf("Disconnected")
+	}
+}
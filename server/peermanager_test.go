@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+// Closing the manager answers every request, whether it was in flight,
+// queued, waiting to be queued or sent afterwards.
+func TestPeerManagerClose(t *testing.T) {
+	const addr = "127.0.0.1:1"
+	m, err := NewPeerManager([]string{addr}, NetworkConfig{
+		SendQueue:      4,
+		MaxInFlight:    1,
+		Timeout:        time.Minute,
+		BackoffMin:     10 * time.Millisecond,
+		BackoffMax:     10 * time.Millisecond,
+		FailuresToOpen: 100,
+		OpenFor:        time.Minute,
+	}, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 10)
+	done := func(ret interface{}, err error) { errs <- err }
+	started := make(chan struct{})
+	// holds the one slot until the manager is closed
+	m.Send(addr, func(ctx context.Context, c pb.AlgorandClient) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, done)
+	<-started
+
+	call := func(ctx context.Context, c pb.AlgorandClient) (interface{}, error) {
+		t.Errorf("request went out after the manager was closed")
+		return nil, nil
+	}
+	for i := 0; i < 3; i++ {
+		m.Send(addr, call, done)
+	}
+	m.SendAfter(50*time.Millisecond, addr, call, done)
+	m.Close()
+	m.Send(addr, call, done)
+
+	timeout := time.After(5 * time.Second)
+	closed := 0
+	for i := 0; i < 6; i++ {
+		select {
+		case err := <-errs:
+			if err == errManagerClosed {
+				closed++
+			}
+		case <-timeout:
+			t.Fatalf("%v of 6 requests answered", i)
+		}
+	}
+	// all but the one in flight, which sees its context canceled
+	if closed != 5 {
+		t.Errorf("%v requests failed with %v, want 5", closed, errManagerClosed)
+	}
+	select {
+	case <-m.Drained():
+	case <-timeout:
+		t.Errorf("not drained")
+	}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+// Protocol versions, in the order they were introduced. A block's Protocol field
+// names the version whose rules (runStepN, calculateHash, ...) it was built under.
+const (
+	protocolV1 = "algorand-v1"
+)
+
+// The version genesis blocks are created with.
+const genesisProtocol = protocolV1
+
+// The newest version this binary implements. Proposers vote to upgrade to it
+// whenever the chain is still running an older version.
+const latestProtocol = protocolV1
+
+// Per-version consensus parameters.
+type ConsensusParams struct {
+	// how many rounds a proposed upgrade has to collect approvals
+	UpgradeVoteRounds int64
+	// approvals needed, as a fraction of total stake
+	UpgradeThresholdNum int64
+	UpgradeThresholdDen int64
+	// rounds between an upgrade being approved and it taking effect
+	UpgradeWaitRounds int64
+
+	// largest encoded transaction, and sum of them, a block may hold
+	MaxTxBytes    int
+	MaxBlockBytes int
+	// how far ahead of our clock a proposed block's timestamp may be
+	MaxClockDrift time.Duration
+
+	// agreement timing and committee size nodes start with unless their config overrides them
+	Agreement agreement.Params
+}
+
+// The versions this node knows how to run.
+var supportedProtocols = map[string]ConsensusParams{
+	protocolV1: {
+		UpgradeVoteRounds:   100,
+		UpgradeThresholdNum: 2,
+		UpgradeThresholdDen: 3,
+		UpgradeWaitRounds:   20,
+		MaxTxBytes:          4 << 10,
+		MaxBlockBytes:       1 << 20,
+		MaxClockDrift:       15 * time.Second,
+		Agreement:           agreement.DefaultParams,
+	},
+}
+
+// UpgradeState is derived from the chain by replaying block headers, so every
+// node holding the same chain agrees on it.
+type UpgradeState struct {
+	Current string
+
+	// the version being voted on, "" if there is no open vote
+	Next string
+	// last round in which approvals for Next are counted
+	NextVoteBefore int64
+	// stake-weighted approvals for Next, counting each proposer once
+	approvers map[string]bool
+	approvals int64
+
+	// round at which Next becomes Current, 0 if not yet approved
+	SwitchOn int64
+}
+
+func newUpgradeState() UpgradeState {
+	return UpgradeState{Current: genesisProtocol, approvers: make(map[string]bool)}
+}
+
+func (us *UpgradeState) resetVote() {
+	us.Next = ""
+	us.NextVoteBefore = 0
+	us.approvers = make(map[string]bool)
+	us.approvals = 0
+	us.SwitchOn = 0
+}
+
+// apply advances the upgrade state past block. Blocks must be applied in order.
+func (us *UpgradeState) apply(block *pb.Block, idToStake map[string]int, totalStake int64) {
+	round := block.Id
+
+	if us.SwitchOn != 0 && round >= us.SwitchOn {
+		us.Current = us.Next
+		us.resetVote()
+	}
+
+	params, ok := supportedProtocols[us.Current]
+	if !ok {
+		// we can't interpret votes under rules we don't know, the node halts anyway
+		return
+	}
+
+	if us.SwitchOn != 0 {
+		// upgrade already approved, waiting for it to take effect
+		return
+	}
+
+	if us.Next != "" && round > us.NextVoteBefore {
+		logging.Ledger.WithField("round", round).Debugf("Upgrade to %v expired at round %v with %v/%v stake", us.Next, us.NextVoteBefore, us.approvals, totalStake)
+		us.resetVote()
+	}
+
+	vote := block.UpgradeVote
+	if vote == "" || vote == us.Current {
+		return
+	}
+
+	if us.Next == "" {
+		us.Next = vote
+		us.NextVoteBefore = round + params.UpgradeVoteRounds
+		logging.Ledger.WithField("round", round).Debugf("Upgrade to %v proposed by %v, voting closes at round %v", vote, block.Proposer, us.NextVoteBefore)
+	}
+
+	if vote != us.Next || us.approvers[block.Proposer] {
+		return
+	}
+
+	us.approvers[block.Proposer] = true
+	us.approvals += int64(idToStake[block.Proposer])
+
+	if us.approvals*params.UpgradeThresholdDen > totalStake*params.UpgradeThresholdNum {
+		us.SwitchOn = round + params.UpgradeWaitRounds
+		logging.Ledger.WithField("round", round).Debugf("Upgrade to %v approved with %v/%v stake, activates at round %v", us.Next, us.approvals, totalStake, us.SwitchOn)
+	}
+}
+
+// ProtocolAt returns the version the block for round must be built under.
+func (us *UpgradeState) ProtocolAt(round int64) string {
+	if us.SwitchOn != 0 && round >= us.SwitchOn {
+		return us.Next
+	}
+	return us.Current
+}
+
+// Vote returns what this node signals in the blocks it proposes.
+func (us *UpgradeState) Vote() string {
+	if us.Current != latestProtocol {
+		return latestProtocol
+	}
+	return ""
+}
+
+func totalStakeOf(idToStake map[string]int) int64 {
+	total := int64(0)
+	for _, stake := range idToStake {
+		total += int64(stake)
+	}
+	return total
+}
+
+// computeUpgradeState replays every header on the chain.
+func computeUpgradeState(blockchain []*pb.Block, idToStake map[string]int) UpgradeState {
+	us := newUpgradeState()
+	totalStake := totalStakeOf(idToStake)
+
+	for _, block := range blockchain {
+		if block.Id == 0 {
+			// genesis doesn't vote
+			continue
+		}
+		us.apply(block, idToStake, totalStake)
+	}
+	return us
+}
+
+// verifyProtocol checks that every block on the chain was built under the
+// version that was active at its round.
+func verifyProtocol(blockchain []*pb.Block, idToStake map[string]int) error {
+	us := newUpgradeState()
+	totalStake := totalStakeOf(idToStake)
+
+	for _, block := range blockchain {
+		expected := us.ProtocolAt(block.Id)
+		if block.Id == 0 {
+			expected = genesisProtocol
+		}
+		if block.Protocol != expected {
+			return fmt.Errorf("block %v built under protocol %q, expected %q", block.Id, block.Protocol, expected)
+		}
+		if block.Id != 0 {
+			us.apply(block, idToStake, totalStake)
+		}
+	}
+	return nil
+}
+
+func isSupportedProtocol(version string) bool {
+	_, ok := supportedProtocols[version]
+	return ok
+}
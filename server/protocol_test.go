@@ -0,0 +1,149 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+const protocolV2 = "algorand-v2"
+
+// Four proposers with one unit of stake each, an upgrade needs more than
+// two thirds of it: three of them.
+var upgradeStake = map[string]int{"a": 1, "b": 1, "c": 1, "d": 1}
+
+// upgradeChain builds a chain with a block for every proposer in turn, each
+// built under the protocol active at its round and voting for vote.
+func upgradeChain(proposers []string, vote string) []*pb.Block {
+	chain := []*pb.Block{{Id: 0, Protocol: genesisProtocol}}
+	us := newUpgradeState()
+	for i, proposer := range proposers {
+		block := &pb.Block{Id: int64(i + 1), Proposer: proposer, UpgradeVote: vote}
+		block.Protocol = us.ProtocolAt(block.Id)
+		us.apply(block, upgradeStake, totalStakeOf(upgradeStake))
+		chain = append(chain, block)
+	}
+	return chain
+}
+
+// repeat lists proposers, then fills up to n rounds with the last of them.
+func repeat(n int, proposers ...string) []string {
+	for len(proposers) < n {
+		proposers = append(proposers, proposers[len(proposers)-1])
+	}
+	return proposers
+}
+
+func TestUpgrade(t *testing.T) {
+	wait := supportedProtocols[protocolV1].UpgradeWaitRounds
+	voteRounds := supportedProtocols[protocolV1].UpgradeVoteRounds
+
+	tests := []struct {
+		name      string
+		proposers []string
+		// round the upgrade activates at, 0 if it doesn't
+		switchOn int64
+	}{
+		{"approved by three of four", []string{"a", "b", "c"}, 3 + wait},
+		{"two of four aren't enough", repeat(10, "a", "b"), 0},
+		{"a proposer counts once", repeat(10, "a", "b", "b", "a"), 0},
+		{"approvals spread over the vote", append(repeat(int(voteRounds), "a", "b"), "c"), 1 + voteRounds + wait},
+		{"approvals after the vote closes", append(repeat(int(voteRounds)+1, "a", "b"), "c"), 0},
+	}
+	for _, test := range tests {
+		us := computeUpgradeState(upgradeChain(test.proposers, protocolV2), upgradeStake)
+		if us.SwitchOn != test.switchOn {
+			t.Errorf("%v: activates at round %v, want %v", test.name, us.SwitchOn, test.switchOn)
+			continue
+		}
+		if test.switchOn == 0 {
+			if got := us.ProtocolAt(int64(len(test.proposers)) + wait + 1); got != protocolV1 {
+				t.Errorf("%v: runs %v later on", test.name, got)
+			}
+			continue
+		}
+		if got := us.ProtocolAt(test.switchOn - 1); got != protocolV1 {
+			t.Errorf("%v: round %v runs %v before the switch", test.name, test.switchOn-1, got)
+		}
+		if got := us.ProtocolAt(test.switchOn); got != protocolV2 {
+			t.Errorf("%v: round %v runs %v after the switch", test.name, test.switchOn, got)
+		}
+	}
+}
+
+func TestUpgradeActivates(t *testing.T) {
+	wait := supportedProtocols[protocolV1].UpgradeWaitRounds
+	chain := upgradeChain(repeat(int(3+wait), "a", "b", "c"), protocolV2)
+
+	if err := verifyProtocol(chain, upgradeStake); err != nil {
+		t.Fatal(err)
+	}
+	us := computeUpgradeState(chain, upgradeStake)
+	if us.Current != protocolV2 || us.Next != "" || us.SwitchOn != 0 {
+		t.Errorf("after the switch: current %v, next %q, switch on %v", us.Current, us.Next, us.SwitchOn)
+	}
+
+	// a block still built under the old version once the new one is active
+	chain[len(chain)-1].Protocol = protocolV1
+	if err := verifyProtocol(chain, upgradeStake); err == nil {
+		t.Errorf("accepted a block built under the old protocol after the switch")
+	}
+	chain[len(chain)-1].Protocol = protocolV2
+	chain[len(chain)-2].Protocol = protocolV2
+	if err := verifyProtocol(chain, upgradeStake); err == nil {
+		t.Errorf("accepted a block built under the new protocol before the switch")
+	}
+}
+
+// A node that doesn't implement the protocol the chain switched to stops
+// taking part in agreement, one that does keeps going.
+func TestHaltOnUnsupportedProtocol(t *testing.T) {
+	wait := supportedProtocols[protocolV1].UpgradeWaitRounds
+	approved := upgradeChain([]string{"a", "b", "c"}, protocolV2)
+
+	tests := []struct {
+		name   string
+		chain  []*pb.Block
+		halted bool
+	}{
+		{"approved, not active yet", approved, false},
+		// the chain holds rounds up to the one before the switch, the
+		// next round is built under the new version
+		{"active from the next round", upgradeChain(repeat(int(3+wait-1), "a", "b", "c"), protocolV2), true},
+		{"vote for a version we run", upgradeChain(repeat(int(3+wait), "a", "b", "c"), protocolV1), false},
+	}
+	for _, test := range tests {
+		state := &ServerState{}
+		checkProtocol(&BCStore{blockchain: test.chain}, state, upgradeStake)
+		if state.halted != test.halted {
+			t.Errorf("%v: halted %v, want %v", test.name, state.halted, test.halted)
+		}
+	}
+}
+
+// The hash commits to every header field, including where one ends and
+// the next starts.
+func TestHashHeader(t *testing.T) {
+	base := pb.Block{Id: 3, Timestamp: "now", PrevHash: "prev", Protocol: "v1", UpgradeVote: "2", Proposer: "a", Seed: "s", SeedProof: "p"}
+	tests := []struct {
+		name  string
+		apply func(*pb.Block)
+	}{
+		{"protocol", func(b *pb.Block) { b.Protocol = "v2" }},
+		{"upgrade vote", func(b *pb.Block) { b.UpgradeVote = "" }},
+		{"proposer", func(b *pb.Block) { b.Proposer = "b" }},
+		{"seed", func(b *pb.Block) { b.Seed = "t" }},
+		{"seed proof", func(b *pb.Block) { b.SeedProof = "" }},
+		{"protocol takes the vote", func(b *pb.Block) { b.Protocol, b.UpgradeVote = "v12", "" }},
+		{"vote takes the proposer", func(b *pb.Block) { b.UpgradeVote, b.Proposer = "2a", "" }},
+		{"seed takes the proof", func(b *pb.Block) { b.Seed, b.SeedProof = "sp", "" }},
+	}
+	hash := calculateHash(&base)
+	for _, test := range tests {
+		b := base
+		test.apply(&b)
+		if calculateHash(&b) == hash {
+			t.Errorf("%v: same hash", test.name)
+		}
+	}
+}
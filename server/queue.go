@@ -0,0 +1,38 @@
+package main
+
+import (
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/metrics"
+)
+
+// Every handler hands its request to the serve loop through a bounded queue.
+// A full queue is reported to the caller instead of piling up goroutines, and
+// the caller stops waiting once its deadline passes. The loop always answers
+// on a buffered channel, so a caller that gave up never blocks it.
+//
+// Queues are named after what they carry, for the errors and metrics.
+const (
+	voteQueue        = "vote"
+	proposalQueue    = "proposal"
+	transactionQueue = "transaction"
+	syncQueue        = "sync"
+	clientQueue      = "client"
+	adminQueue       = "admin"
+)
+
+func overloaded(queue string) error {
+	metrics.RejectedRequests.WithLabelValues(queue, "full").Inc()
+	return status.Errorf(codes.ResourceExhausted, "%v queue is full, try again later", queue)
+}
+
+// gaveUp is returned when ctx ended before the serve loop answered.
+func gaveUp(ctx context.Context, queue string) error {
+	metrics.RejectedRequests.WithLabelValues(queue, "deadline").Inc()
+	if ctx.Err() == context.DeadlineExceeded {
+		return status.Error(codes.DeadlineExceeded, ctx.Err().Error())
+	}
+	return status.Error(codes.Canceled, ctx.Err().Error())
+}
@@ -1,51 +1,34 @@
 package main
 
 import (
-	"fmt"
-	"log"
+	"math/rand"
 	"net"
 	"time"
 	"strings"
 	"sort"
-	// "math/rand"
-	"strconv"
 
 	context "golang.org/x/net/context"
+	"golang.org/x/crypto/ed25519"
 	"google.golang.org/grpc"
 
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/byzantine"
+	"github.com/nyu-distributed-systems-fa18/algorand/ledger"
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+	"github.com/nyu-distributed-systems-fa18/algorand/metrics"
+	"github.com/nyu-distributed-systems-fa18/algorand/participation"
 	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+	"github.com/nyu-distributed-systems-fa18/algorand/vrf"
 )
 
-// Persistent and volatile state
+// Persistent and volatile state. Round, period and step live in the agreement service.
 type ServerState struct {
-	privateKey   		int64
-	publicKey 	 		int64
-	round		 		int64
-	readyForNextRound 	bool
 	tempBlock	 		*pb.Block
-	proposedBlock 		*pb.Block
 	seed 				string
-	periodState			PeriodState
-	lastPeriodState		PeriodState
-	period int64
-	step int64
-}
-
-type PeriodState struct {
-	proposedValues	map[string]string
-	valueToBlock	map[string]*pb.Block
-
-	nextVotes		map[string]int64
-	softVotes		map[string]int64
-	certVotes		map[string]int64
-
-	haveNextVoted	map[string]bool
-	haveSoftVoted	map[string]bool
-	haveCertVoted	map[string]bool
-
-	myCertVote  	string
-	startingValue	string
-	period			int64
+	upgrade				UpgradeState
+	halted				bool
+	// balances and participation keys after the last block on our chain
+	accounts			*ledger.Ledger
 }
 
 type AppendBlockInput struct {
@@ -63,6 +46,11 @@ type ProposeBlockInput struct {
 	response chan pb.ProposeBlockRet
 }
 
+type ProposePriorityInput struct {
+	arg *pb.ProposePriorityArgs
+	response chan pb.ProposePriorityRet
+}
+
 type VoteInput struct {
 	arg *pb.VoteArgs
 	response chan pb.VoteRet
@@ -73,177 +61,290 @@ type RequestBlockChainInput struct {
 	response chan pb.RequestBlockChainRet
 }
 
+type GetProposalInput struct {
+	arg *pb.GetProposalArgs
+	response chan pb.GetProposalRet
+}
+
 type Algorand struct {
 	AppendBlockChan chan AppendBlockInput
 	AppendTransactionChan chan AppendTransactionInput
 	ProposeBlockChan chan ProposeBlockInput
+	ProposePriorityChan chan ProposePriorityInput
 	VoteChan chan VoteInput
 	RequestBlockChainChan chan RequestBlockChainInput
+	GetProposalChan chan GetProposalInput
+	// closed once the serve loop stops taking requests
+	done <-chan struct{}
 }
 
 func (a *Algorand) AppendBlock(ctx context.Context, arg *pb.AppendBlockArgs) (*pb.AppendBlockRet, error) {
-	c := make(chan pb.AppendBlockRet)
-	a.AppendBlockChan <- AppendBlockInput{arg: arg, response: c}
-	result := <-c
-	return &result, nil
+	c := make(chan pb.AppendBlockRet, 1)
+	select {
+	case a.AppendBlockChan <- AppendBlockInput{arg: arg, response: c}:
+	case <-a.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(syncQueue)
+	}
+	select {
+	case result := <-c:
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, syncQueue)
+	case <-a.done:
+		return nil, errShuttingDown
+	}
 }
 
 func (a *Algorand) AppendTransaction(ctx context.Context, arg *pb.AppendTransactionArgs) (*pb.AppendTransactionRet, error) {
-	c := make(chan pb.AppendTransactionRet)
-	a.AppendTransactionChan <- AppendTransactionInput{arg: arg, response: c}
-	result := <-c
-	return &result, nil
+	c := make(chan pb.AppendTransactionRet, 1)
+	select {
+	case a.AppendTransactionChan <- AppendTransactionInput{arg: arg, response: c}:
+	case <-a.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(transactionQueue)
+	}
+	select {
+	case result := <-c:
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, transactionQueue)
+	case <-a.done:
+		return nil, errShuttingDown
+	}
 }
 
 func (a *Algorand) Vote(ctx context.Context, arg *pb.VoteArgs) (*pb.VoteRet, error) {
-	c := make(chan pb.VoteRet)
-	a.VoteChan <- VoteInput{arg: arg, response: c}
-	result := <-c
-	return &result, nil
+	c := make(chan pb.VoteRet, 1)
+	select {
+	case a.VoteChan <- VoteInput{arg: arg, response: c}:
+	case <-a.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(voteQueue)
+	}
+	select {
+	case result := <-c:
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, voteQueue)
+	case <-a.done:
+		return nil, errShuttingDown
+	}
 }
 
 func (a *Algorand) ProposeBlock(ctx context.Context, arg *pb.ProposeBlockArgs) (*pb.ProposeBlockRet, error) {
-	c := make(chan pb.ProposeBlockRet)
-	a.ProposeBlockChan <- ProposeBlockInput{arg: arg, response: c}
-	result := <-c
-	return &result, nil
+	c := make(chan pb.ProposeBlockRet, 1)
+	select {
+	case a.ProposeBlockChan <- ProposeBlockInput{arg: arg, response: c}:
+	case <-a.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(proposalQueue)
+	}
+	select {
+	case result := <-c:
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, proposalQueue)
+	case <-a.done:
+		return nil, errShuttingDown
+	}
+}
+
+// A proposer announces its priority ahead of its block
+func (a *Algorand) ProposePriority(ctx context.Context, arg *pb.ProposePriorityArgs) (*pb.ProposePriorityRet, error) {
+	c := make(chan pb.ProposePriorityRet, 1)
+	select {
+	case a.ProposePriorityChan <- ProposePriorityInput{arg: arg, response: c}:
+	case <-a.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(proposalQueue)
+	}
+	select {
+	case result := <-c:
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, proposalQueue)
+	case <-a.done:
+		return nil, errShuttingDown
+	}
 }
 
 func (a *Algorand) RequestBlockChain(ctx context.Context, arg *pb.RequestBlockChainArgs) (*pb.RequestBlockChainRet, error) {
-	c := make(chan pb.RequestBlockChainRet)
-	a.RequestBlockChainChan <- RequestBlockChainInput{arg: arg, response: c}
-	result := <-c
-	return &result, nil
+	c := make(chan pb.RequestBlockChainRet, 1)
+	select {
+	case a.RequestBlockChainChan <- RequestBlockChainInput{arg: arg, response: c}:
+	case <-a.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(syncQueue)
+	}
+	select {
+	case result := <-c:
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, syncQueue)
+	case <-a.done:
+		return nil, errShuttingDown
+	}
+}
+
+// A peer that saw a value certified without its body asks us for the block
+func (a *Algorand) GetProposal(ctx context.Context, arg *pb.GetProposalArgs) (*pb.GetProposalRet, error) {
+	c := make(chan pb.GetProposalRet, 1)
+	select {
+	case a.GetProposalChan <- GetProposalInput{arg: arg, response: c}:
+	case <-a.done:
+		return nil, errShuttingDown
+	default:
+		return nil, overloaded(proposalQueue)
+	}
+	select {
+	case result := <-c:
+		return &result, nil
+	case <-ctx.Done():
+		return nil, gaveUp(ctx, proposalQueue)
+	case <-a.done:
+		return nil, errShuttingDown
+	}
 }
 
-// Launch a GRPC service for this peer.
-func RunAlgorandServer(algorand *Algorand, port int) {
-	// Convert port to a string form
-	portString := fmt.Sprintf(":%d", port)
-	// Create socket that listens on the supplied port
-	c, err := net.Listen("tcp", portString)
+// Launch a GRPC service for this peer, it runs until ctx is done.
+func RunAlgorandServer(ctx context.Context, algorand *Algorand, addr string, opts []grpc.ServerOption, grace time.Duration) {
+	// Create socket that listens on the supplied address
+	c, err := net.Listen("tcp", addr)
 	if err != nil {
 		// Note the use of Fatalf which will exit the program after reporting the error.
-		log.Fatalf("Could not create listening socket %v", err)
+		logging.Network.Fatalf("Could not create listening socket %v", err)
 	}
 	// Create a new GRPC server
-	s := grpc.NewServer()
+	s := grpc.NewServer(opts...)
 
 	pb.RegisterAlgorandServer(s, algorand)
-	log.Printf("Going to listen on port %v", port)
+	logging.Network.Infof("Going to listen on %v", addr)
+
+	go func() {
+		<-ctx.Done()
+		stopServer(s, grace)
+	}()
 
 	// Start serving, this will block this function and only return when done.
 	if err := s.Serve(c); err != nil {
-		log.Fatalf("Failed to serve %v", err)
+		logging.Network.Fatalf("Failed to serve %v", err)
 	}
 }
 
-func connectToPeer(peer string) (pb.AlgorandClient, error) {
-	backoffConfig := grpc.DefaultBackoffConfig
-	// Choose an aggressive backoff strategy here.
-	backoffConfig.MaxDelay = 500 * time.Millisecond
-	conn, err := grpc.Dial(peer, grpc.WithInsecure(), grpc.WithBackoffConfig(backoffConfig))
-	// Ensure connection did not fail, which should not happen since this happens in the background
-	if err != nil {
-		return pb.NewAlgorandClient(nil), err
-	}
-	return pb.NewAlgorandClient(conn), nil
-}
+// Recompute the upgrade state after the chain changed. If the chain has moved on
+// to a protocol version this binary doesn't implement we stop taking part in
+// agreement rather than silently disagreeing with the rest of the network.
+func checkProtocol(bcs *BCStore, state *ServerState, idToStake map[string]int) {
+	state.upgrade = computeUpgradeState(bcs.blockchain, idToStake)
 
-func restartTimer(timer *time.Timer, ms int64) {
-	stopped := timer.Stop()
+	// the chain holds every round up to the one being agreed on
+	round := int64(len(bcs.blockchain))
 
-	if !stopped {
-		for len(timer.C) > 0 {
-			<-timer.C
+	version := state.upgrade.ProtocolAt(round)
+	if !isSupportedProtocol(version) {
+		if !state.halted {
+			logging.Ledger.WithField("round", round).Errorf("HALTING: round %v runs protocol %v which this node does not support, please upgrade", round, version)
 		}
-
+		state.halted = true
+		return
 	}
-	timer.Reset(time.Duration(ms) * time.Millisecond)
-}
-
-func initPeriodState(p int64) PeriodState {
-	newPeriodState := PeriodState{
-		proposedValues: make(map[string]string),
-		valueToBlock:   make(map[string]*pb.Block),
-
-		nextVotes: 		make(map[string]int64),
-		softVotes: 		make(map[string]int64),
-		certVotes: 		make(map[string]int64),
 
-		haveNextVoted:	make(map[string]bool),
-		haveSoftVoted:	make(map[string]bool),
-		haveCertVoted:	make(map[string]bool),
-
-		myCertVote: 	"",
-		startingValue: 	"",
-		period: 		p,
+	if state.upgrade.SwitchOn != 0 && !isSupportedProtocol(state.upgrade.Next) {
+		logging.Ledger.WithField("round", round).Warnf("protocol %v activates at round %v and is not supported by this node, upgrade before then", state.upgrade.Next, state.upgrade.SwitchOn)
 	}
-
-	return newPeriodState
 }
 
-func handleHalt(bcs *BCStore, state *ServerState, newBlock *pb.Block) {
-    log.Printf("AGREEMENT!")
-    bcs.blockchain = append(bcs.blockchain, newBlock)
-    log.Printf("Chain: %v", PrettyPrint(bcs.blockchain))
-
-    // Handle Halting Condition
-    state.readyForNextRound = true
-    state.round++
 
-    state.lastPeriodState = PeriodState{}
-    state.period = int64(1)
-    state.step = int64(1)
-    state.periodState = initPeriodState(state.period)
-    state.periodState.startingValue = "_|_"
+// resultLabel is the result label of a message agreement handled. Errors map
+// to a fixed set of labels: their text can come from peers, and every new
+// label value is another series to keep.
+func resultLabel(err error) string {
+	switch err {
+	case nil:
+		return "ok"
+	case agreement.ErrFutureRound:
+		return "future round"
+	case agreement.ErrPastRound:
+		return "past round"
+	case agreement.ErrPastPeriod:
+		return "past period"
+	case agreement.ErrNotOnCommittee:
+		return "not on committee"
+	case agreement.ErrBadSignature, agreement.ErrUnsignedVote:
+		return "bad signature"
+	case agreement.ErrAlreadyVoted:
+		return "already voted"
+	case agreement.ErrEquivocation:
+		return "equivocation"
+	case agreement.ErrUnknownVote:
+		return "unknown vote type"
+	default:
+		return "invalid"
+	}
 }
 
-// The main service loop.
-func serve(bcs *BCStore, peers *arrayPeers, id string, port int) {
-
-	log.Printf("peers: %#v", peers)
+// The main service loop. BA* itself runs in the agreement service, this loop
+// wires it up to the blockchain, the client API and our peers.
+// serve runs agreement until ctx is done. It then lets the votes in flight
+// reach our peers and disconnects from them.
+func serve(ctx context.Context, bcs *BCStore, admin *Admin, cfg *Config, id string, byzantineModes byzantine.Modes, journal agreement.Journal) error {
+	peers := cfg.Peers
+	logging.Network.Debugf("peers: %#v", peers)
 
 	algorand := Algorand{
-		AppendBlockChan: make(chan AppendBlockInput),
-		AppendTransactionChan: make(chan AppendTransactionInput),
-		ProposeBlockChan: make(chan ProposeBlockInput),
-		VoteChan: make(chan VoteInput),
-		RequestBlockChainChan: make(chan RequestBlockChainInput),
+		AppendBlockChan: make(chan AppendBlockInput, cfg.Queues.Client),
+		AppendTransactionChan: make(chan AppendTransactionInput, cfg.Queues.Client),
+		ProposeBlockChan: make(chan ProposeBlockInput, cfg.Queues.Consensus),
+		ProposePriorityChan: make(chan ProposePriorityInput, cfg.Queues.Consensus),
+		VoteChan: make(chan VoteInput, cfg.Queues.Consensus),
+		RequestBlockChainChan: make(chan RequestBlockChainInput, cfg.Queues.Client),
+		GetProposalChan: make(chan GetProposalInput, cfg.Queues.Consensus),
+		done: ctx.Done(),
 	}
 	// Start in a Go routine so it doesn't affect us.
-	go RunAlgorandServer(&algorand, port)
+	serverOptions, err := cfg.serverOptions(true)
+	if err != nil {
+		logging.Network.Fatalf("Could not load TLS certificate %v", err)
+	}
+	transport, err := cfg.peerDialOption()
+	if err != nil {
+		logging.Network.Fatalf("Could not load TLS certificate %v", err)
+	}
+	go RunAlgorandServer(ctx, &algorand, cfg.Listen.Algorand, serverOptions, cfg.ShutdownTimeout)
 
 	state := ServerState{
-		privateKey: 0,
-		publicKey: 0,
-		round: 1,
-		readyForNextRound: true,
 		seed: "thisshouldbeahash", // R in the paper
 	}
 	state.tempBlock = new(pb.Block)
+	state.accounts, err = ledger.Genesis(bcs.blockchain[0])
+	if err != nil {
+		logging.Ledger.Fatalf("Invalid genesis block %v", err)
+	}
 
-	peerClients := make(map[string]pb.AlgorandClient)
+	peerManager, err := NewPeerManager(peers, cfg.Network, transport)
+	if err != nil {
+		logging.Network.Fatalf("Failed to connect to GRPC server %v", err)
+	}
 	peerCount := int64(0)
-	userIds := make([]string, len(*peers) + 1)
-
-	for i, peer := range *peers {
-		client, err := connectToPeer(peer)
-		if err != nil {
-			log.Fatalf("Failed to connect to GRPC server %v", err)
-		}
-
-		peerClients[peer] = client
+	userIds := make([]string, len(peers) + 1)
 
+	for i, peer := range peers {
 		split := strings.Split(peer, ":")
 		userIds[i] = split[1]
 
 		peerCount++
-		log.Printf("Connected to %v", peer)
+		logging.Network.WithField("peer", peer).Infof("Connected")
 	}
 
 	if peerCount < 3 {
-		log.Fatalf("Need at least 4 nodes to achieve Byzantine fault tolerance")
+		logging.Agreement.Fatalf("Need at least 4 nodes to achieve Byzantine fault tolerance")
 	}
 
 	// 2t+1 required votes for Byzantine fault tolerance
@@ -257,7 +358,7 @@ func serve(bcs *BCStore, peers *arrayPeers, id string, port int) {
 
 	// sort userIds so all Algorand servers have the same list of userIds
 	sort.Strings(userIds)
-	log.Printf("UserIds: %#v", userIds)
+	logging.Agreement.Infof("UserIds: %#v", userIds)
 
 	type AppendBlockResponse struct {
 		ret *pb.AppendBlockRet
@@ -271,18 +372,6 @@ func serve(bcs *BCStore, peers *arrayPeers, id string, port int) {
 		peer string
 	}
 
-	type ProposeBlockResponse struct {
-		ret *pb.ProposeBlockRet
-		err error
-		peer string
-	}
-
-	type VoteResponse struct {
-		ret *pb.VoteRet
-		err error
-		peer string
-	}
-
 	type RequestBlockChainResponse struct {
 		ret *pb.RequestBlockChainRet
 		err error
@@ -291,16 +380,23 @@ func serve(bcs *BCStore, peers *arrayPeers, id string, port int) {
 
 	appendBlockResponseChan := make(chan AppendBlockResponse)
 	appendTransactionResponseChan := make(chan AppendTransactionResponse)
-	proposeBlockResponseChan := make(chan ProposeBlockResponse)
-	voteResponseChan := make(chan VoteResponse)
-	requestBlockChainResponseChan := make(chan RequestBlockChainResponse)
-
-	// Set timer to check for new rounds
-	roundTimer := time.NewTimer(5000 * time.Millisecond)
-	agreementTimer := time.NewTimer(10000 * time.Millisecond)
+	type GetProposalResponse struct {
+		ret *pb.GetProposalRet
+		err error
+		peer string
+		arg *pb.GetProposalArgs
+	}
 
-	// set hardcode k to be 2 for now, so 2 members will always be selected to committee
-	k := int64(2)
+	requestBlockChainResponseChan := make(chan RequestBlockChainResponse)
+	getProposalResponseChan := make(chan GetProposalResponse)
+
+	network := &peerNetwork{
+		userId: userId,
+		peers: peerManager,
+		proposeBlockResponseChan: make(chan ProposeBlockResponse),
+		proposePriorityResponseChan: make(chan ProposePriorityResponse),
+		voteResponseChan: make(chan VoteResponse),
+	}
 
 	// intialize everyone's stake between 1 to 10 tokens
 	idToStake := initStake(userIds, 1, 10)
@@ -308,413 +404,578 @@ func serve(bcs *BCStore, peers *arrayPeers, id string, port int) {
 	// generate candidates using every user's stake which will be used for sortition
 	candidates := generateCandidatesByStake(userIds, idToStake)
 
+	// the key we prove sortition with, and everyone's to check their proofs
+	vrfKey, vrfKeys, err := cfg.vrfKeys(userIds)
+	if err != nil {
+		logging.Agreement.Fatalf("Invalid VRF keys %v", err)
+	}
+	if len(cfg.Genesis.VRFKeys) == 0 {
+		logging.Agreement.Warnf("INSECURE: no genesis.vrf_keys configured, sortition runs on keys anyone can derive from the user ids")
+	}
 
-	//Prepare periodState
-	state.period = int64(1)
-	state.step = int64(1)
-	state.periodState = initPeriodState(state.period)
-	state.periodState.startingValue = "_|_"
+	// the keys we vote with, whichever the ledger says is ours for the round
+	participationKeys, err := cfg.participationKeys()
+	if err != nil {
+		logging.Agreement.Fatalf("Invalid participation keys %v", err)
+	}
+	if cfg.Keys.Keystore == "" && len(cfg.Genesis.Participants) == 0 {
+		logging.Agreement.Warnf("INSECURE: no keys.keystore configured, votes are signed with keys anyone can derive from the user ids")
+	}
+	signer := participation.NewKeys(participationKeys, func(round int64) ed25519.PublicKey {
+		return state.accounts.VotingKey(userId, round)
+	})
+
+	// only for testing: misbehave on purpose so the honest nodes have something to tolerate
+	var consensusNetwork agreement.Network = network
+	var adversary *byzantine.Network
+	if len(byzantineModes) > 0 {
+		logging.Network.Warnf("running as a BYZANTINE node (%v), this node will attack the network", byzantineModes)
+		adversary = byzantine.NewNetwork(network, byzantine.Config{
+			UserId: userId,
+			Modes: byzantineModes,
+			Hash: calculateHash,
+			Signer: signer,
+			Rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+		})
+		consensusNetwork = adversary
+	}
 
-	// Run forever handling inputs from various channels
-	for {
-		select{
-		case <-roundTimer.C:
-			// propose block if last round complete or very first round
-			if state.readyForNextRound {
-				log.Printf("Starting round %v, period %v", state.round, state.period)
-				state.readyForNextRound = false
+	service := agreement.NewService(agreement.Config{
+		UserId: userId,
+		Signer: signer,
+		Candidates: candidates,
+		VRFKeys: vrfKeys,
+		Seed: bcs.blockchain[0].Seed,
+		RequiredVotes: requiredVotes,
+		Params: cfg.Protocol.Params(),
+		FutureRounds: cfg.Queues.FutureRounds,
+		FutureMessages: cfg.Queues.FutureMessages,
+	}, agreement.SystemClock, consensusNetwork)
+	if journal != nil {
+		service.SetJournal(journal)
+	}
 
-				// we don't want step two to happen too quick before users can collect proposedBlocks
-				restartTimer(agreementTimer, 10000)
+	checkProtocol(bcs, &state, idToStake)
 
-				// we capture our tempBlock at the time agreement starts. We will reconcile this block after agreement ends
-				state.proposedBlock = prepareBlock(state.tempBlock, bcs.blockchain)
-				b := state.proposedBlock
-				v := calculateHash(state.proposedBlock)
-
-				// each server needs exact same seed per round so they all see the same selection
-				_, _, votes := sortition(state.privateKey, state.round, "proposer", userId, candidates, k)
-
-				sigParams := []string{strconv.FormatInt(state.round, 10), strconv.FormatInt(state.period, 10)}
-
-				sig := SIG(userId, sigParams)
-
-				// Value proposal step
-				for votes > 0 {
-					// add your own proposal to proposedBlock map
-					proposerCredential := []string{userId, sig.SignedMessage}
-					proposerHash := signMessage(proposerCredential)
-					state.periodState.proposedValues[proposerHash] = v
-					state.periodState.valueToBlock[v] = b
-
-					// broadcast proposal
-					for p, c := range peerClients {
-						go func(c pb.AlgorandClient, p string, b *pb.Block, v string, sig *pb.SIGRet, round int64) {
-							log.Printf("Sent proposal to peer %v", p)
-							ret, err := c.ProposeBlock(context.Background(), &pb.ProposeBlockArgs{Block: b, Credential: sig, Value: v, Round: round, Peer: userId})
-							proposeBlockResponseChan <- ProposeBlockResponse{ret: ret, err: err, peer: p}
-						}(c, p, b, v, sig, state.round)
-					}
-					votes--
-				}
-			}
+	// forget the one-time keys of the rounds behind us, nobody can make us vote in them again
+	erasePastKeys := func() {
+		if err := signer.Erase(service.Round()); err != nil {
+			logging.Agreement.WithField("round", service.Round()).Errorf("%v", err)
+		}
+	}
 
-			restartTimer(roundTimer, 5000)
+	requestBlockChains := func() {
+		for _, p := range peerManager.Peers() {
+			p := p
+			peerManager.Send(p, func(ctx context.Context, c pb.AlgorandClient) (interface{}, error) {
+				return c.RequestBlockChain(ctx, &pb.RequestBlockChainArgs{Peer: userId})
+			}, func(ret interface{}, err error) {
+				r, _ := ret.(*pb.RequestBlockChainRet)
+				requestBlockChainResponseChan <- RequestBlockChainResponse{ret: r, err: err, peer: p}
+			})
+		}
+	}
 
-		case <-agreementTimer.C:
-			// if we are currently in agreement protocol
-			if !state.readyForNextRound && state.step < 5 {
-				state.step++
+	// ask the voters that certified a value for its block, anyone else if none of them is a peer
+	fetchBlock := func(fetch agreement.FetchBlock) {
+		voters := make(map[string]bool)
+		for _, v := range fetch.Voters {
+			voters[v] = true
+		}
+		targets := []string{}
+		for _, p := range peerManager.Peers() {
+			if voters[strings.Split(p, ":")[1]] {
+				targets = append(targets, p)
 			}
+		}
+		if len(targets) == 0 {
+			targets = peerManager.Peers()
+		}
 
-			if state.step == 2 {
-				log.Printf("STEP 2")
-				softVoteV := runStep2(&state.periodState, &state.lastPeriodState, requiredVotes)
-				log.Printf("soft vote is %v", softVoteV)
-
-				if softVoteV != "" {
-					// add my own vote for this value
-					state.periodState.softVotes[softVoteV]++
-
-					// broadcast my decision to vote for this value
-					message := []string{softVoteV, "soft", strconv.FormatInt(state.period, 10)}
-					softVoteSIG := SIG(userId, message)
-
-					for p, c := range peerClients {
-						go func(c pb.AlgorandClient, p string, softVoteSIG *pb.SIGRet, round int64) {
-							log.Printf("Sent soft vote to peer %v", p)
-							ret, err := c.Vote(context.Background(), &pb.VoteArgs{Message: softVoteSIG, Round: round, Peer: userId})
-							voteResponseChan <- VoteResponse{ret: ret, err: err, peer: p}
-						}(c, p, softVoteSIG, state.round)
-					}
+		arg := &pb.GetProposalArgs{Round: fetch.Round, Period: fetch.Period, Value: fetch.Value, Peer: userId}
+		for _, p := range targets {
+			p := p
+			peerManager.Send(p, func(ctx context.Context, c pb.AlgorandClient) (interface{}, error) {
+				return c.GetProposal(ctx, arg)
+			}, func(ret interface{}, err error) {
+				r, _ := ret.(*pb.GetProposalRet)
+				getProposalResponseChan <- GetProposalResponse{ret: r, err: err, peer: p, arg: arg}
+			})
+		}
+	}
+
+	// keep the metrics in step with the service after every event
+	lastRound := service.Round()
+	roundStart := time.Now()
+	observe := func() {
+		if service.Round() != lastRound {
+			lastRound = service.Round()
+			roundStart = time.Now()
+		}
+		metrics.Round.Set(float64(service.Round()))
+		metrics.Period.Set(float64(service.Period()))
+		metrics.Step.Set(float64(service.Step()))
+		metrics.ChainLength.Set(float64(len(bcs.blockchain)))
+		metrics.TentativeBlocks.Set(float64(int64(len(bcs.blockchain)) - 1 - bcs.finalRound()))
+		metrics.MempoolSize.Set(float64(len(state.tempBlock.Tx)))
+	}
+
+	// checkProposal keeps a proposal whose block doesn't follow our chain from
+	// ever becoming a candidate, it can only be checked in its own round
+	checkProposal := func(arg *pb.ProposeBlockArgs) error {
+		err := validateProposal(arg, bcs.blockchain, state.accounts, vrfKeys, &state.upgrade, time.Now())
+		if err != nil {
+			logging.Agreement.WithFields(logging.Fields{"peer": arg.Peer, "round": arg.Round}).Warnf("DENIED proposal %.8v: %v", arg.Value, err)
+			metrics.ProposalsReceived.WithLabelValues("invalid block").Inc()
+		}
+		return err
+	}
+
+	// Carry out what the agreement service hands back to us
+	var execute func(actions []agreement.Action)
+	execute = func(actions []agreement.Action) {
+		for _, action := range actions {
+			switch a := action.(type) {
+			case agreement.AssembleBlock:
+				// we capture our tempBlock at the time agreement starts. We will reconcile this block after agreement ends
+				b := prepareBlock(state.tempBlock, bcs.blockchain, userId, vrfKey, &state.upgrade, state.accounts)
+				_, proof := vrf.Prove(vrfKey, a.Seed)
+				more, _ := service.Handle(agreement.BlockAssembled{Round: a.Round, Period: a.Period, Block: b, Value: calculateHash(b), Proof: proof})
+				execute(more)
+				if adversary != nil {
+					adversary.Assembled(a.Round, a.Period, b, calculateHash(b), proof)
 				}
-			} else if state.step == 3 {
-				log.Printf("STEP 3")
-				certVoteV := runStep3(&state.periodState, requiredVotes)
-				log.Printf("cert vote is %v", certVoteV)
-
-				if certVoteV != "" {
-					// add my own vote for this value
-					state.periodState.certVotes[certVoteV]++
-					state.periodState.myCertVote = certVoteV;
-
-					message := []string{certVoteV, "cert", strconv.FormatInt(state.period, 10)}
-					certVoteSIG := SIG(userId, message)
-
-					for p, c := range peerClients {
-						go func(c pb.AlgorandClient, p string, certVoteSIG *pb.SIGRet, round int64) {
-							log.Printf("Sent cert vote to peer %v", p)
-							ret, err := c.Vote(context.Background(), &pb.VoteArgs{Message: certVoteSIG, Round: round, Peer: userId})
-							voteResponseChan <- VoteResponse{ret: ret, err: err, peer: p}
-						}(c, p, certVoteSIG, state.round)
-					}
 
-					// check if our own vote helped us reach requiredVotes
-					haltValue := checkHaltingCondition(&state.periodState, requiredVotes)
-					if haltValue != "" {
-						handleHalt(bcs, &state, state.periodState.valueToBlock[haltValue])
-					}
+			case agreement.Commit:
+				if a.Value == agreement.Empty {
+					a.Block = createEmptyBlock(bcs.blockchain, &state.upgrade)
 				}
-			} else if state.step == 4 {
-				log.Printf("STEP 4")
-				nextVoteV := runStep4(&state.periodState, &state.lastPeriodState, requiredVotes)
-				log.Printf("next vote is %v", nextVoteV)
-
-				// add my own vote for this value
-				state.periodState.nextVotes[nextVoteV]++
-
-				message := []string{nextVoteV, "next", strconv.FormatInt(state.period, 10)}
-				nextVoteSIG := SIG(userId, message)
-
-				for p, c := range peerClients {
-					go func(c pb.AlgorandClient, p string, nextVoteSIG *pb.SIGRet, round int64) {
-						log.Printf("Sent next vote to peer %v", p)
-						ret, err := c.Vote(context.Background(), &pb.VoteArgs{Message: nextVoteSIG, Round: round, Peer: userId})
-						voteResponseChan <- VoteResponse{ret: ret, err: err, peer: p}
-					}(c, p, nextVoteSIG, state.round)
+				metrics.TimeToAgreement.Observe(time.Since(roundStart).Seconds())
+				metrics.PeriodsToAgreement.Observe(float64(a.Period))
+				metrics.CommittedTransactions.Add(float64(len(a.Block.GetTx())))
+
+				if err := bcs.commit(a.Block, a.Certificate); err != nil {
+					logging.Ledger.WithFields(logging.Fields{"round": a.Round, "period": a.Period}).Errorf("Not committing block %.8v: %v", a.Value, err)
+					break
 				}
-			} else if state.step == 5 {
-				log.Printf("STEP 5")
-				nextVoteV := runStep5(&state.periodState, &state.lastPeriodState, requiredVotes)
-				log.Printf("next vote is %v", nextVoteV)
-
-				if nextVoteV != "" {
-					// add my own vote for this value
-					state.periodState.nextVotes[nextVoteV]++
-
-					message := []string{nextVoteV, "next", strconv.FormatInt(state.period, 10)}
-					nextVoteSIG := SIG(userId, message)
-
-					for p, c := range peerClients {
-						go func(c pb.AlgorandClient, p string, nextVoteSIG *pb.SIGRet, round int64) {
-							log.Printf("Sent next vote to peer %v", p)
-							ret, err := c.Vote(context.Background(), &pb.VoteArgs{Message: nextVoteSIG, Round: round, Peer: userId})
-							voteResponseChan <- VoteResponse{ret: ret, err: err, peer: p}
-						}(c, p, nextVoteSIG, state.round)
-					}
+				accounts := state.accounts.Clone()
+				if err := accounts.ApplyBlock(a.Block); err != nil {
+					// 2t+1 voters validated it, so should have we
+					logging.Ledger.WithField("round", a.Round).Errorf("Committed block doesn't apply to our accounts: %v", err)
+				} else {
+					state.accounts = accounts
+				}
+				logging.Ledger.WithFields(logging.Fields{"round": a.Round, "period": a.Period}).Infof("Committed block %.8v with %v transactions", a.Block.Hash, len(a.Block.GetTx()))
+				if logging.DebugEnabled(logging.Ledger) {
+					logging.Ledger.Debugf("Chain: %v", PrettyPrint(bcs.blockchain))
+				}
+				checkProtocol(bcs, &state, idToStake)
+				erasePastKeys()
 
-					// finish period
-					state.period ++
-					state.step = 1
-					state.lastPeriodState = state.periodState
-					state.periodState = initPeriodState(state.period)
-					state.periodState.startingValue = calculateHash(state.proposedBlock)
-					
-					// allow step1 to happen again
-					state.readyForNextRound = true
+			case agreement.CheckProposal:
+				// kept from an earlier round, the block before it is ours now
+				if a.Proposal.Round != service.Round() || checkProposal(a.Proposal) != nil {
+					break
+				}
+				more, _ := service.Handle(agreement.ProposalReceived{Proposal: a.Proposal})
+				execute(more)
+
+			case agreement.RequestSync:
+				requestBlockChains()
+
+			case agreement.FetchBlock:
+				logging.Agreement.WithFields(logging.Fields{"round": a.Round, "period": a.Period}).Infof("Fetching block %.8v from %v", a.Value, a.Voters)
+				fetchBlock(a)
+
+			case agreement.Equivocation:
+				e := a.Evidence
+				fields := logging.Fields{"round": e.Round, "period": e.Period, "step": e.Step, "peer": e.UserId}
+				if err := agreement.VerifyEvidence(e, state.accounts.VotingKey(e.UserId, e.Round)); err != nil {
+					logging.Ledger.WithFields(fields).Warnf("Dropping evidence against %v: %v", e.UserId, err)
+					break
 				}
+				logging.Ledger.WithFields(fields).Warnf("EVIDENCE: %v equivocated, signed both %#v and %#v", e.UserId, e.First.Message, e.Second.Message)
+				bcs.addEvidence(e)
+				metrics.Equivocations.Inc()
 			}
+		}
+	}
 
-			// Handle resetting the agreementTimer
-			// we want a shorter timout for continously checking step5 again and again
-			if state.step == 5 {
-				restartTimer(agreementTimer, 2000)
-			} else {
-				restartTimer(agreementTimer, 10000)
+	// adoptChain switches to a chain from peer if it checks out, and has
+	// agreement go on from the round after it
+	adoptChain := func(peer string, blockchain []*pb.Block, certificates []*pb.Certificate) bool {
+		reject := func(result string, err error) bool {
+			logging.Ledger.WithField("peer", peer).Warnf("Rejecting Blockchain: %v", err)
+			metrics.Syncs.WithLabelValues(result).Inc()
+			return false
+		}
+		if err := verifyProtocol(blockchain, idToStake); err != nil {
+			return reject("wrong protocol", err)
+		}
+		if err := bcs.keepsFinal(blockchain); err != nil {
+			return reject("replaces final block", err)
+		}
+		accounts, err := verifyChain(blockchain, bcs.blockchain[0], idToStake, vrfKeys)
+		if err != nil {
+			return reject("invalid block", err)
+		}
+
+		bcs.adopt(blockchain, checkCertificates(blockchain, certificates, userIds, requiredVotes, accounts))
+		logging.Ledger.WithField("peer", peer).Infof("Verified new Blockchain of %v blocks, final up to round %v", len(blockchain), bcs.finalRound())
+		state.accounts = accounts
+		metrics.Syncs.WithLabelValues("ok").Inc()
+		metrics.SyncLag.Set(0)
+		// before agreement moves on, the proposals it kept are checked under the new round's protocol
+		checkProtocol(bcs, &state, idToStake)
+
+		// Prepare to reenter into Agreement
+		actions, _ := service.Handle(agreement.Synced{Round: int64(len(bcs.blockchain)), Seed: bcs.blockchain[len(bcs.blockchain)-1].Seed})
+		execute(actions)
+		erasePastKeys()
+		return true
+	}
+
+	// Stop where we are, the handlers already refuse new requests
+	shutdown := func() error {
+		service.Stop()
+		logging.Agreement.WithFields(logging.Fields{"round": service.Round(), "period": service.Period(), "step": service.Step()}).Infof("Stopped agreement, waiting for messages in flight")
+
+		var err error
+		drained := peerManager.Drained()
+		deadline := time.After(cfg.ShutdownTimeout)
+	drain:
+		for {
+			select {
+			case <-drained:
+				break drain
+			case <-network.proposeBlockResponseChan:
+			case <-network.proposePriorityResponseChan:
+			case <-network.voteResponseChan:
+			case <-appendTransactionResponseChan:
+			case <-requestBlockChainResponseChan:
+			case <-getProposalResponseChan:
+			case <-deadline:
+				err = errShutdownTimeout
+				break drain
 			}
+		}
+
+		peerManager.Close()
+		return err
+	}
+
+	// Votes, proposals and timers move agreement forward, they are handled
+	// before anything a client or operator asked for
+	handleTimeout := func(timeout agreement.Timeout) {
+		if state.halted {
+			return
+		}
+		actions, _ := service.Handle(timeout)
+		execute(actions)
+	}
+	handleProposal := func(pbc ProposeBlockInput) {
+		if state.halted {
+			pbc.response <- pb.ProposeBlockRet{Success: false}
+			return
+		}
+
+		// one for a later round we can't check yet, agreement keeps it and
+		// hands it back with CheckProposal once we get there
+		if pbc.arg.Round == service.Round() && checkProposal(pbc.arg) != nil {
+			pbc.response <- pb.ProposeBlockRet{Success: true}
+			return
+		}
+
+		actions, err := service.Handle(agreement.ProposalReceived{Proposal: pbc.arg})
+		execute(actions)
+		metrics.ProposalsReceived.WithLabelValues(resultLabel(err)).Inc()
+		if err == agreement.ErrFutureRound {
+			metrics.SyncLag.Set(float64(pbc.arg.Round - service.Round()))
+		}
+
+		// only a proposal from a round we haven't reached yet is worth sending again
+		pbc.response <- pb.ProposeBlockRet{Success: err != agreement.ErrFutureRound}
+	}
+	handlePriority := func(ppc ProposePriorityInput) {
+		if state.halted {
+			ppc.response <- pb.ProposePriorityRet{Success: false}
+			return
+		}
+
+		actions, err := service.Handle(agreement.PriorityReceived{Priority: ppc.arg})
+		execute(actions)
+		metrics.PrioritiesReceived.WithLabelValues(resultLabel(err)).Inc()
+		ppc.response <- pb.ProposePriorityRet{Success: err == nil}
+	}
+	handleVote := func(vc VoteInput) {
+		if state.halted {
+			vc.response <- pb.VoteRet{Success: false}
+			return
+		}
+
+		voteType := "unknown"
+		if message := vc.arg.GetMessage().GetMessage(); len(message) > 1 && (message[1] == "soft" || message[1] == "cert" || message[1] == "next") {
+			voteType = message[1]
+		}
+
+		// only the voter's participation key for the round may sign its vote.
+		// Agreement never counts votes beyond future_rounds, they only tell it
+		// we are behind, and our ledger can't know their keys yet
+		voter := vc.arg.GetMessage().GetUserId()
+		if vc.arg.Round-service.Round() <= cfg.Queues.FutureRounds {
+			err := agreement.VerifyVoteSignature(vc.arg.GetMessage(), vc.arg.Round, state.accounts.VotingKey(voter, vc.arg.Round))
+			if err != nil {
+				logging.Network.WithFields(logging.Fields{"peer": vc.arg.Peer, "round": vc.arg.Round}).Warnf("DENIED vote by %v: %v", voter, err)
+				metrics.VotesReceived.WithLabelValues(voteType, resultLabel(err)).Inc()
+				vc.response <- pb.VoteRet{Success: false}
+				return
+			}
+		}
+
+		actions, err := service.Handle(agreement.VoteReceived{Vote: vc.arg})
+		execute(actions)
+
+		metrics.VotesReceived.WithLabelValues(voteType, resultLabel(err)).Inc()
+		if err == agreement.ErrFutureRound {
+			metrics.SyncLag.Set(float64(vc.arg.Round - service.Round()))
+		}
+		vc.response <- pb.VoteRet{Success: err == nil}
+	}
+
+	execute(service.Start())
+	observe()
+
+	// Run until told to stop, handling inputs from various channels
+	for {
+		// break only leaves the select, every input ends up here
+		observe()
+
+		select {
+		case <-ctx.Done():
+			return shutdown()
+		case timeout := <-service.Timeouts():
+			handleTimeout(timeout)
+			continue
+		case pbc := <-algorand.ProposeBlockChan:
+			handleProposal(pbc)
+			continue
+		case ppc := <-algorand.ProposePriorityChan:
+			handlePriority(ppc)
+			continue
+		case vc := <-algorand.VoteChan:
+			handleVote(vc)
+			continue
+		default:
+			// nothing consensus related waiting, take whatever comes first
+		}
+
+		select{
+		case <-ctx.Done():
+			return shutdown()
+
+		case timeout := <-service.Timeouts():
+			handleTimeout(timeout)
 
 		case op := <-bcs.C:
 			// Received a command from client
 			// TODO: Add Transaction to our local block, broadcast to every user
-			log.Printf("Transaction request: %#v, Round: %v", op.command.Arg, state.round)
+			logging.API.WithFields(logging.Fields{"round": service.Round(), "operation": op.command.Operation}).Debugf("Client request")
 
 			if op.command.Operation == pb.Op_SEND {
+				if err := checkTransaction(op.command.GetTx()); err != nil {
+					logging.API.WithFields(logging.Fields{"round": service.Round(), "txid": txid(op.command.GetTx())}).Warnf("DENIED transaction: %v", err)
+					bcs.HandleCommand(op)
+					break
+				}
 				state.tempBlock.Tx = append(state.tempBlock.Tx, op.command.GetTx())
+				logging.API.WithFields(logging.Fields{"round": service.Round(), "txid": txid(op.command.GetTx())}).Infof("Transaction received")
 
 				// TODO - broadcast, and figure out when to reponse to client?
 
 				// broadcast
-				for p, c := range peerClients {
+				for _, p := range peerManager.Peers() {
+					p := p
 					transaction := op.command.GetTx()
 
-					go func(c pb.AlgorandClient, p string, transaction *pb.Transaction) {
-						log.Printf("Sent transaction to peer %v", p)
-						ret, err := c.AppendTransaction(context.Background(), &pb.AppendTransactionArgs{Peer: p, Tx: transaction})
-						appendTransactionResponseChan <- AppendTransactionResponse{ret: ret, err: err, peer: p}
-					}(c, p, transaction)
+					peerManager.Send(p, func(ctx context.Context, c pb.AlgorandClient) (interface{}, error) {
+						logging.Network.WithFields(logging.Fields{"peer": p, "txid": txid(transaction)}).Debugf("Sent transaction")
+						return c.AppendTransaction(ctx, &pb.AppendTransactionArgs{Peer: p, Tx: transaction})
+					}, func(ret interface{}, err error) {
+						r, _ := ret.(*pb.AppendTransactionRet)
+						appendTransactionResponseChan <- AppendTransactionResponse{ret: r, err: err, peer: p}
+					})
 				}
 
 			}
 
 			bcs.HandleCommand(op)
 
-			// Check if add new Transaction, or simply get the curent Blockchain
-			// if op.command.Operation == pb.Op_GET {
-			// 	log.Printf("Request to view the blockchain")
-			// 	bcs.HandleCommand(op)
-			// } else {
-			// 	log.Printf("Request to add new Block")
-
-			// 	// for now, we simply append to our blockchain and broadcast the new blockchain to all known peers
-			// 	bcs.HandleCommand(op)
-			// 	for p, c := range peerClients {
-			// 		go func(c pb.AlgorandClient, blockchain []*pb.Block, p string) {
-			// 			ret, err := c.AppendBlock(context.Background(), &pb.AppendBlockArgs{Blockchain: blockchain, Peer: id})
-			// 			appendBlockResponseChan <- AppendResponse{ret: ret, err: err, peer: p}
-			// 		}(c, bcs.blockchain, p)
-			// 	}
-			// 	log.Printf("Period: %v, Blockchain: %#v", state.period, bcs.blockchain)
-			// }
-
 		case ab := <-algorand.AppendBlockChan:
 			// we got an AppendBlock request
-			log.Printf("AppendBlock from %v", ab.arg.Peer)
+			logging.Network.WithField("peer", ab.arg.Peer).Infof("AppendBlock")
 
 			// for now, just check if blockchain is longer than ours
 			// if yes, overwrite ours and return true
 			// if no, return false
 			if len(ab.arg.Blockchain) > len(bcs.blockchain) {
-				bcs.blockchain = ab.arg.Blockchain
-				ab.response <- pb.AppendBlockRet{Success: true}
+				ab.response <- pb.AppendBlockRet{Success: adoptChain(ab.arg.Peer, ab.arg.Blockchain, ab.arg.Certificates)}
 			} else {
 				ab.response <- pb.AppendBlockRet{Success: false}
 			}
 
 		case abr := <-appendBlockResponseChan:
 			// we got a response to our AppendBlock request
-			log.Printf("AppendBlockResponse: %#v", abr)
+			logging.Network.WithField("peer", abr.peer).Debugf("AppendBlockResponse: %#v", abr)
 
 		case at := <-algorand.AppendTransactionChan:
 			// we got an AppendTransaction request
-			log.Printf("AppendTransaction from %v", at.arg.Peer)
+			logging.Network.WithFields(logging.Fields{"peer": at.arg.Peer, "txid": txid(at.arg.Tx)}).Debugf("AppendTransaction")
 
+			if err := checkTransaction(at.arg.Tx); err != nil {
+				logging.Network.WithFields(logging.Fields{"peer": at.arg.Peer, "txid": txid(at.arg.Tx)}).Warnf("DENIED transaction: %v", err)
+				at.response <- pb.AppendTransactionRet{Success: false}
+				break
+			}
 			state.tempBlock.Tx = append(state.tempBlock.Tx, at.arg.Tx)
-			log.Printf("Temp block: %#v", len(state.tempBlock.Tx))
+			logging.Ledger.Debugf("Mempool: %v transactions", len(state.tempBlock.Tx))
 
 			at.response <- pb.AppendTransactionRet{Success: true}
 
 		case atr := <- appendTransactionResponseChan:
 			// we got a response to our AppendTransaction request
-			log.Printf("AppendTransactionResponse: %#v", atr)
+			logging.Network.WithField("peer", atr.peer).Debugf("AppendTransactionResponse: %#v", atr)
 
 		case pbc := <-algorand.ProposeBlockChan:
-			if pbc.arg.Round > state.round {
-				log.Printf("Round is behind peers, request blockchains")
-				for p, c := range peerClients {
-					go func(c pb.AlgorandClient, p string) {
-						ret, err := c.RequestBlockChain(context.Background(), &pb.RequestBlockChainArgs{Peer: p})
-						requestBlockChainResponseChan <- RequestBlockChainResponse{ret: ret, err: err, peer: p}
-					}(c, p)
-				}
-				pbc.response <- pb.ProposeBlockRet{Success: false}
+			handleProposal(pbc)
+
+		case pbr := <-network.proposeBlockResponseChan:
+			if pbr.err == errPeerDown {
+				// the peer is resting, it catches up through sync once it's back
 				break
 			}
-			
-			proposerId := pbc.arg.Credential.UserId
-			log.Printf("ProposeBlock from %v", proposerId)
+			if pbr.err != nil || !pbr.ret.Success {
+				// retry, backing off, while the proposal is still out for this round and period
+				if proposal := service.CurrentProposal(); proposal != nil && proposal == pbr.proposal {
+					network.RetryProposal(pbr.peer, proposal, pbr.attempts)
+				}
+			}
 
-			verified := verifySort(proposerId, candidates, state.round, k, state.period)
-			if verified {
-				log.Printf("VERIFIED that %v is on the committee for round %v", proposerId, state.round)
+		case ppc := <-algorand.ProposePriorityChan:
+			handlePriority(ppc)
 
-				proposerCredential := []string{pbc.arg.Credential.UserId, pbc.arg.Credential.SignedMessage}
+		case ppr := <-network.proposePriorityResponseChan:
+			logging.Network.WithField("peer", ppr.peer).Debugf("ProposePriorityResponse")
 
-				proposerHash := signMessage(proposerCredential)
-				// add verified block to list of blocks I've seen this period
-				state.periodState.proposedValues[proposerHash] = pbc.arg.Value
-				state.periodState.valueToBlock[pbc.arg.Value] = pbc.arg.Block
-				pbc.response <- pb.ProposeBlockRet{Success: true}
-			} else {
-				// rejected proposed block
-				log.Printf("DENIED that %v is on the committee for round %v", proposerId, state.round)
-				pbc.response <- pb.ProposeBlockRet{Success: true}
+		case vc := <-algorand.VoteChan:
+			handleVote(vc)
+
+		case vr := <-network.voteResponseChan:
+			logging.Network.WithField("peer", vr.peer).Debugf("VoteResponse")
+
+		case sc := <-admin.GetStatusChan:
+			last := bcs.blockchain[len(bcs.blockchain)-1]
+			sc.response <- pb.Status{
+				UserId: userId,
+				Round: service.Round(),
+				Period: service.Period(),
+				Step: service.Step(),
+				ChainLength: int64(len(bcs.blockchain)),
+				LastBlockHash: last.GetHash(),
+				Protocol: state.upgrade.ProtocolAt(service.Round()),
+				Halted: state.halted,
+				MempoolSize: int64(len(state.tempBlock.Tx)),
+				RequiredVotes: requiredVotes,
+				FinalRound: bcs.finalRound(),
+			}
+
+		case pic := <-admin.GetPeerInfoChan:
+			info := pb.PeerInfoList{}
+			for _, peer := range peers {
+				ps := peerManager.State(peer)
+				peerId := strings.Split(peer, ":")[1]
+				pi := &pb.PeerInfo{
+					Address: peer,
+					UserId: peerId,
+					Stake: int64(idToStake[peerId]),
+					State: ps.Conn.GetState().String(),
+					Connected: ps.Connected,
+					LastError: ps.LastError,
+					Circuit: ps.Circuit,
+					Queued: int64(ps.Queued),
+					InFlight: int64(ps.InFlight),
+				}
+				if !ps.LastSeen.IsZero() {
+					pi.LastSeen = ps.LastSeen.Unix()
+				}
+				info.Peers = append(info.Peers, pi)
 			}
+			pic.response <- info
+
+		case psc := <-admin.GetPeriodStateChan:
+			psc.response <- periodStateInfo(service.Snapshot(), requiredVotes)
 
-		case pbr := <-proposeBlockResponseChan:
-			if pbr.err != nil || !pbr.ret.Success{
-				// retry
-				p := pbr.peer
-				c := peerClients[p]
+		case mc := <-admin.GetMempoolChan:
+			mc.response <- pb.Mempool{Tx: state.tempBlock.Tx}
 
-				// calculate your signature for this round and period
-				sigParams := []string{strconv.FormatInt(state.round, 10), strconv.FormatInt(state.period, 10)}
-				sig := SIG(userId, sigParams)
+		case bcc := <-algorand.RequestBlockChainChan:
+			logging.Network.WithField("peer", bcc.arg.Peer).Debugf("RequestBlockChain")
 
-				// get your proposal back out of the map
-				proposerCredential := []string{userId, sig.SignedMessage}
-				proposerHash := signMessage(proposerCredential)
+			bcc.response <- pb.RequestBlockChainRet{Peer: userId, Blockchain: bcs.blockchain, Certificates: bcs.certified()}
 
-				// check if you proposed a block for this round and period
-				if v, ok := state.periodState.proposedValues[proposerHash]; ok {
-					b := state.periodState.valueToBlock[v]
+		case gpc := <-algorand.GetProposalChan:
+			logging.Network.WithFields(logging.Fields{"peer": gpc.arg.Peer, "round": gpc.arg.Round}).Debugf("GetProposal %.8v", gpc.arg.Value)
 
-					go func(c pb.AlgorandClient, p string, b *pb.Block, v string, sig *pb.SIGRet, round int64) {
-						ret, err := c.ProposeBlock(context.Background(), &pb.ProposeBlockArgs{Block: b, Credential: sig, Value: v, Round: round, Peer: userId})
-						proposeBlockResponseChan <- ProposeBlockResponse{ret: ret, err: err, peer: p}
-					}(c, p, b, v, sig, state.round)
+			var block *pb.Block
+			if gpc.arg.Round < int64(len(bcs.blockchain)) {
+				// already committed, the chain holds the block of every past round
+				if b := bcs.blockchain[gpc.arg.Round]; calculateHash(b) == gpc.arg.Value {
+					block = b
 				}
+			} else if gpc.arg.Round == service.Round() {
+				block = service.Block(gpc.arg.Value)
 			}
+			gpc.response <- pb.GetProposalRet{Found: block != nil, Block: block}
 
-		case vc := <-algorand.VoteChan:
-			if vc.arg.Round > state.round {
-				log.Printf("Round is behind peers, request blockchains")
-				for p, c := range peerClients {
-					go func(c pb.AlgorandClient, p string) {
-						ret, err := c.RequestBlockChain(context.Background(), &pb.RequestBlockChainArgs{Peer: userId})
-						requestBlockChainResponseChan <- RequestBlockChainResponse{ret: ret, err: err, peer: p}
-					}(c, p)
-				}
-				vc.response <- pb.VoteRet{Success: false}
+		case gpr := <-getProposalResponseChan:
+			if gpr.err != nil || !gpr.ret.Found {
+				logging.Network.WithField("peer", gpr.peer).Debugf("No block %.8v for round %v", gpr.arg.Value, gpr.arg.Round)
 				break
 			}
-
-			voterId := vc.arg.Message.UserId
-			voteValue := vc.arg.Message.Message[0]
-			voteType := vc.arg.Message.Message[1]
-			votePeriod, _ := strconv.ParseInt(vc.arg.Message.Message[2], 10, 64)
-			log.Printf("Received %vVote from: %v", voteType, voterId)
-
-			if voteType == "soft" {
-				_, hasVoted := state.periodState.haveSoftVoted[voterId]
-				if !hasVoted {
-					if votePeriod == state.periodState.period {
-						state.periodState.softVotes[voteValue]++
-					} else if votePeriod == state.lastPeriodState.period {
-						state.lastPeriodState.softVotes[voteValue]++
-					}
-					state.periodState.haveSoftVoted[voterId] = true
-					vc.response <- pb.VoteRet{Success: true}
-				} else {
-					log.Printf("Ignoring %vVote from %v: already %vVoted this period", voteType, voterId, voteType)
-					vc.response <- pb.VoteRet{Success: false}
-				}
-			} else if voteType == "cert" {
-				_, hasVoted := state.periodState.haveCertVoted[voterId]
-				if !hasVoted {
-					if votePeriod == state.periodState.period {
-						state.periodState.certVotes[voteValue]++
-					} else if votePeriod == state.lastPeriodState.period {
-						state.lastPeriodState.certVotes[voteValue]++
-					}
-					state.periodState.haveCertVoted[voterId] = true
-					vc.response <- pb.VoteRet{Success: true}
-
-					// we need to check for halting condition anytime we see a new cert vote
-					haltValue := checkHaltingCondition(&state.periodState, requiredVotes)
-					if haltValue != "" {
-						handleHalt(bcs, &state, state.periodState.valueToBlock[haltValue])
-					}
-				} else {
-					log.Printf("Ignoring %vVote from %v: already %vVoted this period", voteType, voterId, voteType)
-					vc.response <- pb.VoteRet{Success: false}
-				}
-			} else if voteType == "next" {
-				_, hasVoted := state.periodState.haveNextVoted[voterId]
-				if !hasVoted {
-					if votePeriod == state.periodState.period {
-						state.periodState.nextVotes[voteValue]++
-					} else if votePeriod == state.lastPeriodState.period {
-						state.lastPeriodState.nextVotes[voteValue]++
-					}
-					state.periodState.haveNextVoted[voterId] = true
-					vc.response <- pb.VoteRet{Success: true}
-				} else {
-					log.Printf("Ignoring %vVote from %v: already %vVoted this period", voteType, voterId, voteType)
-					vc.response <- pb.VoteRet{Success: false}
-				}
-			} else {
-				log.Printf("Strange to arrive here")
-				vc.response <- pb.VoteRet{Success: false}
+			// only a body that hashes to the certified value may be committed
+			block := gpr.ret.Block
+			if block.GetId() != gpr.arg.Round || calculateHash(block) != gpr.arg.Value {
+				logging.Network.WithFields(logging.Fields{"peer": gpr.peer, "round": gpr.arg.Round}).Warnf("DENIED block that doesn't match %.8v", gpr.arg.Value)
+				break
 			}
-		case vr := <-voteResponseChan:
-			log.Printf("VoteResponse from: %v", vr.peer)
-
-		case bcc := <-algorand.RequestBlockChainChan:
-			log.Printf("RequestBlockChain from: %v", bcc.arg.Peer)
-
-			bcc.response <- pb.RequestBlockChainRet{Peer: userId, Blockchain: bcs.blockchain}
+			actions, _ := service.Handle(agreement.BlockFetched{Round: gpr.arg.Round, Value: gpr.arg.Value, Block: block})
+			execute(actions)
 
 		case bcr := <-requestBlockChainResponseChan:
-			log.Printf("Received Blockchain from %v", bcr.peer)
+			logging.Network.WithField("peer", bcr.peer).Debugf("Received Blockchain")
 
 			if bcr.err == nil {
 				candidateBlockchain := bcr.ret.Blockchain
+				// the blocks we share hold the same registrations, our ledger checks their certificates
+				certificates := checkCertificates(candidateBlockchain, bcr.ret.Certificates, userIds, requiredVotes, state.accounts)
 
-				if len(candidateBlockchain) > len(bcs.blockchain) {
-					log.Printf("CandidateChain: %v", PrettyPrint(candidateBlockchain))
+				if len(candidateBlockchain) <= len(bcs.blockchain) && !bcs.overruledBy(candidateBlockchain, certificates) {
+					// nothing to switch to, but it may prove blocks we only hold tentatively
+					if n := bcs.certify(candidateBlockchain, certificates); n > 0 {
+						logging.Ledger.WithField("peer", bcr.ret.Peer).Infof("%v tentative blocks are final now, up to round %v", n, bcs.finalRound())
+					}
+				} else {
+					if logging.DebugEnabled(logging.Ledger) {
+						logging.Ledger.Debugf("CandidateChain: %v", PrettyPrint(candidateBlockchain))
+					}
 
 					// verify every block in this blockchain
-					verified := true
-
-					if verified {
-						log.Printf("Verified new Blockchain from peer: %v", bcr.ret.Peer)
-						bcs.blockchain = candidateBlockchain
-
-						// Prepare to reenter into Agreement
-						state.readyForNextRound = true
-						state.round = int64(len(bcs.blockchain))
+					adoptChain(bcr.ret.Peer, candidateBlockchain, bcr.ret.Certificates)
 
-						state.lastPeriodState = PeriodState{}
-						state.period = int64(1)
-						state.step = int64(1)
-						state.periodState = initPeriodState(state.period)
-						state.periodState.startingValue = "_|_"
+					if logging.DebugEnabled(logging.Ledger) {
+						logging.Ledger.Debugf("NewChain: %v", PrettyPrint(bcs.blockchain))
 					}
-					
-					log.Printf("NewChain: %v", PrettyPrint(bcs.blockchain))
 				}
 			}
 		}
 	}
-	log.Printf("Strange to arrive here")
 }
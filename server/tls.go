@@ -0,0 +1,72 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func (t TLSConfig) enabled() bool {
+	return t.CertFile != ""
+}
+
+func (cfg *Config) certificate() (tls.Certificate, error) {
+	return tls.LoadX509KeyPair(cfg.Path(cfg.TLS.CertFile), cfg.Path(cfg.TLS.KeyFile))
+}
+
+func (cfg *Config) caPool() (*x509.CertPool, error) {
+	if cfg.TLS.CAFile == "" {
+		return nil, nil
+	}
+	pem, err := ioutil.ReadFile(cfg.Path(cfg.TLS.CAFile))
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates in %v", cfg.TLS.CAFile)
+	}
+	return pool, nil
+}
+
+// serverOptions are the options every GRPC server of the node is created with.
+// verifyPeers makes callers present a certificate signed by the CA, for the
+// Algorand service, clients and operators only have to trust ours.
+func (cfg *Config) serverOptions(verifyPeers bool) ([]grpc.ServerOption, error) {
+	if !cfg.TLS.enabled() {
+		return nil, nil
+	}
+	cert, err := cfg.certificate()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if verifyPeers && cfg.TLS.CAFile != "" {
+		if tlsConfig.ClientCAs, err = cfg.caPool(); err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsConfig))}, nil
+}
+
+// peerDialOption is how the node connects to its peers.
+func (cfg *Config) peerDialOption() (grpc.DialOption, error) {
+	if !cfg.TLS.enabled() {
+		return grpc.WithInsecure(), nil
+	}
+	cert, err := cfg.certificate()
+	if err != nil {
+		return nil, err
+	}
+	pool, err := cfg.caPool()
+	if err != nil {
+		return nil, err
+	}
+	// nil RootCAs falls back to the system's
+	return grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: pool})), nil
+}
@@ -1,16 +1,19 @@
 package main
 
 import (
-	"log"
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"time"
 	"math/rand"
-	"strings"
 	"strconv"
 
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/ledger"
 	"github.com/nyu-distributed-systems-fa18/algorand/pb"
 )
 
@@ -18,21 +21,27 @@ func calculateHash(block *pb.Block) string {
 	var transactions bytes.Buffer
 	for _, tx := range block.Tx {
 		transactions.WriteString(tx.V)
+		if !ledger.IsRecord(tx) {
+			transactions.Write(ledger.SigningBytes(tx))
+			transactions.WriteString(tx.Signature)
+		}
+	}
+	record := string(rune(block.Id)) + block.Timestamp + transactions.String() + block.PrevHash
+	// the header fields are prefixed with their length, so no two headers
+	// share an encoding
+	for _, field := range []string{block.Protocol, block.UpgradeVote, block.Proposer, block.Seed, block.SeedProof} {
+		record += strconv.Itoa(len(field)) + ":" + field
 	}
-	record := string(block.Id) + block.Timestamp + transactions.String() + block.PrevHash
 	h := sha256.New()
 	h.Write([]byte(record))
 	hashed := h.Sum(nil)
 	return hex.EncodeToString(hashed)
 }
 
-func signMessage(message []string) string {
-	s := strings.Join(message[:],"")
-
-	h := sha256.New()
-	h.Write([]byte(s))
-	hashed := h.Sum(nil)
-	return hex.EncodeToString(hashed)
+// txid identifies a transaction in logs.
+func txid(tx *pb.Transaction) string {
+	h := sha256.Sum256([]byte(tx.GetV()))
+	return hex.EncodeToString(h[:8])
 }
 
 func generateBlock(oldBlock *pb.Block, tx *pb.Transaction) *pb.Block {
@@ -50,19 +59,21 @@ func generateBlock(oldBlock *pb.Block, tx *pb.Transaction) *pb.Block {
 	return newBlock
 }
 
-func prepareBlock(block *pb.Block, blockchain []*pb.Block) *pb.Block {
+func prepareBlock(block *pb.Block, blockchain []*pb.Block, proposer string, vrfKey ed25519.PrivateKey, upgrade *UpgradeState, accounts *ledger.Ledger) *pb.Block {
 	newBlock := new(pb.Block)
 	lastBlock := blockchain[len(blockchain)-1]
 
 	newBlock.Id = lastBlock.Id + 1
 	newBlock.PrevHash = lastBlock.Hash
 
-	//copy Transactions over to new Block
-	newBlock.Tx = []*pb.Transaction{}
+	// header fields used for protocol upgrades
+	newBlock.Protocol = upgrade.ProtocolAt(newBlock.Id)
+	newBlock.UpgradeVote = upgrade.Vote()
+	newBlock.Proposer = proposer
+	params := supportedProtocols[newBlock.Protocol]
 
-	for _, tx := range block.Tx {
-		newBlock.Tx = append(newBlock.Tx, tx)
-	}
+	// nobody, us included, can pick the seed later rounds draw their proposers from
+	newBlock.Seed, newBlock.SeedProof = agreement.BlockSeed(vrfKey, lastBlock.Seed, newBlock.Id)
 
 	blockMap := make(map[string]bool)
 	// loop through lastBlock's transactions and remove any that appear in newBlock
@@ -70,18 +81,32 @@ func prepareBlock(block *pb.Block, blockchain []*pb.Block) *pb.Block {
 		blockMap[tx.V] = true
 	}
 
-	tempTx := []*pb.Transaction{}
-	for _, tx := range newBlock.Tx {
-		if _, ok := blockMap[tx.V]; ok {
+	// copy over the transactions that still apply, as many as fit
+	newBlock.Tx = []*pb.Transaction{}
+	after := accounts.Clone()
+	size := 0
+	for _, tx := range block.Tx {
+		if _, ok := blockMap[tx.V]; ok && ledger.IsRecord(tx) {
 			// ignore transactions we already appended from new block
-		} else {
-			tempTx = append(tempTx, tx)
+			continue
+		}
+		txSize := proto.Size(tx)
+		if txSize > params.MaxTxBytes || size+txSize > params.MaxBlockBytes {
+			continue
 		}
+		if err := after.Apply(tx); err != nil {
+			continue
+		}
+		newBlock.Tx = append(newBlock.Tx, tx)
+		size += txSize
 	}
 
-	newBlock.Tx = tempTx
-
-	newBlock.Timestamp = time.Now().String()
+	// never before the last block, even if our clock is behind its proposer's
+	now := time.Now().UTC()
+	if last, err := blockTimestamp(lastBlock); err == nil && now.Before(last) {
+		now = last
+	}
+	newBlock.Timestamp = now.Format(timestampLayout)
 	newBlock.Hash = calculateHash(newBlock)
 
 	return newBlock
@@ -129,80 +154,6 @@ func generateCandidatesByStake(userIds []string, idToStake map[string]int) []str
 	return candidates
 }
 
-func committeeSelection(arr []string, seed int64, k int64) []string {
-	// set up array to return as selected elements
-	// and random number generator
-	selection := make([]string, k)
-	s := rand.NewSource(seed)
-	rand := rand.New(s)
-
-	i := int64(0)
-	for i = 0; i < k; i++ {
-		random_idx := rand.Intn(len(arr))
-		selection[i] = arr[random_idx]
-	}
-
-	return selection
-}
-
-func sortition(privateKey int64, round int64, role string, userId string, candidates []string, k int64) (string, string, int64) {
-	// sortition selects k committee members out of all users
-	committee := committeeSelection(candidates, round, k)
-
-	// print committee to verify it is the same accross all servers
-	log.Printf("Round %v Committee: %#v", round, committee)
-
-	// Add up how many times we were selected
-	votes := int64(0)
-	for _, member := range committee {
-		if member == userId {
-			votes++
-		}
-	}
-
-	return "hash", "proof", votes
-}
-
-func verifySort(userId string, candidates []string, round int64, k int64, period int64) bool {
-	sigParams := []string{strconv.FormatInt(round, 10), strconv.FormatInt(period, 10)}
-
-	proposerCredential := SIG(userId, sigParams)
-
-	committee := committeeSelection(candidates, round, k)
-
-	// loop through committee and verify userId is in there
-	for _, member := range committee {
-		memberCredential := SIG(member, sigParams)
-
-		if memberCredential.SignedMessage == proposerCredential.SignedMessage {
-			return true
-		}
-	}
-	return false
-}
-
-func SIG(i string, message []string) *pb.SIGRet {
-	signedMessage := signMessage(message)
-	return &pb.SIGRet{UserId: i, Message: message, SignedMessage: signedMessage}
-}
-
-func selectLeader(proposedValues map[string]string) string {
-	minCredential := ""
-	value := ""
-
-	for k, v := range proposedValues {
-		if minCredential == "" {
-			minCredential = k
-			value = v
-		} else if k < minCredential {
-			minCredential = k
-			value = v
-		}
-	}
-
-	return value
-}
-
 func PrettyPrint(v interface{}) string {
 	b, err := json.MarshalIndent(v, "", "  ")
 	if err == nil {
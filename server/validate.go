@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/ledger"
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+// Every genesis block carries this timestamp so that nodes given the same
+// allocations start from the same block.
+const genesisTimestamp = "2018-12-01T00:00:00Z"
+
+// Block timestamps are written in UTC in this layout.
+const timestampLayout = time.RFC3339Nano
+
+// createGenesisBlock allocates accounts their funds, in address order, then
+// registers every user's first participation key from participants.
+func createGenesisBlock(accounts map[string]int64, participants []*pb.Transaction) *pb.Block {
+	genesisBlock := new(pb.Block)
+	genesisBlock.Id = 0
+	genesisBlock.Timestamp = genesisTimestamp
+	genesisBlock.PrevHash = ""
+	genesisBlock.Protocol = genesisProtocol
+
+	addresses := make([]string, 0, len(accounts))
+	for address := range accounts {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	for _, address := range addresses {
+		genesisBlock.Tx = append(genesisBlock.Tx, &pb.Transaction{To: address, Amount: accounts[address]})
+	}
+	genesisBlock.Tx = append(genesisBlock.Tx, participants...)
+
+	genesisBlock.Hash = calculateHash(genesisBlock)
+	return genesisBlock
+}
+
+// createEmptyBlock returns the block of a round that agreement settled on
+// agreement.Empty. Every node builds the same one from the last block of
+// blockchain: it carries no transactions or proposer, keeps the last block's
+// timestamp and derives its seed from the last block's.
+func createEmptyBlock(blockchain []*pb.Block, upgrade *UpgradeState) *pb.Block {
+	last := blockchain[len(blockchain)-1]
+	block := &pb.Block{
+		Id:        last.Id + 1,
+		Timestamp: last.Timestamp,
+		PrevHash:  last.Hash,
+		Seed:      agreement.EmptySeed(last.Seed, last.Id+1),
+		Protocol:  upgrade.ProtocolAt(last.Id + 1),
+	}
+	block.Hash = calculateHash(block)
+	return block
+}
+
+// blockTimestamp returns the time a block was built, for the genesis block
+// the time the chain starts.
+func blockTimestamp(block *pb.Block) (time.Time, error) {
+	t, err := time.Parse(timestampLayout, block.GetTimestamp())
+	if err != nil {
+		return t, fmt.Errorf("block %v has no valid timestamp: %v", block.GetId(), err)
+	}
+	return t, nil
+}
+
+// validateBlock checks that block can follow prev on a chain whose accounts
+// are in l: it links to prev, hashes to its Hash, stays within the size limits
+// of params, isn't older than prev and only holds transactions that apply in
+// order. Its seed has to follow from prev's, proven with the VRF key in
+// vrfKeys of its proposer. On success it returns the accounts after block, l
+// is left alone.
+func validateBlock(block *pb.Block, prev *pb.Block, l *ledger.Ledger, vrfKeys map[string]ed25519.PublicKey, params ConsensusParams) (*ledger.Ledger, error) {
+	if block.Id != prev.Id+1 {
+		return nil, fmt.Errorf("block %v follows block %v", block.Id, prev.Id)
+	}
+	if block.PrevHash != prev.Hash {
+		return nil, fmt.Errorf("block %v links to %.8v, not block %v %.8v", block.Id, block.PrevHash, prev.Id, prev.Hash)
+	}
+	if hash := calculateHash(block); block.Hash != hash {
+		return nil, fmt.Errorf("block %v claims hash %.8v, hashes to %.8v", block.Id, block.Hash, hash)
+	}
+	if block.Proposer == "" && (len(block.Tx) > 0 || block.UpgradeVote != "" || block.Timestamp != prev.Timestamp || block.Seed != agreement.EmptySeed(prev.Seed, block.Id) || block.SeedProof != "") {
+		// only an empty block has no proposer, and there is just one for every round
+		return nil, fmt.Errorf("block %v has no proposer but isn't the round's empty block", block.Id)
+	}
+	if block.Proposer != "" {
+		if err := agreement.VerifyBlockSeed(block, prev.Seed, vrfKeys[block.Proposer]); err != nil {
+			return nil, fmt.Errorf("block %v %v", block.Id, err)
+		}
+	}
+
+	size := 0
+	for i, tx := range block.Tx {
+		txSize := proto.Size(tx)
+		if txSize > params.MaxTxBytes {
+			return nil, fmt.Errorf("block %v transaction %v is %v bytes, at most %v allowed", block.Id, i, txSize, params.MaxTxBytes)
+		}
+		size += txSize
+	}
+	if size > params.MaxBlockBytes {
+		return nil, fmt.Errorf("block %v holds %v bytes of transactions, at most %v allowed", block.Id, size, params.MaxBlockBytes)
+	}
+
+	built, err := blockTimestamp(block)
+	if err != nil {
+		return nil, err
+	}
+	prevBuilt, err := blockTimestamp(prev)
+	if err != nil {
+		return nil, err
+	}
+	if built.Before(prevBuilt) {
+		return nil, fmt.Errorf("block %v was built at %v, before block %v at %v", block.Id, built, prev.Id, prevBuilt)
+	}
+
+	after := l.Clone()
+	if err := after.ApplyBlock(block); err != nil {
+		return nil, fmt.Errorf("block %v %v", block.Id, err)
+	}
+	return after, nil
+}
+
+// validateProposal checks a proposal for the round after the last block of
+// blockchain, whose accounts are in l, before it may become a candidate.
+func validateProposal(arg *pb.ProposeBlockArgs, blockchain []*pb.Block, l *ledger.Ledger, vrfKeys map[string]ed25519.PublicKey, upgrade *UpgradeState, now time.Time) error {
+	block := arg.Block
+	round := int64(len(blockchain))
+	if block == nil {
+		return fmt.Errorf("proposal carries no block")
+	}
+	if arg.Round != round || block.Id != round {
+		return fmt.Errorf("proposal for round %v carries block %v, we are at round %v", arg.Round, block.Id, round)
+	}
+	if block.Hash != arg.Value {
+		return fmt.Errorf("proposal for %.8v carries block %.8v", arg.Value, block.Hash)
+	}
+	if block.Proposer != arg.GetCredential().GetUserId() {
+		return fmt.Errorf("block proposed by %v names %v as its proposer", arg.GetCredential().GetUserId(), block.Proposer)
+	}
+
+	// the proposer runs different rules than we do
+	if expected := upgrade.ProtocolAt(round); block.Protocol != expected {
+		return fmt.Errorf("block built under protocol %q, round runs %q", block.Protocol, expected)
+	}
+	params := supportedProtocols[block.Protocol]
+
+	if _, err := validateBlock(block, blockchain[len(blockchain)-1], l, vrfKeys, params); err != nil {
+		return err
+	}
+	if built, _ := blockTimestamp(block); built.After(now.Add(params.MaxClockDrift)) {
+		return fmt.Errorf("block was built at %v, more than %v from now", built, params.MaxClockDrift)
+	}
+	return nil
+}
+
+// verifyChain checks a whole chain starting at genesis block by block and
+// returns its accounts. The protocol versions are checked by verifyProtocol.
+func verifyChain(blockchain []*pb.Block, genesis *pb.Block, idToStake map[string]int, vrfKeys map[string]ed25519.PublicKey) (*ledger.Ledger, error) {
+	if len(blockchain) == 0 || blockchain[0].Hash != genesis.Hash {
+		return nil, fmt.Errorf("chain starts from a different genesis block")
+	}
+	l, err := ledger.Genesis(genesis)
+	if err != nil {
+		return nil, err
+	}
+
+	us := newUpgradeState()
+	totalStake := totalStakeOf(idToStake)
+	for i, block := range blockchain[1:] {
+		params, ok := supportedProtocols[us.ProtocolAt(block.Id)]
+		if !ok {
+			return nil, fmt.Errorf("block %v runs unsupported protocol %q", block.Id, us.ProtocolAt(block.Id))
+		}
+		if l, err = validateBlock(block, blockchain[i], l, vrfKeys, params); err != nil {
+			return nil, err
+		}
+		us.apply(block, idToStake, totalStake)
+	}
+	return l, nil
+}
+
+// checkCertificates lines certificates up with the rounds of blockchain,
+// keeping only those that hold up and are for the block the chain has in
+// their round. Every vote has to be signed by its voter's participation key
+// in l. Rounds without one are nil.
+func checkCertificates(blockchain []*pb.Block, certificates []*pb.Certificate, userIds []string, requiredVotes int64, l *ledger.Ledger) []*pb.Certificate {
+	aligned := make([]*pb.Certificate, len(blockchain))
+	for _, c := range certificates {
+		if c.GetRound() < 1 || c.GetRound() >= int64(len(blockchain)) || !certifies(c, blockchain[c.Round]) {
+			continue
+		}
+		err := agreement.VerifyCertificate(c, userIds, requiredVotes)
+		for _, vote := range c.Votes {
+			if err == nil {
+				err = agreement.VerifyVoteSignature(vote, c.Round, l.VotingKey(vote.UserId, c.Round))
+			}
+		}
+		if err != nil {
+			logging.Ledger.WithField("round", c.Round).Debugf("Ignoring certificate: %v", err)
+			continue
+		}
+		aligned[c.Round] = c
+	}
+	return aligned
+}
+
+// certifies reports whether c is for block. verifyChain makes sure a block
+// without a proposer is the canonical empty block.
+func certifies(c *pb.Certificate, block *pb.Block) bool {
+	if c.Value == agreement.Empty {
+		return block.Proposer == ""
+	}
+	return c.Value == calculateHash(block)
+}
+
+// checkTransaction turns away a transaction from a client or peer that no
+// block could hold, before it gets into our mempool.
+func checkTransaction(tx *pb.Transaction) error {
+	if tx == nil {
+		return fmt.Errorf("no transaction")
+	}
+	if size, limit := proto.Size(tx), supportedProtocols[latestProtocol].MaxTxBytes; size > limit {
+		return fmt.Errorf("transaction is %v bytes, at most %v allowed", size, limit)
+	}
+	return ledger.Check(tx)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+)
+
+// An event scheduled on the virtual clock. Message deliveries and timers both
+// end up here, so the whole simulation runs off a single ordered queue.
+type event struct {
+	at        time.Time
+	seq       uint64
+	fn        func()
+	cancelled bool
+	fired     bool
+}
+
+type eventQueue []*event
+
+func (q eventQueue) Len() int { return len(q) }
+
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		// same instant, keep scheduling order so runs are reproducible
+		return q[i].seq < q[j].seq
+	}
+	return q[i].at.Before(q[j].at)
+}
+
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *eventQueue) Push(x interface{}) { *q = append(*q, x.(*event)) }
+
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
+
+// virtualClock implements agreement.Clock. Time only moves when the
+// simulation asks for the next event, so nobody ever sleeps.
+type virtualClock struct {
+	now   time.Time
+	seq   uint64
+	queue eventQueue
+}
+
+func newVirtualClock() *virtualClock {
+	return &virtualClock{now: time.Unix(0, 0).UTC()}
+}
+
+func (c *virtualClock) Now() time.Time {
+	return c.now
+}
+
+func (c *virtualClock) schedule(d time.Duration, fn func()) *event {
+	c.seq++
+	e := &event{at: c.now.Add(d), seq: c.seq, fn: fn}
+	heap.Push(&c.queue, e)
+	return e
+}
+
+func (c *virtualClock) AfterFunc(d time.Duration, f func()) agreement.Timer {
+	return &virtualTimer{event: c.schedule(d, f)}
+}
+
+// step advances to the next pending event and runs it. It returns false once
+// nothing is scheduled before deadline.
+func (c *virtualClock) step(deadline time.Time) bool {
+	for c.queue.Len() > 0 {
+		e := c.queue[0]
+		if e.at.After(deadline) {
+			return false
+		}
+		heap.Pop(&c.queue)
+		if e.cancelled {
+			continue
+		}
+		c.now = e.at
+		e.fired = true
+		e.fn()
+		return true
+	}
+	return false
+}
+
+type virtualTimer struct {
+	event *event
+}
+
+func (t *virtualTimer) Stop() bool {
+	if t.event.fired || t.event.cancelled {
+		return false
+	}
+	t.event.cancelled = true
+	return true
+}
@@ -0,0 +1,356 @@
+// Command simulator runs a whole network of agreement services in one process,
+// over a virtual network and a virtual clock, and checks that no two nodes
+// ever commit different blocks for the same round (safety) and that every
+// node keeps committing blocks (liveness).
+//
+//	go run ./simulator -nodes 7 -rounds 200 -runs 20 -loss 0.05
+//	go run ./simulator -nodes 7 -byzantine 2 -modes equivocate,double-vote
+//
+// Every run is determined by its seed, a failing run can be replayed with -v
+// to see every node's log.
+package main
+
+import (
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/byzantine"
+	"github.com/nyu-distributed-systems-fa18/algorand/journal"
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+	"github.com/nyu-distributed-systems-fa18/algorand/participation"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+var verbose bool
+
+func logf(format string, v ...interface{}) {
+	if verbose {
+		log.Printf(format, v...)
+	}
+}
+
+func setLogPrefix(clock *virtualClock, who string) {
+	log.SetPrefix(fmt.Sprintf("%10v %-6v ", clock.Now().Sub(time.Unix(0, 0)), who))
+}
+
+// logFormatter prints the nodes' logs the way logf prints ours, under the
+// virtual time and node of the current log prefix.
+type logFormatter struct{}
+
+func (logFormatter) Format(e *logging.Entry) ([]byte, error) {
+	keys := []string{}
+	for k := range e.Data {
+		if k != "subsystem" && k != "node" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	line := fmt.Sprintf("%v%-5.5v %v", log.Prefix(), e.Level, e.Message)
+	for _, k := range keys {
+		line += fmt.Sprintf(" %v=%v", k, e.Data[k])
+	}
+	return []byte(line + "\n"), nil
+}
+
+type SimConfig struct {
+	Nodes   int
+	Rounds  int64
+	Seed    int64
+	Network NetworkConfig
+	// nodes start at random times within StartSkew of each other
+	StartSkew time.Duration
+	// virtual time after which a run counts as stalled
+	MaxTime time.Duration
+
+	// the last Byzantine nodes misbehave as Modes says, the safety and
+	// liveness checks only cover the honest ones
+	Byzantine int
+	Modes     byzantine.Modes
+
+	// directory to write every node's agreement journal to, empty for none
+	Journal string
+
+	// agreement timing and committee size every node runs with
+	Params agreement.Params
+}
+
+type SimStats struct {
+	commits       int64
+	laterPeriods  int64
+	missingBodies int64
+	empty         int64
+	fetches       int64
+	syncs         int64
+	equivocations int64
+	elapsed       time.Duration
+}
+
+type simulation struct {
+	cfg   SimConfig
+	clock *virtualClock
+	net   *virtualNetwork
+	nodes []*node
+
+	// the first value committed for every round, by anyone
+	committed map[int64]string
+	// who may vote and how many votes a certificate needs
+	userIds       []string
+	requiredVotes int64
+	violations    []string
+	stats         SimStats
+
+	// everyone's participation key, evidence has to be signed with it
+	votingKeys map[string]ed25519.PublicKey
+}
+
+func (s *simulation) violation(format string, v ...interface{}) {
+	msg := fmt.Sprintf("seed %v at %v: ", s.cfg.Seed, s.clock.Now().Sub(time.Unix(0, 0))) + fmt.Sprintf(format, v...)
+	logf("VIOLATION %v", msg)
+	s.violations = append(s.violations, msg)
+}
+
+// checkCommit records value as node's block for round, flagging a safety
+// violation if anybody has a different block for it.
+func (s *simulation) checkCommit(n *node, round int64, value string) {
+	if first, ok := s.committed[round]; !ok {
+		s.committed[round] = value
+	} else if first != value {
+		s.violation("safety: %v has %.8v for round %v, %.8v was committed before", n, value, round, first)
+	}
+}
+
+func newSimulation(cfg SimConfig) (*simulation, error) {
+	r := rand.New(rand.NewSource(cfg.Seed))
+	clock := newVirtualClock()
+	s := &simulation{
+		cfg:       cfg,
+		clock:     clock,
+		net:       newVirtualNetwork(cfg.Network, clock, r),
+		committed: make(map[int64]string),
+	}
+
+	// same layout as test_commands, user ids are the algorand ports
+	userIds := make([]string, cfg.Nodes)
+	idToStake := make(map[string]int)
+	for i := range userIds {
+		userIds[i] = strconv.Itoa(3001 + 2*i)
+		idToStake[userIds[i]] = 1 + r.Intn(9)
+	}
+	sort.Strings(userIds)
+
+	candidates := []string{}
+	for _, id := range userIds {
+		for j := 0; j < idToStake[id]; j++ {
+			candidates = append(candidates, id)
+		}
+	}
+
+	// 2t+1 required votes for Byzantine fault tolerance
+	t := int64(cfg.Nodes-1) / 3
+	requiredVotes := 2*t + 1
+	s.userIds, s.requiredVotes = userIds, requiredVotes
+
+	// VRF keys come from the seed too, so a run can be repeated
+	vrfKeys := make(map[string]ed25519.PrivateKey)
+	vrfPublicKeys := make(map[string]ed25519.PublicKey)
+	// participation keys follow from the VRF keys, so seeds keep playing out
+	// the way they did
+	signers := make(map[string]agreement.Signer)
+	s.votingKeys = make(map[string]ed25519.PublicKey)
+	for _, id := range userIds {
+		seed := make([]byte, ed25519.SeedSize)
+		r.Read(seed)
+		vrfKeys[id] = ed25519.NewKeyFromSeed(seed)
+		vrfPublicKeys[id] = vrfKeys[id].Public().(ed25519.PublicKey)
+
+		root := sha256.Sum256(append([]byte("participation:"), seed...))
+		key := participation.Insecure(ed25519.NewKeyFromSeed(root[:]), 1, participation.MaxRounds)
+		pub := key.Root
+		signers[id] = participation.NewKeys([]*participation.Key{key}, func(int64) ed25519.PublicKey { return pub })
+		s.votingKeys[id] = pub
+	}
+
+	genesis := &pb.Block{Id: 0, Hash: "genesis"}
+
+	for i, id := range userIds {
+		n := &node{index: i, userId: id, vrfKey: vrfKeys[id], sim: s, chain: []*pb.Block{genesis}}
+
+		network := &nodeNetwork{net: s.net, from: i}
+		var consensusNetwork agreement.Network = network
+		if i >= cfg.Nodes-cfg.Byzantine {
+			n.adversary = byzantine.NewNetwork(network, byzantine.Config{
+				UserId: id,
+				Modes:  cfg.Modes,
+				Hash:   hashBlock,
+				Rand:   r,
+				Signer: signers[id],
+			})
+			consensusNetwork = n.adversary
+		}
+
+		n.service = agreement.NewService(agreement.Config{
+			UserId:         id,
+			Candidates:     candidates,
+			VRFKeys:        vrfPublicKeys,
+			Signer:         signers[id],
+			RequiredVotes:  requiredVotes,
+			Params:         cfg.Params,
+			FutureRounds:   agreement.DefaultFutureRounds,
+			FutureMessages: agreement.DefaultFutureMessages,
+		}, clock, consensusNetwork)
+
+		if cfg.Journal != "" {
+			w, err := journal.Create(filepath.Join(cfg.Journal, fmt.Sprintf("seed-%v-%v.journal", cfg.Seed, id)))
+			if err != nil {
+				return nil, err
+			}
+			n.journal = w
+			n.service.SetJournal(w)
+		}
+		s.nodes = append(s.nodes, n)
+	}
+	s.net.nodes = s.nodes
+
+	return s, nil
+}
+
+// done reports whether every honest node has committed cfg.Rounds blocks.
+func (s *simulation) done() bool {
+	for _, n := range s.nodes {
+		if n.adversary == nil && int64(len(n.chain)) <= s.cfg.Rounds {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *simulation) run() {
+	s.net.start()
+	for _, n := range s.nodes {
+		n := n
+		skew := time.Duration(0)
+		if s.cfg.StartSkew > 0 {
+			skew = time.Duration(s.net.rand.Int63n(int64(s.cfg.StartSkew)))
+		}
+		s.clock.schedule(skew, n.start)
+	}
+
+	deadline := s.clock.Now().Add(s.cfg.MaxTime)
+	for !s.done() && s.clock.step(deadline) {
+		for _, n := range s.nodes {
+			n.drainTimeouts()
+		}
+	}
+	s.stats.elapsed = s.clock.Now().Sub(time.Unix(0, 0))
+
+	for _, n := range s.nodes {
+		if n.journal != nil {
+			if err := n.journal.Close(); err != nil {
+				s.violation("journal of %v: %v", n, err)
+			}
+		}
+	}
+
+	if !s.done() {
+		for _, n := range s.nodes {
+			if n.adversary == nil && int64(len(n.chain)) <= s.cfg.Rounds {
+				s.violation("liveness: %v only reached round %v of %v", n, len(n.chain)-1, s.cfg.Rounds)
+			}
+		}
+	}
+}
+
+func main() {
+	var cfg SimConfig
+	var runs int
+	flag.IntVar(&cfg.Nodes, "nodes", 4, "Number of nodes to simulate")
+	flag.Int64Var(&cfg.Rounds, "rounds", 100, "Rounds every node has to commit in each run")
+	flag.IntVar(&runs, "runs", 10, "Number of runs, each with its own seed")
+	flag.Int64Var(&cfg.Seed, "seed", 1, "Seed of the first run, later runs use seed+1, seed+2, ...")
+	flag.DurationVar(&cfg.Network.Latency, "latency", 20*time.Millisecond, "Minimum message latency")
+	flag.DurationVar(&cfg.Network.Jitter, "jitter", 30*time.Millisecond, "Random extra latency on top of -latency")
+	flag.Float64Var(&cfg.Network.Loss, "loss", 0, "Probability a message is dropped")
+	flag.DurationVar(&cfg.Network.PartitionEvery, "partition-every", 0, "Split the network in two this often, 0 to never partition")
+	flag.DurationVar(&cfg.Network.PartitionFor, "partition-for", 30*time.Second, "How long a partition lasts")
+	flag.DurationVar(&cfg.StartSkew, "skew", 500*time.Millisecond, "Nodes start at random times within this window")
+	flag.DurationVar(&cfg.MaxTime, "max-time", 0, "Virtual time a run may take before it counts as stalled, defaults to 5 minutes per round")
+	flag.IntVar(&cfg.Byzantine, "byzantine", 0, "Number of Byzantine nodes, at most t = (nodes-1)/3")
+	modes := flag.String("modes", "all", "Comma separated misbehaviours of the Byzantine nodes: equivocate, double-vote, forge, withhold, invalid-block, replay or all")
+	flag.StringVar(&cfg.Journal, "journal", "", "Directory to write every node's agreement journal to, replay them with the replay tool")
+	cfg.Params = agreement.DefaultParams
+	flag.DurationVar(&cfg.Params.Lambda, "lambda", cfg.Params.Lambda, "λ, how long a vote takes to reach everyone")
+	flag.DurationVar(&cfg.Params.BigLambda, "big-lambda", cfg.Params.BigLambda, "Λ, how long a block takes to reach everyone")
+	flag.DurationVar(&cfg.Params.RoundInterval, "round-interval", cfg.Params.RoundInterval, "How often nodes check whether they can start a new round")
+	flag.Int64Var(&cfg.Params.K, "k", cfg.Params.K, "Committee size, the number of proposers per period on average")
+	flag.BoolVar(&verbose, "v", false, "Print every node's log")
+	flag.Parse()
+
+	if cfg.Nodes < 4 {
+		log.Fatalf("Need at least 4 nodes to achieve Byzantine fault tolerance")
+	}
+	if t := (cfg.Nodes - 1) / 3; cfg.Byzantine > t {
+		log.Fatalf("%v nodes only tolerate %v Byzantine nodes", cfg.Nodes, t)
+	}
+	if err := cfg.Params.Validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+	var err error
+	if cfg.Modes, err = byzantine.ParseModes(*modes); err != nil {
+		log.Fatalf("%v", err)
+	}
+	if cfg.MaxTime == 0 {
+		cfg.MaxTime = time.Duration(cfg.Rounds) * 5 * time.Minute
+	}
+	if verbose {
+		log.SetFlags(0)
+		logging.SetFormatter(logFormatter{})
+		logging.SetOutput(os.Stderr)
+	} else {
+		log.SetOutput(ioutil.Discard)
+		logging.SetOutput(ioutil.Discard)
+		logging.SetLevel(logging.PanicLevel)
+	}
+
+	firstSeed := cfg.Seed
+	var violations []string
+	var total SimStats
+	for i := 0; i < runs; i++ {
+		cfg.Seed = firstSeed + int64(i)
+		s, err := newSimulation(cfg)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		s.run()
+
+		fmt.Printf("seed %v: %v rounds in %v, %v commits (%v after period 1, %v without a body, %v empty), %v fetches, %v syncs, %v equivocations caught, %v/%v/%v messages sent/lost/partitioned\n",
+			cfg.Seed, len(s.committed), s.stats.elapsed, s.stats.commits, s.stats.laterPeriods, s.stats.missingBodies, s.stats.empty,
+			s.stats.fetches, s.stats.syncs, s.stats.equivocations, s.net.stats.sent, s.net.stats.dropped, s.net.stats.partitioned)
+
+		violations = append(violations, s.violations...)
+		total.commits += s.stats.commits
+		total.elapsed += s.stats.elapsed
+	}
+
+	fmt.Printf("%v runs, %v commits, %v virtual time\n", runs, total.commits, total.elapsed)
+	if len(violations) > 0 {
+		for _, v := range violations {
+			fmt.Println(v)
+		}
+		os.Exit(1)
+	}
+	fmt.Println("OK")
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+type NetworkConfig struct {
+	// every message takes Latency plus up to Jitter to arrive
+	Latency time.Duration
+	Jitter  time.Duration
+	// probability a message is dropped
+	Loss float64
+
+	// every PartitionEvery the nodes are split in two random groups that
+	// can't talk to each other for PartitionFor
+	PartitionEvery time.Duration
+	PartitionFor   time.Duration
+}
+
+type NetworkStats struct {
+	sent        int64
+	dropped     int64
+	partitioned int64
+}
+
+// virtualNetwork delivers messages between simulated nodes through the
+// virtual clock.
+type virtualNetwork struct {
+	cfg   NetworkConfig
+	clock *virtualClock
+	rand  *rand.Rand
+	nodes []*node
+
+	// group[i] is the side of the current partition node i is on
+	group       []int
+	partitioned bool
+
+	stats NetworkStats
+}
+
+func newVirtualNetwork(cfg NetworkConfig, clock *virtualClock, r *rand.Rand) *virtualNetwork {
+	return &virtualNetwork{cfg: cfg, clock: clock, rand: r}
+}
+
+func (n *virtualNetwork) start() {
+	n.group = make([]int, len(n.nodes))
+	if n.cfg.PartitionEvery > 0 {
+		n.clock.schedule(n.cfg.PartitionEvery, n.partition)
+	}
+}
+
+func (n *virtualNetwork) partition() {
+	for i := range n.group {
+		n.group[i] = n.rand.Intn(2)
+	}
+	n.partitioned = true
+	logf("partition: %v", n.group)
+
+	n.clock.schedule(n.cfg.PartitionFor, func() {
+		n.partitioned = false
+		logf("partition healed")
+		n.clock.schedule(n.cfg.PartitionEvery, n.partition)
+	})
+}
+
+func (n *virtualNetwork) connected(from, to int) bool {
+	return !n.partitioned || n.group[from] == n.group[to]
+}
+
+func (n *virtualNetwork) delay() time.Duration {
+	d := n.cfg.Latency
+	if n.cfg.Jitter > 0 {
+		d += time.Duration(n.rand.Int63n(int64(n.cfg.Jitter)))
+	}
+	return d
+}
+
+// send schedules deliver on node to, unless the message is lost.
+func (n *virtualNetwork) send(from, to int, deliver func(*node)) {
+	n.stats.sent++
+	if !n.connected(from, to) {
+		n.stats.partitioned++
+		return
+	}
+	if n.cfg.Loss > 0 && n.rand.Float64() < n.cfg.Loss {
+		n.stats.dropped++
+		return
+	}
+	target := n.nodes[to]
+	n.clock.schedule(n.delay(), func() {
+		deliver(target)
+	})
+}
+
+func (n *virtualNetwork) broadcast(from int, deliver func(*node)) {
+	for to := range n.nodes {
+		if to != from {
+			n.send(from, to, deliver)
+		}
+	}
+}
+
+// nodeNetwork is a single node's view of the network, it implements agreement.Network.
+type nodeNetwork struct {
+	net  *virtualNetwork
+	from int
+}
+
+func (n *nodeNetwork) Peers() []string {
+	peers := []string{}
+	for i, peer := range n.net.nodes {
+		if i != n.from {
+			peers = append(peers, peer.userId)
+		}
+	}
+	return peers
+}
+
+func (n *nodeNetwork) indexOf(userId string) int {
+	for i, peer := range n.net.nodes {
+		if peer.userId == userId {
+			return i
+		}
+	}
+	panic("unknown peer " + userId)
+}
+
+func (n *nodeNetwork) SendProposal(peer string, proposal *pb.ProposeBlockArgs) {
+	n.net.send(n.from, n.indexOf(peer), func(target *node) {
+		target.receiveProposal(proposal)
+	})
+}
+
+func (n *nodeNetwork) SendVote(peer string, vote *pb.VoteArgs) {
+	n.net.send(n.from, n.indexOf(peer), func(target *node) {
+		target.receiveVote(vote)
+	})
+}
+
+func (n *nodeNetwork) BroadcastProposal(proposal *pb.ProposeBlockArgs) {
+	n.net.broadcast(n.from, func(target *node) {
+		target.receiveProposal(proposal)
+	})
+}
+
+func (n *nodeNetwork) BroadcastPriority(priority *pb.ProposePriorityArgs) {
+	n.net.broadcast(n.from, func(target *node) {
+		target.receivePriority(priority)
+	})
+}
+
+func (n *nodeNetwork) BroadcastVote(vote *pb.VoteArgs) {
+	n.net.broadcast(n.from, func(target *node) {
+		target.receiveVote(vote)
+	})
+}
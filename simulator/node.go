@@ -0,0 +1,266 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/byzantine"
+	"github.com/nyu-distributed-systems-fa18/algorand/journal"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+	"github.com/nyu-distributed-systems-fa18/algorand/vrf"
+)
+
+// node plays the part serve() plays in the real server: it owns the chain and
+// carries out the actions the agreement service hands back.
+type node struct {
+	index   int
+	userId  string
+	vrfKey  ed25519.PrivateKey
+	sim     *simulation
+	service *agreement.Service
+	// nil for honest nodes
+	adversary *byzantine.Network
+	journal   *journal.Writer
+
+	chain   []*pb.Block
+	syncing bool
+}
+
+func (n *node) trace() {
+	if verbose {
+		setLogPrefix(n.sim.clock, n.userId)
+	}
+}
+
+func (n *node) start() {
+	n.trace()
+	n.execute(n.service.Start())
+}
+
+func (n *node) receiveProposal(proposal *pb.ProposeBlockArgs) {
+	n.trace()
+	if proposal.Block != nil && hashBlock(proposal.Block) != proposal.Value {
+		// the server turns these away before agreement sees them
+		return
+	}
+	actions, _ := n.service.Handle(agreement.ProposalReceived{Proposal: proposal})
+	n.execute(actions)
+}
+
+func (n *node) receivePriority(priority *pb.ProposePriorityArgs) {
+	n.trace()
+	actions, _ := n.service.Handle(agreement.PriorityReceived{Priority: priority})
+	n.execute(actions)
+}
+
+func (n *node) receiveVote(vote *pb.VoteArgs) {
+	n.trace()
+	actions, _ := n.service.Handle(agreement.VoteReceived{Vote: vote})
+	n.execute(actions)
+}
+
+// drainTimeouts handles timers that fired while the clock advanced.
+func (n *node) drainTimeouts() {
+	for {
+		select {
+		case timeout := <-n.service.Timeouts():
+			n.trace()
+			actions, _ := n.service.Handle(timeout)
+			n.execute(actions)
+		default:
+			return
+		}
+	}
+}
+
+func (n *node) execute(actions []agreement.Action) {
+	for _, action := range actions {
+		switch a := action.(type) {
+		case agreement.AssembleBlock:
+			b := n.assemble(a.Round, a.Period, a.PrevSeed)
+			_, proof := vrf.Prove(n.vrfKey, a.Seed)
+			more, _ := n.service.Handle(agreement.BlockAssembled{Round: a.Round, Period: a.Period, Block: b, Value: b.Hash, Proof: proof})
+			n.execute(more)
+			if n.adversary != nil {
+				n.adversary.Assembled(a.Round, a.Period, b, b.Hash, proof)
+			}
+
+		case agreement.Commit:
+			n.commit(a)
+
+		case agreement.FetchBlock:
+			n.fetchBlock(a)
+
+		case agreement.CheckProposal:
+			// its hash was checked when it arrived, that's all we check
+			actions, _ := n.service.Handle(agreement.ProposalReceived{Proposal: a.Proposal})
+			n.execute(actions)
+
+		case agreement.RequestSync:
+			n.requestSync()
+
+		case agreement.Equivocation:
+			n.equivocation(a.Evidence)
+		}
+	}
+}
+
+func (n *node) assemble(round, period int64, prevSeed string) *pb.Block {
+	last := n.chain[len(n.chain)-1]
+	b := &pb.Block{
+		Id:        round,
+		Timestamp: n.sim.clock.Now().String(),
+		PrevHash:  last.Hash,
+		Proposer:  n.userId,
+	}
+	b.Seed, b.SeedProof = agreement.BlockSeed(n.vrfKey, prevSeed, round)
+	b.Timestamp += " period " + strconv.FormatInt(period, 10)
+	b.Hash = hashBlock(b)
+	return b
+}
+
+func hashBlock(b *pb.Block) string {
+	record := strconv.FormatInt(b.Id, 10) + b.Timestamp + b.PrevHash
+	for _, field := range []string{b.Proposer, b.Seed} {
+		record += strconv.Itoa(len(field)) + ":" + field
+	}
+	h := sha256.Sum256([]byte(record))
+	return hex.EncodeToString(h[:])
+}
+
+func (n *node) commit(c agreement.Commit) {
+	n.sim.stats.commits++
+	if c.Period > 1 {
+		n.sim.stats.laterPeriods++
+	}
+
+	if c.Round != int64(len(n.chain)) {
+		if n.adversary != nil {
+			return
+		}
+		n.sim.violation("node %v committed round %v but its chain ends at round %v", n.userId, c.Round, len(n.chain)-1)
+		return
+	}
+	if n.adversary == nil {
+		if err := agreement.VerifyCertificate(c.Certificate, n.sim.userIds, n.sim.requiredVotes); err != nil {
+			n.sim.violation("%v committed round %v without a valid certificate: %v", n, c.Round, err)
+		}
+	}
+
+	block := c.Block
+	if c.Value == agreement.Empty {
+		n.sim.stats.empty++
+		last := n.chain[len(n.chain)-1]
+		block = &pb.Block{Id: c.Round, Timestamp: last.Timestamp, PrevHash: last.Hash, Seed: agreement.EmptySeed(last.Seed, c.Round)}
+		block.Hash = hashBlock(block)
+	} else if block == nil {
+		// we agreed on a value whose proposal never reached us, keep the chain
+		// linked by its hash so later rounds can still be checked
+		n.sim.stats.missingBodies++
+		block = &pb.Block{Id: c.Round, Hash: c.Value}
+	}
+	if n.adversary == nil {
+		// by block hash, like the blocks a sync brings in: an empty round
+		// has its empty block's
+		n.sim.checkCommit(n, c.Round, block.Hash)
+	}
+	n.chain = append(n.chain, block)
+}
+
+// fetchBlock asks the voters of a decided value for its block, the way the
+// server's GetProposal does, and hands back the first answer that hashes right.
+func (n *node) fetchBlock(f agreement.FetchBlock) {
+	n.sim.stats.fetches++
+	voters := make(map[string]bool)
+	for _, v := range f.Voters {
+		voters[v] = true
+	}
+	for _, peer := range n.sim.nodes {
+		if peer == n || (len(voters) > 0 && !voters[peer.userId]) {
+			continue
+		}
+		n.sim.net.send(n.index, peer.index, func(peer *node) {
+			block := peer.lookup(f.Round, f.Value)
+			if block == nil {
+				return
+			}
+			n.sim.net.send(peer.index, n.index, func(n *node) {
+				if block.Id != f.Round || hashBlock(block) != f.Value {
+					return
+				}
+				n.trace()
+				actions, _ := n.service.Handle(agreement.BlockFetched{Round: f.Round, Value: f.Value, Block: block})
+				n.execute(actions)
+			})
+		})
+	}
+}
+
+// lookup answers a fetch from the chain or the proposals of the current round.
+func (n *node) lookup(round int64, value string) *pb.Block {
+	if round < int64(len(n.chain)) {
+		if b := n.chain[round]; b.Hash == value {
+			return b
+		}
+		return nil
+	}
+	if round == n.service.Round() {
+		return n.service.Block(value)
+	}
+	return nil
+}
+
+// equivocation checks that evidence holds up and only ever accuses Byzantine nodes.
+func (n *node) equivocation(e *pb.Evidence) {
+	n.sim.stats.equivocations++
+	if err := agreement.VerifyEvidence(e, n.sim.votingKeys[e.UserId]); err != nil {
+		n.sim.violation("%v reported invalid evidence against %v: %v", n, e.UserId, err)
+	}
+	for _, accused := range n.sim.nodes {
+		if accused.userId == e.UserId && accused.adversary == nil {
+			n.sim.violation("%v accused honest node %v of equivocating in round %v period %v step %v", n, e.UserId, e.Round, e.Period, e.Step)
+		}
+	}
+}
+
+func (n *node) requestSync() {
+	if n.syncing {
+		return
+	}
+	n.syncing = true
+	n.sim.stats.syncs++
+
+	n.sim.clock.schedule(n.sim.net.delay(), func() {
+		n.syncing = false
+
+		var longest []*pb.Block
+		for _, peer := range n.sim.nodes {
+			if peer != n && n.sim.net.connected(n.index, peer.index) && len(peer.chain) > len(longest) {
+				longest = peer.chain
+			}
+		}
+		if len(longest) <= len(n.chain) {
+			return
+		}
+
+		for i, block := range longest[1:] {
+			if n.adversary == nil {
+				n.sim.checkCommit(n, int64(i+1), block.Hash)
+			}
+		}
+		n.chain = append([]*pb.Block{}, longest...)
+
+		n.trace()
+		actions, _ := n.service.Handle(agreement.Synced{Round: int64(len(n.chain)), Seed: n.chain[len(n.chain)-1].Seed})
+		n.execute(actions)
+	})
+}
+
+func (n *node) String() string {
+	return fmt.Sprintf("node %v", n.userId)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/agreement"
+	"github.com/nyu-distributed-systems-fa18/algorand/byzantine"
+	"github.com/nyu-distributed-systems-fa18/algorand/logging"
+)
+
+func TestSimulation(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	logging.SetOutput(ioutil.Discard)
+	logging.SetLevel(logging.PanicLevel)
+
+	tests := []struct {
+		name      string
+		nodes     int
+		rounds    int64
+		seeds     []int64
+		network   NetworkConfig
+		byzantine int
+	}{
+		{name: "clean", nodes: 4, rounds: 30, seeds: []int64{1, 2}},
+		{name: "loss", nodes: 7, rounds: 30, seeds: []int64{1, 2}, network: NetworkConfig{Loss: 0.1}},
+		// seeds that commit empty blocks and sync them in from peers
+		{name: "loss and partitions", nodes: 7, rounds: 50, seeds: []int64{2, 3}, network: NetworkConfig{Loss: 0.1, PartitionEvery: 5 * time.Minute, PartitionFor: 30 * time.Second}},
+		{name: "byzantine", nodes: 7, rounds: 20, seeds: []int64{1, 2}, byzantine: 2},
+	}
+	all, err := byzantine.ParseModes("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		for _, seed := range test.seeds {
+			cfg := SimConfig{
+				Nodes:     test.nodes,
+				Rounds:    test.rounds,
+				Seed:      seed,
+				Network:   test.network,
+				StartSkew: 500 * time.Millisecond,
+				MaxTime:   time.Duration(test.rounds) * 5 * time.Minute,
+				Byzantine: test.byzantine,
+				Modes:     all,
+				Params:    agreement.DefaultParams,
+			}
+			cfg.Network.Latency = 20 * time.Millisecond
+			cfg.Network.Jitter = 30 * time.Millisecond
+
+			s, err := newSimulation(cfg)
+			if err != nil {
+				t.Fatalf("%v seed %v: %v", test.name, seed, err)
+			}
+			s.run()
+			for _, v := range s.violations {
+				t.Errorf("%v: %v", test.name, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Package vrf implements the verifiable random function ECVRF-EDWARDS25519-
+// SHA512-TAI of RFC 9381. Only the holder of a private key can compute the
+// output for an input, and anyone with the public key can check it from the
+// proof. For a given key and input there is exactly one output, so a prover
+// can't grind for a better one.
+//
+// Keys are ordinary ed25519 keys.
+package vrf
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+
+	"filippo.io/edwards25519"
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	// ProofSize is the length of a proof: Gamma, c and s.
+	ProofSize = 80
+	// OutputSize is the length of an output.
+	OutputSize = 64
+
+	suite     = 0x03
+	challenge = 16
+)
+
+var ErrBadProof = errors.New("vrf proof doesn't verify")
+
+// Prove returns the output of the function for alpha under key, and the proof for it.
+func Prove(key ed25519.PrivateKey, alpha []byte) (output, proof []byte) {
+	digest := sha512.Sum512(key.Seed())
+	x, err := edwards25519.NewScalar().SetBytesWithClamping(digest[:32])
+	if err != nil {
+		panic(err)
+	}
+	pub := []byte(key.Public().(ed25519.PublicKey))
+
+	h := encodeToCurve(pub, alpha)
+	hBytes := h.Bytes()
+	gamma := new(edwards25519.Point).ScalarMult(x, h)
+
+	// deterministic nonce, as ed25519 signing derives it
+	nonce := sha512.New()
+	nonce.Write(digest[32:])
+	nonce.Write(hBytes)
+	k, err := edwards25519.NewScalar().SetUniformBytes(nonce.Sum(nil))
+	if err != nil {
+		panic(err)
+	}
+
+	u := new(edwards25519.Point).ScalarBaseMult(k)
+	v := new(edwards25519.Point).ScalarMult(k, h)
+	c := challengeOf(pub, hBytes, gamma, u, v)
+	s := edwards25519.NewScalar().MultiplyAdd(scalarOf(c), x, k)
+
+	proof = make([]byte, 0, ProofSize)
+	proof = append(proof, gamma.Bytes()...)
+	proof = append(proof, c...)
+	proof = append(proof, s.Bytes()...)
+	return outputOf(gamma), proof
+}
+
+// Verify checks proof for alpha under pub and returns the output it proves.
+func Verify(pub ed25519.PublicKey, alpha []byte, proof []byte) ([]byte, error) {
+	if len(pub) != ed25519.PublicKeySize || len(proof) != ProofSize {
+		return nil, ErrBadProof
+	}
+	y, err := new(edwards25519.Point).SetBytes(pub)
+	if err != nil || isSmallOrder(y) {
+		return nil, ErrBadProof
+	}
+	gamma, err := new(edwards25519.Point).SetBytes(proof[:32])
+	if err != nil {
+		return nil, ErrBadProof
+	}
+	c := proof[32 : 32+challenge]
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(proof[32+challenge:])
+	if err != nil {
+		return nil, ErrBadProof
+	}
+
+	h := encodeToCurve(pub, alpha)
+	negC := edwards25519.NewScalar().Negate(scalarOf(c))
+	// U = s*B - c*Y, V = s*H - c*Gamma
+	u := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(negC, y, s)
+	v := new(edwards25519.Point).VarTimeMultiScalarMult([]*edwards25519.Scalar{s, negC}, []*edwards25519.Point{h, gamma})
+	if !bytes.Equal(c, challengeOf(pub, h.Bytes(), gamma, u, v)) {
+		return nil, ErrBadProof
+	}
+	return outputOf(gamma), nil
+}
+
+// encodeToCurve hashes alpha to a point by try and increment.
+func encodeToCurve(pub []byte, alpha []byte) *edwards25519.Point {
+	for ctr := 0; ctr < 256; ctr++ {
+		h := sha512.New()
+		h.Write([]byte{suite, 0x01})
+		h.Write(pub)
+		h.Write(alpha)
+		h.Write([]byte{byte(ctr), 0x00})
+		if p, err := new(edwards25519.Point).SetBytes(h.Sum(nil)[:32]); err == nil {
+			return p.MultByCofactor(p)
+		}
+	}
+	// about half of all strings decode, 256 failures in a row don't happen
+	panic("vrf: no point found")
+}
+
+func challengeOf(pub []byte, h []byte, gamma, u, v *edwards25519.Point) []byte {
+	d := sha512.New()
+	d.Write([]byte{suite, 0x02})
+	d.Write(pub)
+	d.Write(h)
+	d.Write(gamma.Bytes())
+	d.Write(u.Bytes())
+	d.Write(v.Bytes())
+	d.Write([]byte{0x00})
+	return d.Sum(nil)[:challenge]
+}
+
+// scalarOf reads a challenge, little endian and shorter than the group order.
+func scalarOf(c []byte) *edwards25519.Scalar {
+	b := make([]byte, 32)
+	copy(b, c)
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(b)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func outputOf(gamma *edwards25519.Point) []byte {
+	d := sha512.New()
+	d.Write([]byte{suite, 0x03})
+	d.Write(new(edwards25519.Point).MultByCofactor(gamma).Bytes())
+	d.Write([]byte{0x00})
+	return d.Sum(nil)
+}
+
+func isSmallOrder(p *edwards25519.Point) bool {
+	return new(edwards25519.Point).MultByCofactor(p).Equal(edwards25519.NewIdentityPoint()) == 1
+}
@@ -0,0 +1,83 @@
+package vrf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// Example 16 of RFC 9381, appendix B.3, for ECVRF-EDWARDS25519-SHA512-TAI.
+var vectors = []struct {
+	sk, pk, alpha, pi, beta string
+}{
+	{
+		sk:    "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+		pk:    "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+		alpha: "",
+		pi:    "8657106690b5526245a92b003bb079ccd1a92130477671f6fc01ad16f26f723f26f8a57ccaed74ee1b190bed1f479d9727d2d0f9b005a6e456a35d4fb0daab1268a1b0db10836d9826a528ca76567805",
+		beta:  "90cf1df3b703cce59e2a35b925d411164068269d7b2d29f3301c03dd757876ff66b71dda49d2de59d03450451af026798e8f81cd2e333de5cdf4f3e140fdd8ae",
+	},
+}
+
+func decode(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestVectors(t *testing.T) {
+	for i, v := range vectors {
+		key := ed25519.NewKeyFromSeed(decode(t, v.sk))
+		pub := key.Public().(ed25519.PublicKey)
+		if !bytes.Equal(pub, decode(t, v.pk)) {
+			t.Fatalf("vector %v: public key %x", i, pub)
+		}
+		alpha, pi, beta := decode(t, v.alpha), decode(t, v.pi), decode(t, v.beta)
+
+		output, proof := Prove(key, alpha)
+		if !bytes.Equal(proof, pi) {
+			t.Errorf("vector %v: proof %x, want %x", i, proof, pi)
+		}
+		if !bytes.Equal(output, beta) {
+			t.Errorf("vector %v: output %x, want %x", i, output, beta)
+		}
+		output, err := Verify(pub, alpha, pi)
+		if err != nil {
+			t.Errorf("vector %v: %v", i, err)
+		} else if !bytes.Equal(output, beta) {
+			t.Errorf("vector %v: verified output %x, want %x", i, output, beta)
+		}
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	other := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+	pub := key.Public().(ed25519.PublicKey)
+	alpha := []byte("round 7")
+	_, proof := Prove(key, alpha)
+
+	flipped := append([]byte(nil), proof...)
+	flipped[40] ^= 1
+	tests := []struct {
+		name  string
+		pub   ed25519.PublicKey
+		alpha []byte
+		proof []byte
+	}{
+		{"other input", pub, []byte("round 8"), proof},
+		{"other key", other.Public().(ed25519.PublicKey), alpha, proof},
+		{"altered proof", pub, alpha, flipped},
+		{"short proof", pub, alpha, proof[:ProofSize-1]},
+		{"short key", pub[:16], alpha, proof},
+	}
+	for _, test := range tests {
+		if _, err := Verify(test.pub, test.alpha, test.proof); err != ErrBadProof {
+			t.Errorf("%v: got %v, want %v", test.name, err, ErrBadProof)
+		}
+	}
+}
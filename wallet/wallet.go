@@ -0,0 +1,176 @@
+// Package wallet builds transactions, signs them and carries them between
+// the two in files, so the machine holding an account key never has to be
+// online. A transaction goes through three steps, each of which can happen
+// on another machine:
+//
+//	tx, _ := wallet.Transfer(from, to, amount, nonce)  // online or offline
+//	wallet.Write("transfer.json", tx)
+//
+//	tx, _ := wallet.Read("transfer.json")              // offline
+//	wallet.Sign(tx, key)
+//	wallet.Write("transfer.signed.json", tx)
+//
+//	tx, _ := wallet.Read("transfer.signed.json")       // online
+//	wallet.Submit(ctx, client, tx)
+//
+// The files are JSON, so whoever signs can read what they sign first.
+package wallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/crypto/ed25519"
+	context "golang.org/x/net/context"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/ledger"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+var (
+	ErrWrongKey = errors.New("key doesn't sign for the sending account")
+	ErrUnsigned = errors.New("transaction isn't signed")
+)
+
+// Transfer returns an unsigned transfer of amount from one account to
+// another. nonce has to be one more than that of from's previous transfer,
+// see NextNonce.
+func Transfer(from, to string, amount, nonce int64) (*pb.Transaction, error) {
+	if err := checkAddress(from); err != nil {
+		return nil, err
+	}
+	if err := checkAddress(to); err != nil {
+		return nil, err
+	}
+	if amount <= 0 {
+		return nil, ledger.ErrBadAmount
+	}
+	return &pb.Transaction{From: from, To: to, Amount: amount, Nonce: nonce}, nil
+}
+
+// Registration returns an unsigned registration of the participation key
+// userId votes with from firstRound through lastRound. owner is the
+// account that owns userId, nonce works as for Transfer.
+func Registration(owner, userId string, key ed25519.PublicKey, firstRound, lastRound, nonce int64) (*pb.Transaction, error) {
+	if err := checkAddress(owner); err != nil {
+		return nil, err
+	}
+	tx := ledger.Register(userId, key, firstRound, lastRound)
+	tx.From = owner
+	tx.Nonce = nonce
+	return tx, nil
+}
+
+// NextNonce is the nonce the next transfer or registration from address
+// has to use, after those in chain. Transactions still waiting for a block
+// aren't counted.
+func NextNonce(chain []*pb.Block, address string) int64 {
+	var nonce int64
+	for _, block := range chain {
+		for _, tx := range block.GetTx() {
+			if tx.From == address && tx.Nonce > nonce {
+				nonce = tx.Nonce
+			}
+		}
+	}
+	return nonce + 1
+}
+
+// Sign signs tx with key, which has to be the key of its sending account.
+func Sign(tx *pb.Transaction, key ed25519.PrivateKey) error {
+	if tx.From != ledger.Address(key.Public().(ed25519.PublicKey)) {
+		return fmt.Errorf("%v %.16v", ErrWrongKey, tx.From)
+	}
+	ledger.Sign(tx, key)
+	return ledger.Check(tx)
+}
+
+// Submit checks that tx is signed and sends it to a node. The node answers
+// with its chain whether it takes tx or not, see Included.
+func Submit(ctx context.Context, client pb.BCStoreClient, tx *pb.Transaction) (*pb.Result, error) {
+	if tx.Signature == "" {
+		return nil, ErrUnsigned
+	}
+	if err := ledger.Check(tx); err != nil {
+		return nil, err
+	}
+	return client.Send(ctx, tx)
+}
+
+// Included returns the round of the block in chain that holds tx, or 0.
+func Included(chain []*pb.Block, tx *pb.Transaction) int64 {
+	for _, block := range chain {
+		for _, other := range block.GetTx() {
+			if other.From == tx.From && other.Nonce == tx.Nonce && other.Signature == tx.Signature {
+				return block.Id
+			}
+		}
+	}
+	return 0
+}
+
+// Describe says what tx does, for whoever is about to sign it.
+func Describe(tx *pb.Transaction) string {
+	var what string
+	if p := tx.Participation; p != nil {
+		what = fmt.Sprintf("register participation key %v for user %v, rounds %v to %v, owner %v", p.Key, p.UserId, p.FirstRound, p.LastRound, tx.From)
+	} else {
+		what = fmt.Sprintf("transfer %v from %v to %v", tx.Amount, tx.From, tx.To)
+	}
+	what += fmt.Sprintf(", nonce %v", tx.Nonce)
+	if tx.V != "" {
+		what += fmt.Sprintf(", note %q", tx.V)
+	}
+	if tx.Signature == "" {
+		return what + ", unsigned"
+	}
+	if err := ledger.Check(tx); err != nil {
+		return what + ", INVALID: " + err.Error()
+	}
+	return what + ", signed"
+}
+
+// Write saves tx to path as JSON, it never replaces an existing file.
+func Write(path string, tx *pb.Transaction) error {
+	b, err := json.MarshalIndent(tx, "", "    ")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read loads a transaction Write saved.
+func Read(path string) (*pb.Transaction, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	tx := &pb.Transaction{}
+	if err := decoder.Decode(tx); err != nil {
+		return nil, fmt.Errorf("%v: isn't a transaction: %v", path, err)
+	}
+	return tx, nil
+}
+
+func checkAddress(address string) error {
+	b, err := hex.DecodeString(address)
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return fmt.Errorf("%v: %.16q", ledger.ErrBadAddress, address)
+	}
+	return nil
+}
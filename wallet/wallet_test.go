@@ -0,0 +1,188 @@
+package wallet
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+	context "golang.org/x/net/context"
+
+	"github.com/nyu-distributed-systems-fa18/algorand/ledger"
+	"github.com/nyu-distributed-systems-fa18/algorand/pb"
+)
+
+var (
+	alice = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize))
+	bob   = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{2}, ed25519.SeedSize))
+	votes = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{3}, ed25519.SeedSize))
+	// alice's participation key after votes
+	nextVotes = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{4}, ed25519.SeedSize))
+)
+
+func address(key ed25519.PrivateKey) string {
+	return ledger.Address(key.Public().(ed25519.PublicKey))
+}
+
+// The whole way a transaction takes: built and written on one machine,
+// read and signed on another, read back and applied to the ledger.
+func TestRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	genesis := &pb.Block{Tx: []*pb.Transaction{
+		{To: address(alice), Amount: 100},
+		{To: address(alice), Participation: ledger.Register("alice", votes.Public().(ed25519.PublicKey), 1, 100).Participation},
+	}}
+	chain := []*pb.Block{genesis}
+
+	transfer, err := Transfer(address(alice), address(bob), 30, NextNonce(chain, address(alice)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registration, err := Registration(address(alice), "alice", nextVotes.Public().(ed25519.PublicKey), 50, 200, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tx := range []*pb.Transaction{transfer, registration} {
+		unsigned := filepath.Join(dir, "tx.json")
+		signed := filepath.Join(dir, "tx.signed.json")
+		if err := Write(unsigned, tx); err != nil {
+			t.Fatal(err)
+		}
+		offline, err := Read(unsigned)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(offline, tx) {
+			t.Fatalf("transaction %v: read back %v, wrote %v", i, offline, tx)
+		}
+		if !strings.HasSuffix(Describe(offline), ", unsigned") {
+			t.Errorf("transaction %v: %v", i, Describe(offline))
+		}
+		if _, err := Submit(context.Background(), nil, offline); err != ErrUnsigned {
+			t.Errorf("transaction %v: submitting it unsigned: got %v, want %v", i, err, ErrUnsigned)
+		}
+
+		if err := Sign(offline, bob); err == nil || !strings.HasPrefix(err.Error(), ErrWrongKey.Error()) {
+			t.Errorf("transaction %v: signing with bob's key: got %v, want %v", i, err, ErrWrongKey)
+		}
+		if err := Sign(offline, alice); err != nil {
+			t.Fatal(err)
+		}
+		if err := Write(signed, offline); err != nil {
+			t.Fatal(err)
+		}
+		online, err := Read(signed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(Describe(online), ", signed") {
+			t.Errorf("transaction %v: %v", i, Describe(online))
+		}
+		if err := ledger.Check(online); err != nil {
+			t.Errorf("transaction %v: %v", i, err)
+		}
+
+		chain = append(chain, &pb.Block{Id: int64(len(chain)), Tx: []*pb.Transaction{online}})
+		if round := Included(chain, online); round != int64(len(chain)-1) {
+			t.Errorf("transaction %v: included in round %v, want %v", i, round, len(chain)-1)
+		}
+		os.Remove(unsigned)
+		os.Remove(signed)
+	}
+
+	l, err := ledger.Genesis(genesis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range chain[1:] {
+		if err := l.ApplyBlock(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if l.Balance(address(alice)) != 70 || l.Balance(address(bob)) != 30 {
+		t.Errorf("alice has %v and bob %v, want 70 and 30", l.Balance(address(alice)), l.Balance(address(bob)))
+	}
+	if next := NextNonce(chain, address(alice)); next != 3 {
+		t.Errorf("next nonce %v, want 3", next)
+	}
+}
+
+// A signed transaction that's changed in its file no longer checks out.
+func TestTamperedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wallet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tx.signed.json")
+
+	tx, err := Transfer(address(alice), address(bob), 5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Sign(tx, alice); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(path, tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(path, tx); err == nil {
+		t.Errorf("wrote over an existing file")
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(strings.Replace(string(b), `"amount": 5`, `"amount": 500`, 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tampered, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tampered.Amount != 500 {
+		t.Fatalf("amount %v, the file didn't change", tampered.Amount)
+	}
+	if _, err := Submit(context.Background(), nil, tampered); err != ledger.ErrBadSignature {
+		t.Errorf("got %v, want %v", err, ledger.ErrBadSignature)
+	}
+	if !strings.Contains(Describe(tampered), "INVALID") {
+		t.Errorf("%v", Describe(tampered))
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"amount": 5, "fee": 1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(path); err == nil {
+		t.Errorf("read a transaction with an unknown field")
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		amount   int64
+	}{
+		{"no amount", address(alice), address(bob), 0},
+		{"negative amount", address(alice), address(bob), -1},
+		{"bad sender", "alice", address(bob), 1},
+		{"bad receiver", address(alice), address(bob)[:10], 1},
+	}
+	for _, test := range tests {
+		if _, err := Transfer(test.from, test.to, test.amount, 1); err == nil {
+			t.Errorf("%v: built it", test.name)
+		}
+	}
+}